@@ -0,0 +1,67 @@
+package alerts
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Critical
+)
+
+var severityLabels = map[Severity]string{
+	Info:     "ℹ️  INFO",
+	Warning:  "⚠️  WARNING",
+	Critical: "🚨 CRITICAL",
+}
+
+func (s Severity) String() string {
+	return severityLabels[s]
+}
+
+type Alert struct {
+	Severity Severity  `json:"severity"`
+	Source   string    `json:"source"` // Chain or subsystem that raised the alert
+	Message  string    `json:"message"`
+	Created  time.Time `json:"created"`
+}
+
+// Keep enough history for the API without growing forever
+const historyLength = 100
+
+var (
+	mutex   sync.RWMutex
+	history []Alert
+)
+
+func Raise(severity Severity, source, message string) {
+	alert := Alert{
+		Severity: severity,
+		Source:   source,
+		Message:  message,
+		Created:  time.Now(),
+	}
+
+	log.Printf("%v [%v] %v", severity, source, message)
+
+	mutex.Lock()
+	history = append(history, alert)
+	if len(history) > historyLength {
+		history = history[len(history)-historyLength:]
+	}
+	mutex.Unlock()
+}
+
+func Recent() []Alert {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	recent := make([]Alert, len(history))
+	copy(recent, history)
+	return recent
+}
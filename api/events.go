@@ -0,0 +1,16 @@
+package api
+
+import "designs.capital/dogepool/chainevents"
+
+type chainEventsResponse struct {
+	Stats  map[string]chainevents.ChainStats `json:"stats"`
+	Recent []chainevents.Event               `json:"recent"`
+}
+
+// What the nodes' ZMQ notifications said recently, with per topic counters
+func getChainEvents() chainEventsResponse {
+	return chainEventsResponse{
+		Stats:  chainevents.Stats(),
+		Recent: chainevents.Recent(),
+	}
+}
@@ -0,0 +1,16 @@
+package api
+
+import "designs.capital/dogepool/rpc"
+
+// chain name => the health of each of its configured nodes
+func getNodeStatuses(chains []string) map[string][]rpc.NodeStatus {
+	statuses := make(map[string][]rpc.NodeStatus)
+	for _, chain := range chains {
+		manager, exists := rpcManagers[chain]
+		if !exists {
+			continue
+		}
+		statuses[chain] = manager.Statuses()
+	}
+	return statuses
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"designs.capital/dogepool/config"
+	"designs.capital/dogepool/rpc"
 )
 
 const JavascriptISOFormat = "2006-01-02T15:04:05.999Z07:00"
@@ -54,14 +55,46 @@ func poolIndex(response http.ResponseWriter, request *http.Request) {
 	}
 }
 
+func nodesIndex(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		http.Error(response, fmt.Sprintf("method %s is not allowed", request.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	response.Header().Set("Access-Control-Allow-Origin", "*")
+	err := json.NewEncoder(response).Encode(getNodeStatuses(serverConfig.BlockChainOrder))
+	if err != nil {
+		http.Error(response, fmt.Sprintf("error building the response, %v", err), http.StatusInternalServerError)
+	}
+}
+
+func chainEventsIndex(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		http.Error(response, fmt.Sprintf("method %s is not allowed", request.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	response.Header().Set("Access-Control-Allow-Origin", "*")
+	err := json.NewEncoder(response).Encode(getChainEvents())
+	if err != nil {
+		http.Error(response, fmt.Sprintf("error building the response, %v", err), http.StatusInternalServerError)
+	}
+}
+
 var serverConfig *config.Config
+var rpcManagers map[string]*rpc.Manager
 
-func ListenAndServe(configuration *config.Config) {
+func ListenAndServe(configuration *config.Config, managers map[string]*rpc.Manager) {
 	serverConfig = configuration
+	rpcManagers = managers
 
 	http.HandleFunc("/miner", minerIndex)
 	http.HandleFunc("/miner-history", minerHistory)
 	http.HandleFunc("/pool", poolIndex)
+	http.HandleFunc("/nodes", nodesIndex)
+	http.HandleFunc("/chain-events", chainEventsIndex)
 
 	log.Fatal(http.ListenAndServe(":"+configuration.API.Port, nil))
 }
@@ -0,0 +1,111 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type AddressType int
+
+const (
+	AddressP2PKH AddressType = iota + 1
+	AddressP2SH
+	AddressWitness
+)
+
+type Address struct {
+	Type    AddressType
+	Version byte   // Base58 version byte or witness version
+	Program []byte // Hash160 for base58 addresses, witness program for segwit
+	Encoded string
+}
+
+// Version bytes and bech32 prefix a chain uses on one network
+type AddressFormat struct {
+	PubKeyHash []byte // P2PKH version bytes
+	ScriptHash []byte // P2SH version bytes, some chains accept a legacy prefix too
+	Bech32HRP  string // Empty when the chain has no segwit addresses
+}
+
+type AddressNetworks struct {
+	Mainnet AddressFormat
+	Testnet AddressFormat
+	Regtest AddressFormat
+}
+
+// Network names as reported by getblockchaininfo's "chain" field
+func (n AddressNetworks) ForNetwork(network string) (AddressFormat, error) {
+	switch network {
+	case "main":
+		return n.Mainnet, nil
+	case "test", "testnet", "testnet4", "signet":
+		return n.Testnet, nil
+	case "regtest":
+		return n.Regtest, nil
+	default:
+		return AddressFormat{}, errors.New("unknown network: " + network)
+	}
+}
+
+func containsByte(haystack []byte, needle byte) bool {
+	for _, b := range haystack {
+		if b == needle {
+			return true
+		}
+	}
+	return false
+}
+
+func DecodeAddress(address string, format AddressFormat) (Address, error) {
+	decoded := Address{Encoded: address}
+
+	if format.Bech32HRP != "" && strings.HasPrefix(strings.ToLower(address), format.Bech32HRP+"1") {
+		version, program, err := SegwitAddressDecode(format.Bech32HRP, address)
+		if err != nil {
+			return decoded, fmt.Errorf("invalid address %v: %v", address, err)
+		}
+		decoded.Type = AddressWitness
+		decoded.Version = version
+		decoded.Program = program
+		return decoded, nil
+	}
+
+	version, payload, err := Base58CheckDecode(address)
+	if err != nil {
+		return decoded, fmt.Errorf("invalid address %v: %v", address, err)
+	}
+	if len(payload) != 20 {
+		return decoded, fmt.Errorf("invalid address %v: expected a 20 byte hash, got %v", address, len(payload))
+	}
+
+	switch {
+	case containsByte(format.PubKeyHash, version):
+		decoded.Type = AddressP2PKH
+	case containsByte(format.ScriptHash, version):
+		decoded.Type = AddressP2SH
+	default:
+		return decoded, fmt.Errorf("invalid address %v: unexpected version byte %#02x", address, version)
+	}
+	decoded.Version = version
+	decoded.Program = payload
+
+	return decoded, nil
+}
+
+func ValidAddress(address string, format AddressFormat) bool {
+	_, err := DecodeAddress(address, format)
+	return err == nil
+}
+
+func ValidNetworkAddress(chain Blockchain, network, address string) bool {
+	if scripter, ok := chain.(AddressScripter); ok {
+		_, err := scripter.ScriptPubKey(network, address)
+		return err == nil
+	}
+	format, err := chain.AddressNetworks().ForNetwork(network)
+	if err != nil {
+		return false
+	}
+	return ValidAddress(address, format)
+}
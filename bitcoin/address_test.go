@@ -0,0 +1,180 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki#test-vectors
+// with the scriptPubKey each address stands for
+var validSegwitAddresses = []struct {
+	address, script string
+}{
+	{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+	{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+	{"bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y", "5128751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6"},
+	{"BC1SW50QGDZ25J", "6002751e"},
+	{"bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", "5210751e76e8199196d454941c45d1b3a323"},
+	{"tb1qqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesrxh6hy", "0020000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+	{"tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c", "5120000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+	{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", "512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+}
+
+func TestSegwitAddressDecode(t *testing.T) {
+	for _, test := range validSegwitAddresses {
+		hrp := strings.ToLower(test.address[:2])
+		version, program, err := SegwitAddressDecode(hrp, test.address)
+		if err != nil {
+			t.Errorf("%v: %v", test.address, err)
+			continue
+		}
+
+		script, _ := hex.DecodeString(test.script)
+		wantVersion := script[0]
+		if wantVersion != 0 {
+			wantVersion -= 0x50 // OP_1 through OP_16
+		}
+		if version != wantVersion || hex.EncodeToString(program) != test.script[4:] {
+			t.Errorf("%v: version %v program %x, want %v %v", test.address, version, program, wantVersion, test.script[4:])
+		}
+
+		encoded, err := SegwitAddressEncode(hrp, version, program)
+		if err != nil || encoded != strings.ToLower(test.address) {
+			t.Errorf("%v encoded back as %v: %v", test.address, encoded, err)
+		}
+	}
+}
+
+// v0 programs are only ever a 20 byte key hash or a 32 byte script hash,
+// whatever their checksum says
+func v0Address(t *testing.T, program []byte) string {
+	data, err := convertBits(program, 8, 5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bech32Encode("bc", append([]byte{0}, data...), bech32Standard)
+}
+
+func TestSegwitAddressDecodeRejects(t *testing.T) {
+	tests := []struct {
+		reason, hrp, address string
+		want                 error // Any error when nil
+	}{
+		// BIP350's invalid addresses
+		{"unexpected human readable part", "bc", "tc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq5zuyut", nil},
+		{"v1 with a bech32 checksum", "bc", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd", errWitnessEncoding},
+		{"v19 with a bech32 checksum", "tb", "tb1z0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqglt7rf", errWitnessEncoding},
+		{"v18 with a bech32 checksum", "bc", "BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ54WELL", errWitnessEncoding},
+		{"v0 with a bech32m checksum", "bc", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh", errWitnessEncoding},
+		{"v0 with a bech32m checksum", "tb", "tb1q0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq24jc47", errWitnessEncoding},
+		{"invalid character in the checksum", "bc", "bc1p38j9r5y49hruaue7wxjce0updqjuyyx0kh56v8s25huc6995vvpql3jow4", errBech32Character},
+		{"witness version 17", "bc", "BC130XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ7ZWS8R", errWitnessVersion},
+		{"1 byte program", "bc", "bc1pw5dgrnzv", errWitnessProgram},
+		{"41 byte program", "bc", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v8n0nx0muaewav253zgeav", errWitnessProgram},
+		{"16 byte v0 program", "bc", "BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P", errWitnessProgram},
+		{"mixed case", "tb", "tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq47Zagq", errBech32Case},
+		{"more than 4 padding bits", "bc", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v07qwwzcrf", errBech32Padding},
+		{"non-zero padding", "tb", "tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vpggkg4j", errBech32Padding},
+		{"empty data", "bc", "bc1gmk9yu", nil},
+
+		{"40 byte v0 program", "bc", v0Address(t, make([]byte, 40)), errWitnessProgram},
+		{"2 byte v0 program", "bc", v0Address(t, []byte{0x75, 0x1e}), errWitnessProgram},
+		{"upper case checksum on a lower case address", "bc", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kV8F3T4", errBech32Case},
+		{"one character off", "bc", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", errBech32Checksum},
+	}
+	for _, test := range tests {
+		_, _, err := SegwitAddressDecode(test.hrp, test.address)
+		if err == nil || (test.want != nil && !errors.Is(err, test.want)) {
+			t.Errorf("%v: %v got %v, want %v", test.reason, test.address, err, test.want)
+		}
+	}
+
+	// The same programs with the right length do decode
+	for _, length := range []int{20, 32} {
+		address := v0Address(t, make([]byte, length))
+		_, _, err := SegwitAddressDecode("bc", address)
+		if err != nil {
+			t.Errorf("%v byte v0 program %v: %v", length, address, err)
+		}
+	}
+}
+
+// The genesis block's coinbase pays this key hash
+const genesisKeyHash = "62e907b15cbf27d5425399ebf6f0fb50ebb88f18"
+
+func TestBase58Check(t *testing.T) {
+	payload, _ := hex.DecodeString(genesisKeyHash)
+	address := Base58CheckEncode(0x00, payload)
+	if address != "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" {
+		t.Fatalf("encoded %v, want the genesis address", address)
+	}
+
+	version, decoded, err := Base58CheckDecode(address)
+	if err != nil || version != 0x00 || hex.EncodeToString(decoded) != genesisKeyHash {
+		t.Errorf("decoded version %v payload %x: %v", version, decoded, err)
+	}
+
+	tests := []struct {
+		reason, address string
+		want            error
+	}{
+		{"last character changed", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", errBase58Checksum},
+		{"two characters swapped", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivNfa", errBase58Checksum},
+		{"not in the alphabet", "1A1zP1eP5QGefi2DMPTfTL5SLmv7Div0Na", errBase58Character},
+		{"too short for a checksum", "1A1z", errBase58Length},
+	}
+	for _, test := range tests {
+		_, _, err := Base58CheckDecode(test.address)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%v: %v got %v, want %v", test.reason, test.address, err, test.want)
+		}
+	}
+}
+
+func TestDecodeAddressChecksVersionBytes(t *testing.T) {
+	litecoin := GetChain("litecoin").AddressNetworks()
+	payload, _ := hex.DecodeString(genesisKeyHash)
+
+	tests := []struct {
+		address string
+		format  AddressFormat
+		want    AddressType // Zero when the address must be rejected
+	}{
+		{Base58CheckEncode(0x30, payload), litecoin.Mainnet, AddressP2PKH},
+		{Base58CheckEncode(0x32, payload), litecoin.Mainnet, AddressP2SH},
+		{Base58CheckEncode(0x05, payload), litecoin.Mainnet, AddressP2SH}, // Legacy 3... prefix
+		{Base58CheckEncode(0x6f, payload), litecoin.Testnet, AddressP2PKH},
+
+		// Bitcoin's, and testnet's on mainnet
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", litecoin.Mainnet, 0},
+		{Base58CheckEncode(0x6f, payload), litecoin.Mainnet, 0},
+		{Base58CheckEncode(0x30, payload), litecoin.Testnet, 0},
+		// Right version, 21 byte payload
+		{Base58CheckEncode(0x30, append(payload, 0)), litecoin.Mainnet, 0},
+		// Another chain's segwit prefix
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", litecoin.Mainnet, 0},
+	}
+	for _, test := range tests {
+		decoded, err := DecodeAddress(test.address, test.format)
+		if test.want == 0 {
+			if err == nil {
+				t.Errorf("%v decoded as %v", test.address, decoded.Type)
+			}
+			continue
+		}
+		if err != nil || decoded.Type != test.want || hex.EncodeToString(decoded.Program) != genesisKeyHash {
+			t.Errorf("%v decoded as type %v program %x: %v", test.address, decoded.Type, decoded.Program, err)
+		}
+	}
+
+	segwit, err := SegwitAddressEncode("ltc", 0, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeAddress(strings.ToUpper(segwit), litecoin.Mainnet)
+	if err != nil || decoded.Type != AddressWitness || decoded.Version != 0 {
+		t.Errorf("%v decoded as type %v version %v: %v", segwit, decoded.Type, decoded.Version, err)
+	}
+}
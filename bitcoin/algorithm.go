@@ -0,0 +1,54 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Proof of work families a pool can be configured for.  Chains merged mined
+// together must share one, the aux proof is the primary header's digest.
+type Algorithm struct {
+	Name         string
+	HeaderDigest func(header string) (string, error)
+	digest       func(header []byte) ([32]byte, error)
+	// Stratum difficulty 1 relative to Bitcoin's difficulty 1 target
+	ShareMultiplier float64
+	// Hashes expected per Bitcoin difficulty 1 share.  Shares are persisted
+	// in Bitcoin difficulty, after dividing by ShareMultiplier.
+	HashrateConstant float64
+}
+
+var (
+	Scrypt = Algorithm{
+		Name:             "scrypt",
+		HeaderDigest:     ScryptDigest,
+		digest:           scryptDigest,
+		ShareMultiplier:  65536,
+		HashrateConstant: math.Pow(2, 32),
+	}
+	Sha256d = Algorithm{
+		Name:             "sha256d",
+		HeaderDigest:     DoubleSha256,
+		digest:           doubleSha256Digest,
+		ShareMultiplier:  1,
+		HashrateConstant: math.Pow(2, 32),
+	}
+)
+
+// Every chain in the merged mining order has to hash its headers the same way
+func CheckMergedFamily(chainNames []string) error {
+	if len(chainNames) < 1 {
+		return errors.New("no chains configured")
+	}
+	primary := GetChain(chainNames[0])
+	for _, chainName := range chainNames[1:] {
+		aux := GetChain(chainName)
+		if aux.Algorithm().Name != primary.Algorithm().Name {
+			m := "%v (%v) can't be merged mined with %v (%v)"
+			m = fmt.Sprintf(m, chainName, aux.Algorithm().Name, chainNames[0], primary.Algorithm().Name)
+			return errors.New(m)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,11 @@
 package bitcoin
 
-import "fmt"
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
 
 const (
 	mergedMiningHeader  = "fabe6d6d"
@@ -15,7 +20,77 @@ type AuxBlock struct {
 	CoinbaseValue     uint   `json:"coinbasevalue"`
 	Bits              string `json:"bits"`
 	Height            uint64 `json:"height"`
-	Target            string `json:"target"`
+	Target            string `json:"target"` // Big endian once parsed, like Template.Target
+}
+
+// How a daemon hands out and takes back aux blocks
+type AuxRPCFlavour struct {
+	// "createauxblock" (with submitauxblock) or the wallet based "getauxblock"
+	Method string `json:"method"`
+	// Byte order of "target"/"_target", Namecoin and Dogecoin reply "little"
+	TargetByteOrder string `json:"target_byte_order"`
+}
+
+const (
+	AuxMethodCreate = "createauxblock"
+	AuxMethodGet    = "getauxblock"
+)
+
+func (f AuxRPCFlavour) withDefaults() AuxRPCFlavour {
+	if f.Method == "" {
+		f.Method = AuxMethodCreate
+	}
+	if f.TargetByteOrder == "" {
+		f.TargetByteOrder = "little"
+	}
+	return f
+}
+
+func (f AuxRPCFlavour) validate() error {
+	if f.Method != AuxMethodCreate && f.Method != AuxMethodGet {
+		return errors.New("unknown aux rpc method: " + f.Method)
+	}
+	if f.TargetByteOrder != "little" && f.TargetByteOrder != "big" {
+		return errors.New("aux target byte order must be little or big: " + f.TargetByteOrder)
+	}
+	return nil
+}
+
+// Normalizes createauxblock/getauxblock replies whatever the daemon's flavour
+func ParseAuxBlock(reply json.RawMessage, flavour AuxRPCFlavour) (AuxBlock, error) {
+	var parsed struct {
+		AuxBlock
+		LegacyTarget string `json:"_target"`
+	}
+	err := json.Unmarshal(reply, &parsed)
+	if err != nil {
+		return AuxBlock{}, errors.Join(errors.New("failed to parse aux block"), err)
+	}
+
+	auxBlock := parsed.AuxBlock
+	if auxBlock.Target == "" {
+		auxBlock.Target = parsed.LegacyTarget
+	}
+	if len(auxBlock.Hash) != 64 || len(auxBlock.Target) != 64 {
+		m := "aux block needs a 32 byte hash and target, got %q and %q"
+		m = fmt.Sprintf(m, auxBlock.Hash, auxBlock.Target)
+		return AuxBlock{}, errors.New(m)
+	}
+	for _, field := range []string{auxBlock.Hash, auxBlock.Target} {
+		_, err = hex.DecodeString(field)
+		if err != nil {
+			return AuxBlock{}, errors.Join(errors.New("aux block hash and target must be hex"), err)
+		}
+	}
+
+	if flavour.withDefaults().TargetByteOrder == "little" {
+		auxBlock.Target, err = reverseHexBytes(auxBlock.Target)
+		if err != nil {
+			return AuxBlock{}, err
+		}
+	}
+
+	return auxBlock, nil
 }
 
 func (b *AuxBlock) GetWork() string {
@@ -31,17 +106,16 @@ type AuxPow struct {
 }
 
 func MakeAuxPow(parentBlock BitcoinBlock) AuxPow {
-	if parentBlock.hash == "" {
+	if !parentBlock.summed {
 		panic("Set parent block hash first")
 	}
-	// debugAuxPow(parentBlock, makeParentMerkleBranch(parentBlock.merkleSteps), makeAuxChainMerkleBranch())
 
 	return AuxPow{
-		ParentCoinbase:       parentBlock.coinbase,
-		ParentHeaderHash:     parentBlock.hash,
+		ParentCoinbase:       parentBlock.coinbaseHex(),
+		ParentHeaderHash:     parentBlock.hashHex(),
 		ParentMerkleBranch:   makeParentMerkleBranch(parentBlock.merkleSteps),
 		auxMerkleBranch:      makeAuxChainMerkleBranch(),
-		ParentHeaderUnhashed: parentBlock.header,
+		ParentHeaderUnhashed: parentBlock.Header(),
 	}
 }
 
@@ -91,14 +165,3 @@ func makeAuxChainMerkleBranch() AuxMerkleBranch {
 func (am *AuxMerkleBranch) Serialize() string {
 	return am.numberOfBranches + am.mask
 }
-
-func debugAuxPow(parentBlock BitcoinBlock, parentMerkle ParentMerkleBranch, auxchainMerkle AuxMerkleBranch) {
-	fmt.Println()
-	fmt.Println("coinbase", parentBlock.coinbase)
-	fmt.Println("hash", parentBlock.hash)
-	fmt.Println("merkleSteps", parentBlock.merkleSteps)
-	fmt.Println("merkleDigested", parentMerkle.Serialize())
-	fmt.Println("chainmerklebranch", auxchainMerkle.Serialize())
-	fmt.Println("header", parentBlock.header)
-	fmt.Println()
-}
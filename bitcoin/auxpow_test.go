@@ -0,0 +1,93 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// Replies shaped like Dogecoin Core 1.14's and Namecoin Core's, targets
+// are the blocks' bits in each daemon's byte order
+const (
+	dogecoinCreateAuxBlock = `{
+		"hash": "5ae2d4e39e0ce5b4d5b8e8a0d3cb6f6d9c6d4e8a2b1f0e3c7d9a8b6c5d4e3f21",
+		"chainid": 98,
+		"previousblockhash": "b1c5ad7f0bd4f1b5e0d41a7c6a3e3b1f6f8c1d0e9a2b3c4d5e6f708192a3b4c5",
+		"coinbasevalue": 1000000000000,
+		"bits": "1a01bd20",
+		"height": 5000001,
+		"_target": "000000000000000000000000000000000000000000000020bd01000000000000"
+	}`
+	namecoinGetAuxBlock = `{
+		"hash": "9f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0",
+		"chainid": 1,
+		"previousblockhash": "c0ffee0000000000000000000000000000000000000000000000000000c0ffee",
+		"coinbasevalue": 625000000,
+		"bits": "17083df5",
+		"height": 700001,
+		"_target": "0000000000000000000000000000000000000000f53d08000000000000000000"
+	}`
+	// Older daemons named the field "target"
+	legacyGetAuxBlock = `{
+		"hash": "9f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0",
+		"chainid": 1,
+		"target": "0000000000000000000000000000000000000000f53d08000000000000000000"
+	}`
+)
+
+func TestParseAuxBlock(t *testing.T) {
+	little := AuxRPCFlavour{}
+	tests := []struct {
+		name    string
+		reply   string
+		flavour AuxRPCFlavour
+		bits    uint32 // The target ParseAuxBlock has to arrive at, zero for an error
+		chainID int
+	}{
+		{"dogecoin createauxblock", dogecoinCreateAuxBlock, little, 0x1a01bd20, 98},
+		{"namecoin getauxblock", namecoinGetAuxBlock, AuxRPCFlavour{Method: AuxMethodGet, TargetByteOrder: "little"}, 0x17083df5, 1},
+		{"legacy target field", legacyGetAuxBlock, AuxRPCFlavour{Method: AuxMethodGet}, 0x17083df5, 1},
+		{"big endian daemon", `{
+			"hash": "5ae2d4e39e0ce5b4d5b8e8a0d3cb6f6d9c6d4e8a2b1f0e3c7d9a8b6c5d4e3f21",
+			"target": "00000000000001bd200000000000000000000000000000000000000000000000"
+		}`, AuxRPCFlavour{TargetByteOrder: "big"}, 0x1a01bd20, 0},
+		{"target both ways", `{
+			"hash": "5ae2d4e39e0ce5b4d5b8e8a0d3cb6f6d9c6d4e8a2b1f0e3c7d9a8b6c5d4e3f21",
+			"target": "000000000000000000000000000000000000000000000020bd01000000000000",
+			"_target": "ffff000000000000000000000000000000000000000000000000000000000000"
+		}`, little, 0x1a01bd20, 0},
+		{"no target", `{"hash": "5ae2d4e39e0ce5b4d5b8e8a0d3cb6f6d9c6d4e8a2b1f0e3c7d9a8b6c5d4e3f21"}`, little, 0, 0},
+		{"short hash", `{"hash": "5ae2", "_target": "000000000000000000000000000000000000000000000020bd01000000000000"}`, little, 0, 0},
+		{"not hex", `{
+			"hash": "5ae2d4e39e0ce5b4d5b8e8a0d3cb6f6d9c6d4e8a2b1f0e3c7d9a8b6c5d4e3f21",
+			"_target": "zz0000000000000000000000000000000000000000000020bd01000000000000"
+		}`, little, 0, 0},
+		{"error reply", `null`, little, 0, 0},
+	}
+	for _, test := range tests {
+		auxBlock, err := ParseAuxBlock(json.RawMessage(test.reply), test.flavour)
+		if test.bits == 0 {
+			if err == nil {
+				t.Errorf("%v: parsed %+v", test.name, auxBlock)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+
+		target, _ := CompactToTarget(test.bits)
+		if want := fmt.Sprintf("%064x", target); auxBlock.Target != want {
+			t.Errorf("%v: target %v, want %v", test.name, auxBlock.Target, want)
+		}
+		if auxBlock.ChainID != test.chainID {
+			t.Errorf("%v: chain ID %v, want %v", test.name, auxBlock.ChainID, test.chainID)
+		}
+	}
+
+	parsed, _ := ParseAuxBlock(json.RawMessage(dogecoinCreateAuxBlock), little)
+	if parsed.Height != 5000001 || parsed.CoinbaseValue != 1000000000000 || parsed.Bits != "1a01bd20" || parsed.PreviousBlockHash[:8] != "b1c5ad7f" {
+		t.Errorf("createauxblock's other fields lost: %+v", parsed)
+	}
+}
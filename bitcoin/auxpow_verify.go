@@ -0,0 +1,280 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Mirrors CAuxPow::check in Namecoin/Dogecoin so a broken proof is caught
+// before submitauxblock answers with a bare false.
+
+const maxChainMerkleBranchLength = 30
+
+type AuxPowCheck struct {
+	Name   string
+	Passed bool
+	Detail string
+}
+
+type AuxPowReport struct {
+	Checks []AuxPowCheck
+}
+
+func (r *AuxPowReport) add(name string, passed bool, format string, args ...any) {
+	r.Checks = append(r.Checks, AuxPowCheck{
+		Name:   name,
+		Passed: passed,
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+func (r AuxPowReport) Valid() bool {
+	return len(r.Failed()) == 0
+}
+
+func (r AuxPowReport) Failed() []AuxPowCheck {
+	var failed []AuxPowCheck
+	for _, check := range r.Checks {
+		if !check.Passed {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// Nil when every check passed
+func (r AuxPowReport) Error() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	reasons := make([]string, len(failed))
+	for i, check := range failed {
+		reasons[i] = check.Name + ": " + check.Detail
+	}
+	return errors.New("invalid auxpow - " + strings.Join(reasons, "; "))
+}
+
+func (r AuxPowReport) String() string {
+	var builder strings.Builder
+	for _, check := range r.Checks {
+		mark := "✅"
+		if !check.Passed {
+			mark = "❌"
+		}
+		fmt.Fprintf(&builder, "%v %v: %v\n", mark, check.Name, check.Detail)
+	}
+	return builder.String()
+}
+
+type ParsedAuxPow struct {
+	Coinbase          []byte
+	CoinbaseScriptSig []byte
+	ParentHash        []byte
+	CoinbaseBranch    [][]byte
+	CoinbaseIndex     uint32
+	ChainBranch       [][]byte
+	ChainIndex        uint32
+	ParentHeader      []byte
+}
+
+// auxHash is createauxblock's "hash", target the aux chain's target as a number
+func VerifyAuxPow(serialized, auxHash string, auxChainID uint32, target *big.Int, parentChain Blockchain) AuxPowReport {
+	var report AuxPowReport
+
+	auxpow, err := ParseAuxPow(serialized)
+	if err != nil {
+		report.add("parse", false, "%v", err)
+		return report
+	}
+	report.add("parse", true, "%v byte coinbase, %v coinbase branch, %v chain branch",
+		len(auxpow.Coinbase), len(auxpow.CoinbaseBranch), len(auxpow.ChainBranch))
+
+	report.add("coinbase index", auxpow.CoinbaseIndex == 0, "%v, the parent coinbase is always the first transaction", auxpow.CoinbaseIndex)
+
+	parentVersion := binary.LittleEndian.Uint32(auxpow.ParentHeader[0:4])
+	parentChainID := parentVersion >> 16
+	report.add("parent chain ID", auxChainID == 0 || parentChainID != auxChainID,
+		"parent %#x, aux %#x", parentChainID, auxChainID)
+
+	coinbaseHash := doubleSha256Bytes(auxpow.Coinbase)
+	merkleRoot := foldMerkleBranch(coinbaseHash[:], auxpow.CoinbaseBranch, auxpow.CoinbaseIndex)
+	headerMerkleRoot := auxpow.ParentHeader[36:68]
+	report.add("coinbase merkle branch", bytes.Equal(merkleRoot, headerMerkleRoot),
+		"computed %x, parent header has %x", merkleRoot, headerMerkleRoot)
+
+	report.add("chain merkle branch length", len(auxpow.ChainBranch) <= maxChainMerkleBranchLength,
+		"%v, at most %v", len(auxpow.ChainBranch), maxChainMerkleBranchLength)
+
+	auxHashBytes, err := hex.DecodeString(auxHash)
+	if err != nil || len(auxHashBytes) != 32 {
+		report.add("commitment", false, "aux hash %q isn't 32 bytes of hex", auxHash)
+		return report
+	}
+	// Coinbases commit to the root in RPC byte order, merkle math is in internal order
+	chainRoot := reverse(foldMerkleBranch(reverse(auxHashBytes), auxpow.ChainBranch, auxpow.ChainIndex))
+	verifyCommitment(&report, auxpow, chainRoot, auxChainID)
+
+	verifyParentPow(&report, auxpow, target, parentChain)
+
+	return report
+}
+
+func verifyCommitment(report *AuxPowReport, auxpow ParsedAuxPow, chainRoot []byte, auxChainID uint32) {
+	script := auxpow.CoinbaseScriptSig
+	magic, _ := hex.DecodeString(mergedMiningHeader)
+
+	headerAt := bytes.Index(script, magic)
+	rootAt := bytes.Index(script, chainRoot)
+	if rootAt < 0 {
+		report.add("commitment", false, "aux merkle root %x not in the parent coinbase", chainRoot)
+		return
+	}
+
+	if headerAt >= 0 {
+		if bytes.Index(script[headerAt+len(magic):], magic) >= 0 {
+			report.add("commitment", false, "more than one %v header in the parent coinbase", mergedMiningHeader)
+			return
+		}
+		if rootAt != headerAt+len(magic) {
+			report.add("commitment", false, "aux merkle root doesn't follow the %v header", mergedMiningHeader)
+			return
+		}
+	} else if rootAt > 20 {
+		// Legacy proofs without the header have to commit early in the script
+		report.add("commitment", false, "aux merkle root at byte %v without a %v header", rootAt, mergedMiningHeader)
+		return
+	}
+	report.add("commitment", true, "aux merkle root %x at byte %v", chainRoot, rootAt)
+
+	sizeAt := rootAt + len(chainRoot)
+	if len(script) < sizeAt+8 {
+		report.add("merkle size", false, "coinbase ends before the merkle size and nonce")
+		return
+	}
+	size := binary.LittleEndian.Uint32(script[sizeAt : sizeAt+4])
+	nonce := binary.LittleEndian.Uint32(script[sizeAt+4 : sizeAt+8])
+
+	height := uint(len(auxpow.ChainBranch))
+	report.add("merkle size", size == 1<<height, "%v for a branch of %v", size, height)
+
+	expected := expectedAuxChainIndex(nonce, auxChainID, height)
+	report.add("merkle nonce", expected == auxpow.ChainIndex,
+		"nonce %v puts chain %#x at slot %v, the branch is for slot %v", nonce, auxChainID, expected, auxpow.ChainIndex)
+}
+
+func verifyParentPow(report *AuxPowReport, auxpow ParsedAuxPow, target *big.Int, parentChain Blockchain) {
+	if target == nil || parentChain == nil {
+		report.add("parent proof of work", false, "no aux target or parent chain to check against")
+		return
+	}
+
+	digest, err := parentChain.HeaderDigest(hex.EncodeToString(auxpow.ParentHeader))
+	if err != nil {
+		report.add("parent proof of work", false, "%v", err)
+		return
+	}
+	digest, err = reverseHexBytes(digest)
+	if err != nil {
+		report.add("parent proof of work", false, "%v", err)
+		return
+	}
+	hash, _ := new(big.Int).SetString(digest, 16)
+
+	report.add("parent proof of work", HashMeetsTarget(hash, target),
+		"parent hash %v, aux target %064x", digest, target)
+}
+
+// https://github.com/namecoin/namecoin-core/blob/master/src/auxpow.cpp getExpectedIndex
+func expectedAuxChainIndex(nonce, chainID uint32, height uint) uint32 {
+	random := nonce
+	random = random*1103515245 + 12345
+	random += chainID
+	random = random*1103515245 + 12345
+
+	return random % (1 << height)
+}
+
+func foldMerkleBranch(hash []byte, branch [][]byte, index uint32) []byte {
+	for _, step := range branch {
+		var joined [32]byte
+		if index&1 == 1 {
+			joined = doubleSha256Bytes(append(append([]byte{}, step...), hash...))
+		} else {
+			joined = doubleSha256Bytes(append(append([]byte{}, hash...), step...))
+		}
+		hash = joined[:]
+		index >>= 1
+	}
+	return hash
+}
+
+func ParseAuxPow(serialized string) (ParsedAuxPow, error) {
+	var auxpow ParsedAuxPow
+
+	raw, err := hex.DecodeString(serialized)
+	if err != nil {
+		return auxpow, err
+	}
+	reader := &byteReader{data: raw}
+
+	coinbaseStart := reader.offset
+	auxpow.CoinbaseScriptSig, err = reader.coinbaseTransaction()
+	if err != nil {
+		return auxpow, errors.Join(errors.New("parent coinbase"), err)
+	}
+	auxpow.Coinbase = raw[coinbaseStart:reader.offset]
+
+	auxpow.ParentHash = reader.next(32)
+	auxpow.CoinbaseBranch, auxpow.CoinbaseIndex = reader.merkleBranch()
+	auxpow.ChainBranch, auxpow.ChainIndex = reader.merkleBranch()
+	auxpow.ParentHeader = reader.next(80)
+
+	if reader.err != nil {
+		return auxpow, reader.err
+	}
+	if reader.offset != len(raw) {
+		return auxpow, fmt.Errorf("%v trailing bytes after the parent header", len(raw)-reader.offset)
+	}
+
+	return auxpow, nil
+}
+
+// Legacy serialization only, returns the first input's scriptSig
+func (r *byteReader) coinbaseTransaction() ([]byte, error) {
+	r.next(4) // version
+	inputs := r.varUint()
+	if r.err == nil && inputs != 1 {
+		return nil, fmt.Errorf("%v inputs, a coinbase has one", inputs)
+	}
+	r.next(36) // null prevout
+	scriptSig := r.varBytes()
+	r.next(4) // sequence
+
+	outputs := r.varUint()
+	for i := uint64(0); i < outputs && r.err == nil; i++ {
+		r.next(8) // value
+		r.varBytes()
+	}
+	r.next(4) // lock time
+
+	return scriptSig, r.err
+}
+
+func (r *byteReader) merkleBranch() ([][]byte, uint32) {
+	length := r.varUint()
+	if length > uint64(len(r.data)/32) {
+		r.err = fmt.Errorf("merkle branch of %v hashes at byte %v runs past the data", length, r.offset)
+		return nil, 0
+	}
+	branch := make([][]byte, length)
+	for i := range branch {
+		branch[i] = r.next(32)
+	}
+	return branch, r.uint32()
+}
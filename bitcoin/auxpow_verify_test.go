@@ -0,0 +1,148 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+const (
+	testAuxHash    = "3d5e6ae6e6dbd6a6b49e0ca08f2a43d5f4a81c5a4c0d3b1f8e2e0b7a9f5c1d24"
+	testAuxChainID = 0x62 // Dogecoin's
+)
+
+// A Litecoin parent block merge mining one aux chain in a two level chain
+// merkle tree, built the way Namecoin's auxpow_tests.cpp builds its proofs
+type testAuxPow struct {
+	scriptPrefix []byte // Before the commitment
+	header       bool   // fabe6d6d before the root
+	duplicate    bool   // A second fabe6d6d commitment after the first
+	nonce        uint32
+	chainIndex   uint32
+	chainBranch  [][]byte
+}
+
+func makeTestAuxPow() testAuxPow {
+	nonce := uint32(7)
+	return testAuxPow{
+		scriptPrefix: []byte{0x03, 0x65, 0x00, 0x00}, // BIP34 height 101
+		header:       true,
+		nonce:        nonce,
+		chainIndex:   expectedAuxChainIndex(nonce, testAuxChainID, 2),
+		chainBranch:  [][]byte{bytes.Repeat([]byte{0x11}, 32), bytes.Repeat([]byte{0x22}, 32)},
+	}
+}
+
+func (a testAuxPow) serialize(t *testing.T) string {
+	auxHash := mustDecodeHex(t, testAuxHash)
+	chainRoot := reverse(foldMerkleBranch(reverse(auxHash), a.chainBranch, a.chainIndex))
+
+	commitment := append([]byte{}, chainRoot...)
+	commitment = binary.LittleEndian.AppendUint32(commitment, 1<<len(a.chainBranch))
+	commitment = binary.LittleEndian.AppendUint32(commitment, a.nonce)
+	magic := mustDecodeHex(t, mergedMiningHeader)
+	if a.header {
+		commitment = append(magic, commitment...)
+	}
+
+	scriptSig := append(append([]byte{}, a.scriptPrefix...), commitment...)
+	if a.duplicate {
+		scriptSig = append(scriptSig, commitment...)
+	}
+
+	var coinbase []byte
+	coinbase = binary.LittleEndian.AppendUint32(coinbase, 1)
+	coinbase = append(coinbase, 1)
+	coinbase = append(coinbase, make([]byte, 32)...)
+	coinbase = binary.LittleEndian.AppendUint32(coinbase, 0xffffffff)
+	coinbase = append(coinbase, bytesWithLengthHeader(scriptSig)...)
+	coinbase = binary.LittleEndian.AppendUint32(coinbase, 0xffffffff)
+	coinbase = append(coinbase, 1)
+	coinbase = binary.LittleEndian.AppendUint64(coinbase, 1_250_000_000)
+	coinbase = append(coinbase, 1, opOne)
+	coinbase = binary.LittleEndian.AppendUint32(coinbase, 0)
+
+	coinbaseSibling := bytes.Repeat([]byte{0x33}, 32)
+	coinbaseHash := doubleSha256Bytes(coinbase)
+	merkleRoot := foldMerkleBranch(coinbaseHash[:], [][]byte{coinbaseSibling}, 0)
+
+	var header []byte
+	header = binary.LittleEndian.AppendUint32(header, 0x20000000)
+	header = append(header, bytes.Repeat([]byte{0x44}, 32)...)
+	header = append(header, merkleRoot...)
+	header = binary.LittleEndian.AppendUint32(header, 1_700_000_000)
+	header = binary.LittleEndian.AppendUint32(header, 0x207fffff)
+	header = binary.LittleEndian.AppendUint32(header, 0)
+	headerHash := doubleSha256Bytes(header)
+
+	var proof []byte
+	proof = append(proof, coinbase...)
+	proof = append(proof, headerHash[:]...)
+	proof = append(proof, 1)
+	proof = append(proof, coinbaseSibling...)
+	proof = binary.LittleEndian.AppendUint32(proof, 0)
+	proof = append(proof, byte(len(a.chainBranch)))
+	for _, step := range a.chainBranch {
+		proof = append(proof, step...)
+	}
+	proof = binary.LittleEndian.AppendUint32(proof, a.chainIndex)
+	proof = append(proof, header...)
+
+	return hex.EncodeToString(proof)
+}
+
+func TestVerifyAuxPow(t *testing.T) {
+	litecoin := GetChain("litecoin")
+	anyHash := maxTarget
+
+	report := VerifyAuxPow(makeTestAuxPow().serialize(t), testAuxHash, testAuxChainID, anyHash, litecoin)
+	if !report.Valid() {
+		t.Fatalf("a well formed proof failed:\n%v", report)
+	}
+
+	otherSlot := func(a *testAuxPow) {
+		for a.nonce++; expectedAuxChainIndex(a.nonce, testAuxChainID, 2) == a.chainIndex; a.nonce++ {
+		}
+	}
+	tests := []struct {
+		name   string
+		change func(*testAuxPow)
+		target *big.Int
+		failed string // The check that has to fail
+	}{
+		{"branch for another slot", func(a *testAuxPow) { a.chainIndex ^= 1 }, anyHash, "merkle nonce"},
+		{"nonce for another slot", otherSlot, anyHash, "merkle nonce"},
+		{"missing merged mining header", func(a *testAuxPow) {
+			a.header = false
+			a.scriptPrefix = append(a.scriptPrefix, bytes.Repeat([]byte{0x55}, 20)...)
+		}, anyHash, "commitment"},
+		{"duplicate merged mining header", func(a *testAuxPow) { a.duplicate = true }, anyHash, "commitment"},
+		{"parent hash above the target", func(a *testAuxPow) {}, big.NewInt(1), "parent proof of work"},
+	}
+	for _, test := range tests {
+		auxpow := makeTestAuxPow()
+		test.change(&auxpow)
+		report := VerifyAuxPow(auxpow.serialize(t), testAuxHash, testAuxChainID, test.target, litecoin)
+
+		failed := report.Failed()
+		if len(failed) != 1 || failed[0].Name != test.failed {
+			t.Errorf("%v: want only %q to fail, got:\n%v", test.name, test.failed, report)
+		}
+	}
+
+	// Legacy proofs may leave the header out if the root comes early enough
+	legacy := makeTestAuxPow()
+	legacy.header = false
+	report = VerifyAuxPow(legacy.serialize(t), testAuxHash, testAuxChainID, anyHash, litecoin)
+	if !report.Valid() {
+		t.Errorf("a legacy proof without a header failed:\n%v", report)
+	}
+
+	// The aux chain's own ID in the parent's version is a proof for itself
+	report = VerifyAuxPow(makeTestAuxPow().serialize(t), testAuxHash, 0x2000, anyHash, litecoin)
+	if failed := report.Failed(); len(failed) == 0 || failed[0].Name != "parent chain ID" {
+		t.Errorf("accepted a parent with the aux chain's ID:\n%v", report)
+	}
+}
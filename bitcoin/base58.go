@@ -0,0 +1,113 @@
+package bitcoin
+
+import (
+	"bytes"
+	"errors"
+)
+
+// https://en.bitcoin.it/wiki/Base58Check_encoding
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Indexes [256]int
+
+func init() {
+	for i := range base58Indexes {
+		base58Indexes[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		base58Indexes[base58Alphabet[i]] = i
+	}
+}
+
+var (
+	errBase58Character = errors.New("base58: invalid character")
+	errBase58Checksum  = errors.New("base58: checksum mismatch")
+	errBase58Length    = errors.New("base58: payload too short for a checksum")
+)
+
+func base58Decode(input string) ([]byte, error) {
+	leadingZeros := 0
+	for leadingZeros < len(input) && input[leadingZeros] == base58Alphabet[0] {
+		leadingZeros++
+	}
+
+	// log(58) / log(256), rounded up
+	decoded := make([]byte, 0, len(input)*733/1000+1)
+	for i := leadingZeros; i < len(input); i++ {
+		carry := base58Indexes[input[i]]
+		if carry < 0 {
+			return nil, errBase58Character
+		}
+		for j := range decoded {
+			carry += int(decoded[j]) * 58
+			decoded[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			decoded = append(decoded, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	result := make([]byte, leadingZeros, leadingZeros+len(decoded))
+	return append(result, reverse(decoded)...), nil
+}
+
+func base58Encode(input []byte) string {
+	leadingZeros := 0
+	for leadingZeros < len(input) && input[leadingZeros] == 0 {
+		leadingZeros++
+	}
+
+	// log(256) / log(58), rounded up
+	encoded := make([]byte, 0, len(input)*138/100+1)
+	for _, b := range input[leadingZeros:] {
+		carry := int(b)
+		for j := range encoded {
+			carry += int(encoded[j]) << 8
+			encoded[j] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			encoded = append(encoded, byte(carry%58))
+			carry /= 58
+		}
+	}
+
+	result := make([]byte, leadingZeros+len(encoded))
+	for i := 0; i < leadingZeros; i++ {
+		result[i] = base58Alphabet[0]
+	}
+	for i, digit := range encoded {
+		result[len(result)-1-i] = base58Alphabet[digit]
+	}
+	return string(result)
+}
+
+// Returns the version byte and payload after verifying the 4 byte checksum
+func Base58CheckDecode(input string) (byte, []byte, error) {
+	decoded, err := base58Decode(input)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(decoded) < 5 {
+		return 0, nil, errBase58Length
+	}
+
+	body, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	sum := doubleSha256Bytes(body)
+	if !bytes.Equal(sum[:4], checksum) {
+		return 0, nil, errBase58Checksum
+	}
+
+	return body[0], body[1:], nil
+}
+
+func Base58CheckEncode(version byte, payload []byte) string {
+	body := make([]byte, 0, len(payload)+5)
+	body = append(body, version)
+	body = append(body, payload...)
+	sum := doubleSha256Bytes(body)
+	return base58Encode(append(body, sum[:4]...))
+}
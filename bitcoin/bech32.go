@@ -0,0 +1,214 @@
+package bitcoin
+
+import (
+	"errors"
+	"strings"
+)
+
+// https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki
+// https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+type bech32Encoding int
+
+const (
+	bech32Standard bech32Encoding = iota + 1
+	bech32m
+)
+
+const (
+	bech32Constant  = 1
+	bech32mConstant = 0x2bc830a3
+	bech32MaxLength = 90
+)
+
+var (
+	errBech32Length    = errors.New("bech32: invalid length")
+	errBech32Case      = errors.New("bech32: mixed case")
+	errBech32Separator = errors.New("bech32: missing separator")
+	errBech32Character = errors.New("bech32: invalid character")
+	errBech32Checksum  = errors.New("bech32: checksum mismatch")
+	errBech32Padding   = errors.New("bech32: invalid padding")
+	errWitnessVersion  = errors.New("bech32: invalid witness version")
+	errWitnessProgram  = errors.New("bech32: invalid witness program length")
+	errWitnessEncoding = errors.New("bech32: wrong checksum variant for witness version")
+)
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	checksum := uint32(1)
+	for _, value := range values {
+		top := checksum >> 25
+		checksum = (checksum&0x1ffffff)<<5 ^ uint32(value)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				checksum ^= generator[i]
+			}
+		}
+	}
+	return checksum
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+func bech32Checksum(hrp string, data []byte, encoding bech32Encoding) []byte {
+	constant := uint32(bech32Constant)
+	if encoding == bech32m {
+		constant = bech32mConstant
+	}
+
+	values := append(bech32HRPExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	polymod := bech32Polymod(values) ^ constant
+
+	checksum := make([]byte, 6)
+	for i := range checksum {
+		checksum[i] = byte(polymod>>uint(5*(5-i))) & 31
+	}
+	return checksum
+}
+
+// Returns the human readable part, the 5 bit data without checksum and which checksum matched
+func bech32Decode(input string) (string, []byte, bech32Encoding, error) {
+	if len(input) < 8 || len(input) > bech32MaxLength {
+		return "", nil, 0, errBech32Length
+	}
+
+	lower, upper := strings.ToLower(input), strings.ToUpper(input)
+	if input != lower && input != upper {
+		return "", nil, 0, errBech32Case
+	}
+	input = lower
+
+	separator := strings.LastIndexByte(input, '1')
+	if separator < 1 || separator+7 > len(input) {
+		return "", nil, 0, errBech32Separator
+	}
+
+	hrp := input[:separator]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, 0, errBech32Character
+		}
+	}
+
+	data := make([]byte, 0, len(input)-separator-1)
+	for i := separator + 1; i < len(input); i++ {
+		value := strings.IndexByte(bech32Charset, input[i])
+		if value < 0 {
+			return "", nil, 0, errBech32Character
+		}
+		data = append(data, byte(value))
+	}
+
+	var encoding bech32Encoding
+	switch bech32Polymod(append(bech32HRPExpand(hrp), data...)) {
+	case bech32Constant:
+		encoding = bech32Standard
+	case bech32mConstant:
+		encoding = bech32m
+	default:
+		return "", nil, 0, errBech32Checksum
+	}
+
+	return hrp, data[:len(data)-6], encoding, nil
+}
+
+func bech32Encode(hrp string, data []byte, encoding bech32Encoding) string {
+	combined := append(append([]byte{}, data...), bech32Checksum(hrp, data, encoding)...)
+
+	var builder strings.Builder
+	builder.Grow(len(hrp) + 1 + len(combined))
+	builder.WriteString(hrp)
+	builder.WriteByte('1')
+	for _, value := range combined {
+		builder.WriteByte(bech32Charset[value])
+	}
+	return builder.String()
+}
+
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	accumulator, bits := uint32(0), uint(0)
+	maxValue := uint32(1)<<toBits - 1
+	converted := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+
+	for _, value := range data {
+		if uint32(value)>>fromBits != 0 {
+			return nil, errBech32Padding
+		}
+		accumulator = accumulator<<fromBits | uint32(value)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			converted = append(converted, byte(accumulator>>bits&maxValue))
+		}
+	}
+
+	if pad {
+		if bits > 0 {
+			converted = append(converted, byte(accumulator<<(toBits-bits)&maxValue))
+		}
+	} else if bits >= fromBits || accumulator<<(toBits-bits)&maxValue != 0 {
+		return nil, errBech32Padding
+	}
+
+	return converted, nil
+}
+
+// Returns the witness version and program of a segwit address with the expected human readable part
+func SegwitAddressDecode(hrp, address string) (byte, []byte, error) {
+	decodedHRP, data, encoding, err := bech32Decode(address)
+	if err != nil {
+		return 0, nil, err
+	}
+	if decodedHRP != hrp {
+		return 0, nil, errors.New("bech32: unexpected human readable part " + decodedHRP)
+	}
+	if len(data) < 1 || data[0] > 16 {
+		return 0, nil, errWitnessVersion
+	}
+
+	version := data[0]
+	program, err := convertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(program) < 2 || len(program) > 40 {
+		return 0, nil, errWitnessProgram
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return 0, nil, errWitnessProgram
+	}
+	if (version == 0 && encoding != bech32Standard) || (version != 0 && encoding != bech32m) {
+		return 0, nil, errWitnessEncoding
+	}
+
+	return version, program, nil
+}
+
+func SegwitAddressEncode(hrp string, version byte, program []byte) (string, error) {
+	data, err := convertBits(program, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+
+	encoding := bech32Standard
+	if version > 0 {
+		encoding = bech32m
+	}
+
+	address := bech32Encode(hrp, append([]byte{version}, data...), encoding)
+	_, _, err = SegwitAddressDecode(hrp, address)
+	return address, err
+}
@@ -1,15 +1,33 @@
 package bitcoin
 
 type BitcoinBlock struct {
-	Template             *Template
+	Template *Template
+	chain    Blockchain
+
+	// Stratum notify parameters, hex
 	reversePrevBlockHash string
 	coinbaseInitial      string
 	coinbaseFinal        string
 	merkleSteps          []string
-	coinbase             string
-	header               string
-	hash                 string
-	chain                Blockchain
+
+	// Shared by every copy of the block made for a share, never written after GenerateWork
+	job *jobBytes
+
+	// Per share.  The pool validates shares on copies of the job's block,
+	// arrays keep those copies from writing to each other.
+	coinbase []byte
+	header   [80]byte
+	hash     [32]byte // Proof of work digest, RPC byte order
+	built    bool
+	summed   bool
+}
+
+type jobBytes struct {
+	coinbasePrefix []byte
+	coinbaseSuffix []byte
+	merkleBranch   [][32]byte
+	header         [80]byte // Version, previous block hash and bits filled in
+	digester       ByteDigester
 }
 
 func (b BitcoinBlock) ChainName() string {
@@ -1,30 +1,58 @@
 package bitcoin
 
+import "encoding/json"
+
 const BitcoinMinConfirmations = 102
 
 type Blockchain interface {
     ChainName() string
+    Algorithm() Algorithm
     CoinbaseDigest(coinbase string) (string, error)
     HeaderDigest(header string) (string, error)
     ShareMultiplier() float64
     MinimumConfirmations() uint
+    AuxChainID() uint32
+    BlockTemplateRules() []string
+    AuxRPC() AuxRPCFlavour
+    CoinbaseVersion() uint32
+    AddressNetworks() AddressNetworks
     ValidMainnetAddress(address string) bool
     ValidTestnetAddress(address string) bool
 }
 
+// Optional, lets share validation skip hex round trips
+type ByteDigester interface {
+    CoinbaseDigestBytes(coinbase []byte) ([32]byte, error)
+    HeaderDigestBytes(header []byte) ([32]byte, error)
+}
+
+// Optional, for chains whose addresses the built-in decoders don't understand
+type AddressScripter interface {
+    ScriptPubKey(network, address string) (string, error)
+}
+
+// Optional, the smallest payout output the chain's nodes will relay
+type DustLimiter interface {
+    DustLimit() float64
+}
+
+// Optional, for chains whose coinbase isn't laid out like Bitcoin's.  Returns
+// the legacy serialized coinbase hex either side of the extranonce.
+type CoinbaseBuilder interface {
+    BuildCoinbase(template *Template, arbitrary string, extranonceLength int, payees CoinbasePayees) (initial, final string, err error)
+}
+
+// Optional, for chains that fetch templates and submit blocks without the node RPC
+type BlockSource interface {
+    HandlesBlocks() bool
+    GetBlockTemplate(rules []string) (json.RawMessage, error)
+    SubmitBlock(submission string) error
+}
+
 func GetChain(chainName string) Blockchain {
-    switch chainName {
-    case "dogecoin":
-        return Dogecoin{}
-    case "litecoin":
-        return Litecoin{}
-    case "bellscoin":
-        return Bellscoin{}
-    case "luckycoin":
-        return Luckycoin{}
-    case "pepecoin":
-        return Pepecoin{}
-    default:
+    chain, exists := lookupChain(chainName)
+    if !exists {
         panic("Unknown blockchain: " + chainName)
     }
-}
\ No newline at end of file
+    return chain
+}
@@ -0,0 +1,87 @@
+package bitcoin
+
+// Chains available without a chains file.  Entries in the file with the same
+// name replace these.
+var builtinChains = []ChainDefinition{
+	{
+		Name:             "dogecoin",
+		Algorithm:        "scrypt",
+		MinConfirmations: 251,
+		AuxChainID:       0x62,
+		DustLimit:        0.01, // Dogecoin Core's soft dust limit, smaller outputs pay a penalty fee
+		Mainnet:          AddressFormatDefinition{PubKeyHash: []string{"1e"}, ScriptHash: []string{"16"}},
+		Testnet:          AddressFormatDefinition{PubKeyHash: []string{"71"}, ScriptHash: []string{"c4"}},
+		Regtest:          AddressFormatDefinition{PubKeyHash: []string{"6f"}, ScriptHash: []string{"c4"}},
+	},
+	{
+		Name:               "litecoin",
+		Algorithm:          "scrypt",
+		MinConfirmations:   BitcoinMinConfirmations,
+		BlockTemplateRules: []string{"mweb", "segwit"},
+		// P2SH accepts the legacy 3... prefix too
+		Mainnet: AddressFormatDefinition{PubKeyHash: []string{"30"}, ScriptHash: []string{"32", "05"}, Bech32HRP: "ltc"},
+		Testnet: AddressFormatDefinition{PubKeyHash: []string{"6f"}, ScriptHash: []string{"3a", "c4"}, Bech32HRP: "tltc"},
+		Regtest: AddressFormatDefinition{PubKeyHash: []string{"6f"}, ScriptHash: []string{"3a", "c4"}, Bech32HRP: "rltc"},
+	},
+	// Testnet and regtest prefixes of the Dogecoin forks below are inherited from Dogecoin
+	{
+		// No Bech32 until Bellscoin's chainparams.cpp confirms its HRPs, a
+		// chains file entry can add them
+		Name:             "bellscoin",
+		Algorithm:        "scrypt",
+		MinConfirmations: BitcoinMinConfirmations,
+		Mainnet:          AddressFormatDefinition{PubKeyHash: []string{"19"}, ScriptHash: []string{"1e"}},
+		Testnet:          AddressFormatDefinition{PubKeyHash: []string{"71"}, ScriptHash: []string{"c4"}},
+		Regtest:          AddressFormatDefinition{PubKeyHash: []string{"6f"}, ScriptHash: []string{"c4"}},
+	},
+	{
+		Name:             "luckycoin",
+		Algorithm:        "scrypt",
+		MinConfirmations: BitcoinMinConfirmations,
+		Mainnet:          AddressFormatDefinition{PubKeyHash: []string{"2f"}, ScriptHash: []string{"05"}},
+		Testnet:          AddressFormatDefinition{PubKeyHash: []string{"71"}, ScriptHash: []string{"c4"}},
+		Regtest:          AddressFormatDefinition{PubKeyHash: []string{"6f"}, ScriptHash: []string{"c4"}},
+	},
+	{
+		Name:             "pepecoin",
+		Algorithm:        "scrypt",
+		MinConfirmations: BitcoinMinConfirmations,
+		Mainnet:          AddressFormatDefinition{PubKeyHash: []string{"38"}, ScriptHash: []string{"16"}},
+		Testnet:          AddressFormatDefinition{PubKeyHash: []string{"71"}, ScriptHash: []string{"c4"}},
+		Regtest:          AddressFormatDefinition{PubKeyHash: []string{"6f"}, ScriptHash: []string{"c4"}},
+	},
+	{
+		Name:             "bitcoin",
+		Algorithm:        "sha256d",
+		MinConfirmations: BitcoinMinConfirmations,
+		Mainnet:          AddressFormatDefinition{PubKeyHash: []string{"00"}, ScriptHash: []string{"05"}, Bech32HRP: "bc"},
+		Testnet:          AddressFormatDefinition{PubKeyHash: []string{"6f"}, ScriptHash: []string{"c4"}, Bech32HRP: "tb"},
+		Regtest:          AddressFormatDefinition{PubKeyHash: []string{"6f"}, ScriptHash: []string{"c4"}, Bech32HRP: "bcrt"},
+	},
+	{
+		// Legacy base58 addresses only, CashAddr isn't decoded yet
+		Name:             "bitcoincash",
+		Algorithm:        "sha256d",
+		MinConfirmations: BitcoinMinConfirmations,
+		Mainnet:          AddressFormatDefinition{PubKeyHash: []string{"00"}, ScriptHash: []string{"05"}},
+		Testnet:          AddressFormatDefinition{PubKeyHash: []string{"6f"}, ScriptHash: []string{"c4"}},
+		Regtest:          AddressFormatDefinition{PubKeyHash: []string{"6f"}, ScriptHash: []string{"c4"}},
+	},
+	{
+		Name:             "namecoin",
+		Algorithm:        "sha256d",
+		MinConfirmations: BitcoinMinConfirmations,
+		AuxChainID:       0x01,
+		Mainnet:          AddressFormatDefinition{PubKeyHash: []string{"34"}, ScriptHash: []string{"0d"}, Bech32HRP: "nc"},
+		Testnet:          AddressFormatDefinition{PubKeyHash: []string{"6f"}, ScriptHash: []string{"c4"}, Bech32HRP: "tn"},
+		Regtest:          AddressFormatDefinition{PubKeyHash: []string{"6f"}, ScriptHash: []string{"c4"}, Bech32HRP: "ncrt"},
+	},
+	{
+		Name:             "syscoin",
+		Algorithm:        "sha256d",
+		MinConfirmations: BitcoinMinConfirmations,
+		Mainnet:          AddressFormatDefinition{PubKeyHash: []string{"3f"}, ScriptHash: []string{"05"}, Bech32HRP: "sys"},
+		Testnet:          AddressFormatDefinition{PubKeyHash: []string{"41"}, ScriptHash: []string{"c4"}, Bech32HRP: "tsys"},
+		Regtest:          AddressFormatDefinition{PubKeyHash: []string{"41"}, ScriptHash: []string{"c4"}, Bech32HRP: "scrt"},
+	},
+}
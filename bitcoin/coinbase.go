@@ -2,7 +2,6 @@ package bitcoin
 
 import (
 	"encoding/hex"
-	"fmt"
 	"log"
 )
 
@@ -18,7 +17,7 @@ type CoinbaseInital struct {
 	HeightHex                   string
 }
 
-func (t *Template) CoinbaseInitial(arbitraryByteLength uint) CoinbaseInital {
+func (t *Template) CoinbaseInitial(arbitraryByteLength uint, version uint32) CoinbaseInital {
 	heightBytes := eightLittleEndianBytes(t.Height)
 	heightBytes = removeInsignificantBytesLittleEndian(heightBytes)
 	heightHex := hex.EncodeToString(heightBytes)
@@ -31,7 +30,7 @@ func (t *Template) CoinbaseInitial(arbitraryByteLength uint) CoinbaseInital {
 	}
 
 	return CoinbaseInital{
-		Version:                     "01000000", // Different from template version
+		Version:                     hex.EncodeToString(fourLittleEndianBytes(version)), // Different from template version
 		NumberOfInputs:              "01",
 		PreviousOutputTransactionID: "0000000000000000000000000000000000000000000000000000000000000000",
 		PreviousOutputIndex:         "ffffffff",
@@ -42,7 +41,6 @@ func (t *Template) CoinbaseInitial(arbitraryByteLength uint) CoinbaseInital {
 }
 
 func (i CoinbaseInital) Serialize() string {
-	// debugCoinbaseInitialOutput(i)
 	return i.Version +
 		i.NumberOfInputs +
 		i.PreviousOutputTransactionID +
@@ -60,36 +58,27 @@ type CoinbaseFinal struct {
 	TransactionLockTime   string
 }
 
-func (t *Template) CoinbaseFinal(poolPayoutPubScriptKey string) CoinbaseFinal {
-	txOutputLen, txOutput := t.coinbaseTransactionOutputs(poolPayoutPubScriptKey)
+func (t *Template) CoinbaseFinal(payees CoinbasePayees) (CoinbaseFinal, error) {
+	txOutputLen, txOutput, err := t.coinbaseTransactionOutputs(payees)
+	if err != nil {
+		return CoinbaseFinal{}, err
+	}
 	return CoinbaseFinal{
 		TransactionInSequence: "00000000",
 		OutputCount:           txOutputLen,
 		TxOuts:                txOutput,
 		TransactionLockTime:   "00000000",
-	}
+	}, nil
 }
 
 func (f CoinbaseFinal) Serialize() string {
-	// debugCoinbaseFinalOutput(f)
 	return f.TransactionInSequence +
 		varUint(f.OutputCount) +
 		f.TxOuts +
 		f.TransactionLockTime
 }
 
-type Coinbase struct {
-	CoinbaseInital string
-	Arbitrary      string
-	CoinbaseFinal  string
-}
-
-func (cb *Coinbase) Serialize() string {
-	// debugCoinbaseOutput(cb)
-	return cb.CoinbaseInital + cb.Arbitrary + cb.CoinbaseFinal
-}
-
-func (t *Template) coinbaseTransactionOutputs(poolPubScriptKey string) (uint, string) {
+func (t *Template) coinbaseTransactionOutputs(payees CoinbasePayees) (uint, string, error) {
 	outputsCount := uint(0)
 	outputs := ""
 
@@ -99,66 +88,15 @@ func (t *Template) coinbaseTransactionOutputs(poolPubScriptKey string) (uint, st
 		outputsCount++
 	}
 
-	// Some alt coins may have additional outputs..
-
-	// Pool reward output
-	rewardAmount := fmt.Sprintf("%016x", t.CoinBaseValue)
-	rewardAmount, _ = reverseHexBytes(rewardAmount)
-	outputsCount++
-
-	outputs = outputs + TransactionOut(rewardAmount, poolPubScriptKey)
-
-	return outputsCount, outputs
-}
-
-func debugCoinbaseOutput(cb *Coinbase) {
-	fmt.Println()
-	fmt.Println("**Coinbase Parts**")
-	fmt.Println()
-	fmt.Println("Initial", cb.CoinbaseInital)
-	fmt.Println("Arbitrary", cb.Arbitrary)
-	fmt.Println("Final", cb.CoinbaseFinal)
-	fmt.Println()
-	fmt.Println("Coinbase", cb.CoinbaseInital+cb.Arbitrary+cb.CoinbaseFinal)
-	fmt.Println()
-}
-
-func debugCoinbaseInitialOutput(i CoinbaseInital) {
-	fmt.Println()
-	fmt.Println("🧐 Coinbase Initial Parts ➔ ➔ ➔ ➔")
-	fmt.Println()
-	fmt.Println("Version", i.Version)
-	fmt.Println("NumberOfInputs", i.NumberOfInputs)
-	fmt.Println("PreviousOutputTransactionID", i.PreviousOutputTransactionID)
-	fmt.Println("PreviousOutputIndex", i.PreviousOutputIndex)
-	fmt.Println("BytesInArbitrary", i.BytesInArbitrary)
-	fmt.Println("BytesInHeight", i.BytesInHeight)
-	fmt.Println("HeightHex", i.HeightHex)
-	fmt.Println()
-	cbI := i.Version +
-		i.NumberOfInputs +
-		i.PreviousOutputTransactionID +
-		i.PreviousOutputIndex +
-		varUint(i.BytesInArbitrary) +
-		varUint(i.BytesInHeight) +
-		i.HeightHex
-	fmt.Println("Coinbase Initial", cbI)
-	fmt.Println()
-}
+	// Pool reward output, then any fee recipients paid directly
+	rewardOutputs, err := payees.Split(uint64(t.CoinBaseValue))
+	if err != nil {
+		return 0, "", err
+	}
+	for _, output := range rewardOutputs {
+		outputs = outputs + output.Serialize()
+		outputsCount++
+	}
 
-func debugCoinbaseFinalOutput(f CoinbaseFinal) {
-	fmt.Println()
-	fmt.Println("➔ ➔ ➔ ➔ Coinbase Final Parts**")
-	fmt.Println()
-	fmt.Println("TransactionInSequence", f.TransactionInSequence)
-	fmt.Println("OutputCount", f.OutputCount)
-	fmt.Println("TxOuts", f.TxOuts)
-	fmt.Println("TransactionLockTime", f.TransactionLockTime)
-	fmt.Println()
-	cbf := f.TransactionInSequence +
-		varUint(f.OutputCount) +
-		f.TxOuts +
-		f.TransactionLockTime
-	fmt.Println("Coinbase Final", cbf)
-	fmt.Println()
+	return outputsCount, outputs, nil
 }
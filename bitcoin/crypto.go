@@ -8,21 +8,37 @@ import (
 )
 
 func DoubleSha256(input string) (string, error) {
-	inputBytes, err := hex.DecodeString(input)
+	return hexDigest(doubleSha256Digest, input)
+}
+
+func ScryptDigest(input string) (string, error) {
+	return hexDigest(scryptDigest, input)
+}
+
+func doubleSha256Digest(input []byte) ([32]byte, error) {
+	sum := sha256.Sum256(input)
+	return sha256.Sum256(sum[:]), nil
+}
+
+// Litecoin's parameters, the header is both password and salt
+func scryptDigest(input []byte) ([32]byte, error) {
+	var sum [32]byte
+	digest, err := scrypt.Key(input, input, 1024, 1, 1, 32)
 	if err != nil {
-		return "", err
+		return sum, err
 	}
-	sum := sha256.Sum256(inputBytes)
-	sum = sha256.Sum256(sum[:])
-
-	return hex.EncodeToString(sum[:]), nil
+	copy(sum[:], digest)
+	return sum, nil
 }
 
-func ScryptDigest(input string) (string, error) {
-	digest, err := hex.DecodeString(input)
+func hexDigest(digest func([]byte) ([32]byte, error), input string) (string, error) {
+	inputBytes, err := hex.DecodeString(input)
 	if err != nil {
 		return "", err
 	}
-	digest, err = scrypt.Key(digest, digest, 1024, 1, 1, 32)
-	return hex.EncodeToString(digest), nil
+	sum, err := digest(inputBytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum[:]), nil
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"log"
 )
 
@@ -18,20 +19,18 @@ func varUint(value uint) string {
 	var buffer []byte
 	if value <= 252 {
 		buffer = []byte{byte(value)}
-	} else if value > 0xfd && value <= 0xffff {
+	} else if value <= 0xffff {
 		buffer = make([]byte, 2)
 		binary.LittleEndian.PutUint16(buffer, uint16(value))
 		buffer = append([]byte{0xfd}, buffer...)
-	} else if value > 0xffff && value <= 0xffffffff {
+	} else if value <= 0xffffffff {
 		buffer = make([]byte, 4)
 		binary.LittleEndian.PutUint32(buffer, uint32(value))
 		buffer = append([]byte{0xfe}, buffer...)
-	} else if value > 0xffffffff && value <= 0xffffffffffffffff {
+	} else {
 		buffer = make([]byte, 8)
 		binary.LittleEndian.PutUint64(buffer, uint64(value))
 		buffer = append([]byte{0xff}, buffer...)
-	} else {
-		panic("Too large to stream")
 	}
 
 	return hex.EncodeToString(buffer)
@@ -131,28 +130,78 @@ func reverse(b []byte) []byte {
 	return r
 }
 
-func reverseHexBytes(hex string) (string, error) {
-	if len(hex)%2 != 0 {
+func reverseHexBytes(hexString string) (string, error) {
+	if len(hexString)%2 != 0 {
 		return "", errors.New("string must be divisible by 2 to be a byte string")
 	}
-	o := ""
-	l := len(hex)
-	for i := l; i > 0; i = i - 2 {
-		o = o + hex[i-2:i]
+	reversed := make([]byte, len(hexString))
+	for i, l := 0, len(hexString); i < l; i += 2 {
+		reversed[l-i-2], reversed[l-i-1] = hexString[i], hexString[i+1]
 	}
-	return o, nil
+	return string(reversed), nil
 }
 
-func reverseHex4Bytes(hex string) (string, error) {
-	if len(hex)%8 != 0 {
+// Reverses the order of 4 byte words, keeping each word's bytes
+func reverseHex4Bytes(hexString string) (string, error) {
+	if len(hexString)%8 != 0 {
 		return "", errors.New("string must be divisible by 8 to represent 4 byte array")
 	}
+	reversed := make([]byte, len(hexString))
+	for i, l := 0, len(hexString); i < l; i += 8 {
+		copy(reversed[l-i-8:l-i], hexString[i:i+8])
+	}
+	return string(reversed), nil
+}
+
+func reverseInPlace(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+// Decodes into a preallocated buffer, the hex has to fill it exactly
+func decodeHexInto(dst []byte, hexString string) error {
+	if len(hexString) != len(dst)*2 {
+		return fmt.Errorf("expected %v hex characters, got %v", len(dst)*2, len(hexString))
+	}
+	for i := range dst {
+		high, okHigh := fromHexChar(hexString[2*i])
+		low, okLow := fromHexChar(hexString[2*i+1])
+		if !okHigh || !okLow {
+			return errors.New("invalid hex: " + hexString)
+		}
+		dst[i] = high<<4 | low
+	}
+	return nil
+}
 
-	var o string
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
 
-	for l, i := len(hex), 0; i < l/8; i++ {
-		o = o + hex[l-8*(i+1):(l-(8*i))]
+// Stratum sends ntime and nonce as 8 big endian hex characters
+func parseHexUint32(hexString string) (uint32, error) {
+	var word [4]byte
+	err := decodeHexInto(word[:], hexString)
+	if err != nil {
+		return 0, err
 	}
+	return binary.BigEndian.Uint32(word[:]), nil
+}
 
-	return o, nil
+func digestFromHex(digestHex string, err error) ([32]byte, error) {
+	var digest [32]byte
+	if err != nil {
+		return digest, err
+	}
+	err = decodeHexInto(digest[:], digestHex)
+	return digest, err
 }
@@ -1,22 +1,25 @@
 package bitcoin
 
 import (
+    "encoding/binary"
     "encoding/hex"
     "errors"
     "fmt"
     "math/big"
+    "strconv"
+    "sync/atomic"
 )
 
 type BlockGenerator interface {
-    MakeHeader(extranonce, nonce string) (string, error)
+    MakeHeader(extranonce, nonce, nonceTime string) error
     Header() string
     Sum() (*big.Int, error)
     Submit() (string, error)
 }
 
-var jobCounter int
+var jobCounter atomic.Uint64
 
-func GenerateWork(template *Template, auxBlocks map[string]*AuxBlock, chainName, arbitrary, poolPayoutPubScriptKey string, reservedArbitraryByteLength int) (*BitcoinBlock, Work, error) {
+func GenerateWork(template *Template, chainName, arbitrary string, payees CoinbasePayees, reservedArbitraryByteLength int) (*BitcoinBlock, Work, error) {
     if template == nil {
         return nil, Work{}, errors.New("template cannot be null")
     }
@@ -31,20 +34,28 @@ func GenerateWork(template *Template, auxBlocks map[string]*AuxBlock, chainName,
         return nil, Work{}, fmt.Errorf("invalid previous block hash hex: %v", err)
     }
 
-    arbitraryBytes := bytesWithLengthHeader([]byte(arbitrary))
-    arbitraryByteLength := uint(len(arbitraryBytes) + reservedArbitraryByteLength)
-    arbitraryHex := hex.EncodeToString(arbitraryBytes)
-
-    block.coinbaseInitial = block.Template.CoinbaseInitial(arbitraryByteLength).Serialize()
-    block.coinbaseFinal = arbitraryHex + block.Template.CoinbaseFinal(poolPayoutPubScriptKey).Serialize()
+    builder, custom := block.chain.(CoinbaseBuilder)
+    if custom {
+        block.coinbaseInitial, block.coinbaseFinal, err = builder.BuildCoinbase(template, arbitrary, reservedArbitraryByteLength, payees)
+    } else {
+        block.coinbaseInitial, block.coinbaseFinal, err = BuildCoinbase(template, block.chain, arbitrary, reservedArbitraryByteLength, payees)
+    }
+    if err != nil {
+        return nil, Work{}, err
+    }
     block.merkleSteps, err = block.Template.MerkleSteps()
     if err != nil {
         return nil, Work{}, fmt.Errorf("failed to generate merkle steps: %v", err)
     }
+    block.job, err = block.makeJobBytes()
+    if err != nil {
+        return nil, Work{}, err
+    }
 
     // Work as []any for Stratum compatibility
+    jobID := jobCounter.Add(1) - 1
     work := Work{
-        fmt.Sprintf("%08x", jobCounter), // Job ID (string)
+        fmt.Sprintf("%08x", jobID),      // Job ID (string)
         block.reversePrevBlockHash,      // PrevHash (string)
         block.coinbaseInitial,           // Coinb1 (string)
         block.coinbaseFinal,             // Coinb2 (string)
@@ -54,126 +65,199 @@ func GenerateWork(template *Template, auxBlocks map[string]*AuxBlock, chainName,
         fmt.Sprintf("%x", block.Template.CurrentTime), // NTime (string)
     }
 
-    // Append auxpow data
-    for chainName, auxBlock := range auxBlocks {
-        if auxBlock != nil {
-            auxData := fmt.Sprintf("%s:%s", chainName, auxBlock.Hash)
-            work = append(work, auxData)
-        }
-    }
-
-    jobCounter++
     return &block, work, nil
 }
 
-// MakeHeader (unchanged except for nonceTime)
-func (b *BitcoinBlock) MakeHeader(extranonce, nonce, nonceTime string) (string, error) {
-    if b.Template == nil {
-        return "", errors.New("generate work first")
+// Bitcoin's coinbase, the extranonce goes between the height and arbitrary
+func BuildCoinbase(template *Template, chain Blockchain, arbitrary string, extranonceLength int, payees CoinbasePayees) (string, string, error) {
+    arbitraryBytes := bytesWithLengthHeader([]byte(arbitrary))
+    arbitraryByteLength := uint(len(arbitraryBytes) + extranonceLength)
+    arbitraryHex := hex.EncodeToString(arbitraryBytes)
+
+    initial := template.CoinbaseInitial(arbitraryByteLength, chain.CoinbaseVersion()).Serialize()
+    final, err := template.CoinbaseFinal(payees)
+    if err != nil {
+        return "", "", fmt.Errorf("failed to build coinbase outputs: %v", err)
     }
+    return initial, arbitraryHex + final.Serialize(), nil
+}
 
+// Decodes the job's hex once, shares only fill in what the miner sent
+func (b *BitcoinBlock) makeJobBytes() (*jobBytes, error) {
     var err error
-    coinbase := Coinbase{
-        CoinbaseInital: b.coinbaseInitial,
-        Arbitrary:      extranonce,
-        CoinbaseFinal:  b.coinbaseFinal,
+    job := &jobBytes{}
+    job.digester, _ = b.chain.(ByteDigester)
+
+    job.coinbasePrefix, err = hex.DecodeString(b.coinbaseInitial)
+    if err != nil {
+        return nil, fmt.Errorf("invalid coinbase initial hex: %v", err)
+    }
+    job.coinbaseSuffix, err = hex.DecodeString(b.coinbaseFinal)
+    if err != nil {
+        return nil, fmt.Errorf("invalid coinbase final hex: %v", err)
     }
 
-    b.coinbase = coinbase.Serialize()
-    coinbaseHashed, err := b.CoinbaseHashed()
+    job.merkleBranch = make([][32]byte, len(b.merkleSteps))
+    for i, step := range b.merkleSteps {
+        err = decodeHexInto(job.merkleBranch[i][:], step)
+        if err != nil {
+            return nil, fmt.Errorf("invalid merkle step: %v", err)
+        }
+    }
+
+    t := b.Template
+    binary.LittleEndian.PutUint32(job.header[0:4], uint32(t.Version))
+    err = decodeHexInto(job.header[4:36], t.PrevBlockHash)
     if err != nil {
-        return "", err
+        return nil, fmt.Errorf("invalid previous block hash hex: %v", err)
+    }
+    reverseInPlace(job.header[4:36])
+    bits, err := strconv.ParseUint(t.Bits, 16, 32)
+    if err != nil {
+        return nil, fmt.Errorf("invalid bits: %v", err)
     }
+    binary.LittleEndian.PutUint32(job.header[72:76], uint32(bits))
 
-    merkleRoot, err := makeHeaderMerkleRoot(coinbaseHashed, b.merkleSteps)
+    // Shares are judged against the template's target, the header commits to its bits
+    bitsTarget, err := CompactToTarget(uint32(bits))
     if err != nil {
-        return "", err
+        return nil, err
+    }
+    templateTarget, ok := t.Target.ToBig()
+    if !ok || templateTarget.Cmp(bitsTarget) != 0 {
+        return nil, fmt.Errorf("template target %v doesn't match bits %v", t.Target, t.Bits)
     }
 
-    t := b.Template
-    b.header, err = blockHeader(uint(t.Version), t.PrevBlockHash, merkleRoot, nonceTime, t.Bits, nonce)
+    return job, nil
+}
+
+// Builds the share's coinbase and header, extranonce is extranonce1 + extranonce2
+func (b *BitcoinBlock) MakeHeader(extranonce, nonce, nonceTime string) error {
+    if b.Template == nil || b.job == nil {
+        return errors.New("generate work first")
+    }
+    job := b.job
+
+    if len(extranonce)%2 != 0 {
+        return errors.New("extranonce must be whole bytes")
+    }
+    prefixLength, extranonceLength := len(job.coinbasePrefix), len(extranonce)/2
+    coinbase := make([]byte, prefixLength+extranonceLength+len(job.coinbaseSuffix))
+    copy(coinbase, job.coinbasePrefix)
+    err := decodeHexInto(coinbase[prefixLength:prefixLength+extranonceLength], extranonce)
     if err != nil {
-        return "", err
+        return fmt.Errorf("invalid extranonce: %v", err)
+    }
+    copy(coinbase[prefixLength+extranonceLength:], job.coinbaseSuffix)
+
+    nonceTimeValue, err := parseHexUint32(nonceTime)
+    if err != nil {
+        return fmt.Errorf("invalid ntime: %v", err)
+    }
+    nonceValue, err := parseHexUint32(nonce)
+    if err != nil {
+        return fmt.Errorf("invalid nonce: %v", err)
+    }
+
+    merkleRoot, err := b.coinbaseDigest(coinbase)
+    if err != nil {
+        return err
+    }
+    var pair [64]byte
+    for _, step := range job.merkleBranch {
+        copy(pair[:32], merkleRoot[:])
+        copy(pair[32:], step[:])
+        merkleRoot = doubleSha256Bytes(pair[:])
     }
 
-    return b.header, nil
+    b.coinbase = coinbase
+    b.header = job.header
+    copy(b.header[36:68], merkleRoot[:])
+    binary.LittleEndian.PutUint32(b.header[68:72], nonceTimeValue)
+    binary.LittleEndian.PutUint32(b.header[76:80], nonceValue)
+    b.built = true
+    b.summed = false
+
+    return nil
 }
 
-// Remaining functions unchanged (omitted for brevity)
+func (b *BitcoinBlock) coinbaseDigest(data []byte) ([32]byte, error) {
+    if b.job != nil && b.job.digester != nil {
+        return b.job.digester.CoinbaseDigestBytes(data)
+    }
+    return digestFromHex(b.chain.CoinbaseDigest(hex.EncodeToString(data)))
+}
+
+func (b *BitcoinBlock) headerDigest(data []byte) ([32]byte, error) {
+    if b.job != nil && b.job.digester != nil {
+        return b.job.digester.HeaderDigestBytes(data)
+    }
+    return digestFromHex(b.chain.HeaderDigest(hex.EncodeToString(data)))
+}
 
-// Remaining functions (unchanged)
+func (b *BitcoinBlock) Header() string {
+    if !b.built {
+        return ""
+    }
+    return hex.EncodeToString(b.header[:])
+}
+
+func (b *BitcoinBlock) coinbaseHex() string {
+    return hex.EncodeToString(b.coinbase)
+}
+
+// Proof of work hash in RPC byte order, empty before Sum
+func (b *BitcoinBlock) hashHex() string {
+    if !b.summed {
+        return ""
+    }
+    return hex.EncodeToString(b.hash[:])
+}
+
+// The block's ID, RPC byte order
 func (b *BitcoinBlock) HeaderHashed() (string, error) {
-    header, err := b.chain.CoinbaseDigest(b.header)
-    if err != nil {
-        return "", err
+    if !b.built {
+        return "", errors.New("generate header first")
     }
-    header, err = reverseHexBytes(header)
+    digest, err := b.coinbaseDigest(b.header[:])
     if err != nil {
         return "", err
     }
-    return header, nil
+    reverseInPlace(digest[:])
+    return hex.EncodeToString(digest[:]), nil
 }
 
 func (b *BitcoinBlock) CoinbaseHashed() (string, error) {
-    return b.chain.CoinbaseDigest(b.coinbase)
+    digest, err := b.coinbaseDigest(b.coinbase)
+    if err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(digest[:]), nil
 }
 
 func (b *BitcoinBlock) Sum() (*big.Int, error) {
     if b.chain == nil {
         return nil, errors.New("calculateSum: Missing blockchain interface")
     }
-    if b.header == "" {
+    if !b.built {
         return nil, errors.New("generate header first")
     }
 
-    digest, err := b.chain.HeaderDigest(b.header)
-    if err != nil {
-        return nil, err
-    }
-
-    digest, err = reverseHexBytes(digest)
+    digest, err := b.headerDigest(b.header[:])
     if err != nil {
         return nil, err
     }
-
+    reverseInPlace(digest[:])
     b.hash = digest
-    digestBytes, err := hex.DecodeString(digest)
-    if err != nil {
-        return nil, err
-    }
+    b.summed = true
 
-    return new(big.Int).SetBytes(digestBytes), nil
+    return new(big.Int).SetBytes(b.hash[:]), nil
 }
 
 func (b *BitcoinBlock) Submit() (string, error) {
-    if b.header == "" {
+    if !b.built {
         return "", errors.New("generate header first")
     }
 
-    transactionPool := make([]string, len(b.Template.Transactions))
-    for i, transaction := range b.Template.Transactions {
-        transactionPool[i] = transaction.Data
-    }
-
-    submission := b.createSubmissionHex()
-    if b.Template.MimbleWimble != "" {
-        submission = submission + "01" + b.Template.MimbleWimble
-    }
-
-    return submission, nil
+    return b.createSubmissionHex()
 }
-
-func debugMerkleSteps(block BitcoinBlock) {
-    fmt.Println()
-    fmt.Println("Transaction IDs")
-    for i, transaction := range block.Template.Transactions {
-        fmt.Println(i+1, transaction.ID, " : ", transaction.Data)
-    }
-
-    fmt.Println()
-    fmt.Println("Steps")
-    for i, step := range block.merkleSteps {
-        fmt.Println(i+1, step)
-    }
-    fmt.Println()
-}
\ No newline at end of file
@@ -0,0 +1,33 @@
+package bitcoin
+
+import "testing"
+
+// What validating one share costs: the miner's coinbase and header, then
+// the proof of work.  Allocations here are paid on every submit.
+func BenchmarkMakeHeaderSum(b *testing.B) {
+	fixture := loadBlockFixtures(b)["litecoin-mweb-synthetic.json"]
+	payees := CoinbasePayees{RewardScript: "76a9140102030405060708090a0b0c0d0e0f101112131488ac"}
+
+	for _, chainName := range []string{"litecoin", "bitcoin"} {
+		b.Run(chainName, func(b *testing.B) {
+			template := fixture.Template
+			block, _, err := GenerateWork(&template, chainName, "/dogepool/", payees, 8)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				err = block.MakeHeader("0000000100000000", "2a000000", "6553f100")
+				if err != nil {
+					b.Fatal(err)
+				}
+				_, err = block.Sum()
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
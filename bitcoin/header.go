@@ -2,7 +2,6 @@ package bitcoin
 
 import (
 	"encoding/hex"
-	"fmt"
 )
 
 // https://developer.bitcoin.org/reference/block_chain.html#block-headers
@@ -43,21 +42,5 @@ func blockHeader(version uint, previousBlockHash, merkleRootHex, nTime, bits, no
 	blockHeader = append(blockHeader, reverse(bitsHex)...)
 	blockHeader = append(blockHeader, reverse(nonceHex)...)
 
-	// headerDebugOutput(versionBytes, prevBlockHash, merkleRoot, nonceTime, bitsHex, nonceHex, blockHeader)
 	return hex.EncodeToString(blockHeader), nil
 }
-
-func headerDebugOutput(versionBytes, prevBlockHash, merkleRoot, nonceTime, bitsHex, nonceHex, blockHeader []byte) {
-	fmt.Println()
-	fmt.Println("**Block HEADER**")
-	fmt.Println()
-	fmt.Println("version", hex.EncodeToString(versionBytes))
-	fmt.Println("prevBlockHash", hex.EncodeToString(reverse(prevBlockHash)))
-	fmt.Println("merkleRoot", hex.EncodeToString(merkleRoot))
-	fmt.Println("nonceTime", hex.EncodeToString(reverse(nonceTime)))
-	fmt.Println("bitsHex", hex.EncodeToString(reverse(bitsHex)))
-	fmt.Println("nonceHex", hex.EncodeToString(reverse(nonceHex)))
-	fmt.Println()
-	fmt.Println("Header", hex.EncodeToString(blockHeader))
-	fmt.Println()
-}
@@ -0,0 +1,437 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Annotated decodes behind `dogepool inspect`
+
+type InspectionField struct {
+	Name  string
+	Value string
+	Note  string
+}
+
+type Inspection struct {
+	Title    string
+	Fields   []InspectionField
+	Sections []Inspection
+}
+
+func (i *Inspection) add(name, value, note string) {
+	i.Fields = append(i.Fields, InspectionField{Name: name, Value: value, Note: note})
+}
+
+func (i Inspection) Print(w io.Writer) {
+	i.print(w, "")
+}
+
+func (i Inspection) print(w io.Writer, indent string) {
+	fmt.Fprintf(w, "%v%v\n", indent, i.Title)
+	for _, field := range i.Fields {
+		if field.Note == "" {
+			fmt.Fprintf(w, "%v  %-26v %v\n", indent, field.Name, field.Value)
+		} else {
+			fmt.Fprintf(w, "%v  %-26v %v  (%v)\n", indent, field.Name, field.Value, field.Note)
+		}
+	}
+	for _, section := range i.Sections {
+		section.print(w, indent+"  ")
+	}
+}
+
+type headerHashes struct {
+	blockHash string   // Coinbase digest of the header, RPC byte order
+	powHash   string   // Algorithm digest, RPC byte order
+	pow       *big.Int // powHash as a number
+}
+
+func hashHeader(header []byte, chain Blockchain) (headerHashes, error) {
+	var hashes headerHashes
+	headerHex := hex.EncodeToString(header)
+
+	digest, err := chain.CoinbaseDigest(headerHex)
+	if err != nil {
+		return hashes, err
+	}
+	hashes.blockHash, err = reverseHexBytes(digest)
+	if err != nil {
+		return hashes, err
+	}
+
+	digest, err = chain.HeaderDigest(headerHex)
+	if err != nil {
+		return hashes, err
+	}
+	hashes.powHash, err = reverseHexBytes(digest)
+	if err != nil {
+		return hashes, err
+	}
+	var valid bool
+	hashes.pow, valid = new(big.Int).SetString(hashes.powHash, 16)
+	if !valid {
+		return hashes, fmt.Errorf("%v digest %q isn't a hash", chain.Algorithm().Name, digest)
+	}
+
+	return hashes, nil
+}
+
+func InspectHeader(headerHex string, chain Blockchain) (Inspection, error) {
+	header, err := hex.DecodeString(headerHex)
+	if err != nil {
+		return Inspection{}, err
+	}
+	return inspectHeader(header, chain)
+}
+
+func inspectHeader(header []byte, chain Blockchain) (Inspection, error) {
+	inspection := Inspection{Title: "Block header (" + chain.ChainName() + ")"}
+	if len(header) != 80 {
+		return inspection, fmt.Errorf("a header is 80 bytes, got %v", len(header))
+	}
+
+	version := binary.LittleEndian.Uint32(header[0:4])
+	bits := binary.LittleEndian.Uint32(header[72:76])
+	timestamp := binary.LittleEndian.Uint32(header[68:72])
+	target, err := CompactToTarget(bits)
+	if err != nil {
+		return inspection, err
+	}
+
+	inspection.add("version", fmt.Sprintf("%08x", version), fmt.Sprintf("%v, auxpow chain ID %#x", version, version>>16))
+	inspection.add("previous block", hex.EncodeToString(reverse(header[4:36])), "")
+	inspection.add("merkle root", hex.EncodeToString(reverse(header[36:68])), "RPC byte order")
+	inspection.add("time", fmt.Sprintf("%08x", timestamp), time.Unix(int64(timestamp), 0).UTC().Format(time.RFC3339))
+	inspection.add("bits", fmt.Sprintf("%08x", bits), "")
+	inspection.add("target", formatTarget(target), "")
+	inspection.add("nonce", fmt.Sprintf("%08x", binary.LittleEndian.Uint32(header[76:80])), "")
+
+	hashes, err := hashHeader(header, chain)
+	if err != nil {
+		return inspection, err
+	}
+	inspection.add("block hash", hashes.blockHash, "")
+	inspection.add("pow hash", hashes.powHash, chain.Algorithm().Name)
+	inspection.add("meets target", strconv.FormatBool(HashMeetsTarget(hashes.pow, target)), "")
+
+	return inspection, nil
+}
+
+func InspectTransaction(transactionHex string) (Inspection, error) {
+	transaction, err := ParseTransaction(transactionHex)
+	if err != nil {
+		return Inspection{}, err
+	}
+	return inspectTransaction(transaction, "Transaction"), nil
+}
+
+func inspectTransaction(transaction ParsedTransaction, title string) Inspection {
+	inspection := Inspection{Title: title + " " + transaction.ID}
+	inspection.add("version", strconv.FormatUint(uint64(transaction.Version), 10), "")
+	inspection.add("flags", fmt.Sprintf("%02x", transaction.Flags), describeTransactionFlags(transaction.Flags))
+	inspection.add("size", strconv.Itoa(transaction.Size), "bytes")
+
+	coinbase := transaction.IsCoinbase()
+	for i, input := range transaction.Inputs {
+		section := Inspection{Title: fmt.Sprintf("Input %v", i)}
+		section.add("previous output", fmt.Sprintf("%v:%v", input.PreviousTxID, input.PreviousIndex), "")
+		section.add("scriptSig", hex.EncodeToString(input.ScriptSig), "")
+		if coinbase {
+			annotateCoinbaseScript(&section, input.ScriptSig)
+		}
+		section.add("sequence", fmt.Sprintf("%08x", input.Sequence), "")
+		for j, item := range input.Witness {
+			section.add(fmt.Sprintf("witness %v", j), hex.EncodeToString(item), "")
+		}
+		inspection.Sections = append(inspection.Sections, section)
+	}
+
+	var total uint64
+	for i, output := range transaction.Outputs {
+		section := Inspection{Title: fmt.Sprintf("Output %v", i)}
+		section.add("value", strconv.FormatUint(output.Value, 10), fmt.Sprintf("%.8f coins", float64(output.Value)/1e8))
+		section.add("scriptPubKey", hex.EncodeToString(output.ScriptPubKey), describeScript(output.ScriptPubKey))
+		inspection.Sections = append(inspection.Sections, section)
+		total += output.Value
+	}
+	inspection.add("output total", strconv.FormatUint(total, 10), fmt.Sprintf("%.8f coins", float64(total)/1e8))
+	inspection.add("lock time", strconv.FormatUint(uint64(transaction.LockTime), 10), "")
+
+	return inspection
+}
+
+func describeTransactionFlags(flags byte) string {
+	var names []string
+	if flags&transactionFlagWitness != 0 {
+		names = append(names, "witness")
+	}
+	if flags&transactionFlagMWEB != 0 {
+		names = append(names, "mweb")
+	}
+	if len(names) == 0 {
+		return "legacy"
+	}
+	return strings.Join(names, ", ")
+}
+
+func annotateCoinbaseScript(section *Inspection, script []byte) {
+	if len(script) > 0 && int(script[0]) < len(script) && script[0] <= 8 {
+		heightBytes := script[1 : 1+int(script[0])]
+		height := new(big.Int).SetBytes(reverse(heightBytes))
+		section.add("height", height.String(), "BIP34")
+	}
+
+	magic, _ := hex.DecodeString(mergedMiningHeader)
+	at := bytes.Index(script, magic)
+	if at >= 0 && len(script) >= at+len(magic)+40 {
+		commitment := script[at+len(magic):]
+		section.add("aux merkle root", hex.EncodeToString(commitment[:32]), fmt.Sprintf("%v at byte %v", mergedMiningHeader, at))
+		section.add("aux merkle size", strconv.FormatUint(uint64(binary.LittleEndian.Uint32(commitment[32:36])), 10), "")
+		section.add("aux merkle nonce", strconv.FormatUint(uint64(binary.LittleEndian.Uint32(commitment[36:40])), 10), "")
+	}
+
+	var text strings.Builder
+	for _, b := range script {
+		if b >= 0x20 && b < 0x7f {
+			text.WriteByte(b)
+		} else {
+			text.WriteByte('.')
+		}
+	}
+	section.add("text", text.String(), "")
+}
+
+func describeScript(script []byte) string {
+	switch {
+	case len(script) == 25 && script[0] == opDup && script[1] == opHash160 && script[2] == 20 && script[23] == opEqualVerify && script[24] == opCheckSig:
+		return "P2PKH"
+	case len(script) == 23 && script[0] == opHash160 && script[1] == 20 && script[22] == opEqual:
+		return "P2SH"
+	case len(script) == 38 && bytes.HasPrefix(script, []byte{opReturn, 0x24, 0xaa, 0x21, 0xa9, 0xed}):
+		return "witness commitment"
+	case len(script) > 0 && script[0] == opReturn:
+		return "OP_RETURN"
+	case len(script) >= 4 && (script[0] == opZero || (script[0] >= opOne && script[0] <= opSixteen)) && int(script[1]) == len(script)-2:
+		version := 0
+		if script[0] != opZero {
+			version = int(script[0]-opOne) + 1
+		}
+		return fmt.Sprintf("witness v%v, %v byte program", version, script[1])
+	}
+	return "nonstandard"
+}
+
+func InspectBlock(blockHex string, chain Blockchain) (Inspection, error) {
+	raw, err := hex.DecodeString(blockHex)
+	if err != nil {
+		return Inspection{}, err
+	}
+	if len(raw) < 80 {
+		return Inspection{}, errors.New("block shorter than its header")
+	}
+
+	inspection := Inspection{Title: "Block (" + chain.ChainName() + ")"}
+	header, err := inspectHeader(raw[:80], chain)
+	if err != nil {
+		return inspection, err
+	}
+	inspection.Sections = append(inspection.Sections, header)
+
+	reader := &byteReader{data: raw, offset: 80}
+	count := reader.varUint()
+	inspection.add("transactions", strconv.FormatUint(count, 10), "")
+
+	var last ParsedTransaction
+	for i := uint64(0); i < count && reader.err == nil; i++ {
+		last = reader.transaction()
+		if reader.err != nil {
+			break
+		}
+		if i == 0 {
+			inspection.Sections = append(inspection.Sections, inspectTransaction(last, "Coinbase"))
+			continue
+		}
+		inspection.add(fmt.Sprintf("tx %v", i), last.ID, fmt.Sprintf("%v bytes, %v", last.Size, describeTransactionFlags(last.Flags)))
+	}
+	if reader.err != nil {
+		return inspection, reader.err
+	}
+
+	remaining := len(raw) - reader.offset
+	if remaining > 0 && last.Flags&transactionFlagMWEB != 0 {
+		inspection.add("mweb block", strconv.Itoa(remaining), "bytes after the HogEx")
+	} else if remaining > 0 {
+		inspection.add("trailing", strconv.Itoa(remaining), "unexpected bytes after the transactions")
+	}
+
+	return inspection, nil
+}
+
+// auxHash may be empty to skip verification
+func InspectAuxPow(auxpowHex string, parentChain Blockchain, auxHash string, auxChainID uint32, target *big.Int) (Inspection, error) {
+	auxpow, err := ParseAuxPow(auxpowHex)
+	if err != nil {
+		return Inspection{}, err
+	}
+
+	inspection := Inspection{Title: "AuxPoW"}
+	coinbase, err := ParseTransaction(hex.EncodeToString(auxpow.Coinbase))
+	if err != nil {
+		return inspection, err
+	}
+	inspection.Sections = append(inspection.Sections, inspectTransaction(coinbase, "Parent coinbase"))
+
+	inspection.add("parent hash", hex.EncodeToString(auxpow.ParentHash), "as serialized, not checked by nodes")
+	for i, step := range auxpow.CoinbaseBranch {
+		inspection.add(fmt.Sprintf("coinbase branch %v", i), hex.EncodeToString(step), "")
+	}
+	inspection.add("coinbase index", strconv.FormatUint(uint64(auxpow.CoinbaseIndex), 10), "")
+	for i, step := range auxpow.ChainBranch {
+		inspection.add(fmt.Sprintf("chain branch %v", i), hex.EncodeToString(step), "")
+	}
+	inspection.add("chain index", strconv.FormatUint(uint64(auxpow.ChainIndex), 10), "")
+
+	header, err := inspectHeader(auxpow.ParentHeader, parentChain)
+	if err != nil {
+		return inspection, err
+	}
+	header.Title = "Parent " + header.Title
+	inspection.Sections = append(inspection.Sections, header)
+
+	if auxHash != "" {
+		report := VerifyAuxPow(auxpowHex, auxHash, auxChainID, target, parentChain)
+		verification := Inspection{Title: "Verification"}
+		for _, check := range report.Checks {
+			mark := "pass"
+			if !check.Passed {
+				mark = "FAIL"
+			}
+			verification.add(check.Name, mark, check.Detail)
+		}
+		inspection.Sections = append(inspection.Sections, verification)
+	}
+
+	return inspection, nil
+}
+
+// Rebuilds a share from a captured mining.notify and mining.submit the same
+// way the pool does, and says why it passed or failed.
+func InspectShare(notify, submit Work, extranonce1 string, difficulty float64, chain Blockchain) (Inspection, error) {
+	inspection := Inspection{Title: "Share (" + chain.ChainName() + ")"}
+	if len(notify) < 8 {
+		return inspection, fmt.Errorf("mining.notify has %v params, expected at least 8", len(notify))
+	}
+	if len(submit) < 5 {
+		return inspection, fmt.Errorf("mining.submit has %v params, expected 5", len(submit))
+	}
+
+	field := func(work Work, i int) string {
+		value, _ := work[i].(string)
+		return value
+	}
+	jobID, prevHash, coinb1, coinb2 := field(notify, 0), field(notify, 1), field(notify, 2), field(notify, 3)
+	versionHex, bits, notifyTime := field(notify, 5), field(notify, 6), field(notify, 7)
+	submitJobID, extranonce2, nonceTime, nonce := field(submit, 1), field(submit, 2), field(submit, 3), field(submit, 4)
+
+	var branch []string
+	steps, _ := notify[4].([]any)
+	for _, step := range steps {
+		stepHex, _ := step.(string)
+		branch = append(branch, stepHex)
+	}
+
+	inspection.add("worker", field(submit, 0), "")
+	jobNote := "matches"
+	if jobID != submitJobID {
+		jobNote = "DIFFERENT JOB - notify was " + jobID
+	}
+	inspection.add("job", submitJobID, jobNote)
+	inspection.add("extranonce1", extranonce1, fmt.Sprintf("%v bytes", len(extranonce1)/2))
+	inspection.add("extranonce2", extranonce2, fmt.Sprintf("%v bytes", len(extranonce2)/2))
+	timeNote := "notify time"
+	if nonceTime != notifyTime {
+		timeNote = "notify sent " + notifyTime
+	}
+	inspection.add("ntime", nonceTime, timeNote)
+	inspection.add("nonce", nonce, "")
+
+	coinbaseHex := coinb1 + extranonce1 + extranonce2 + coinb2
+	coinbase, err := ParseTransaction(coinbaseHex)
+	if err != nil {
+		return inspection, errors.Join(errors.New("rebuilt coinbase doesn't parse"), err)
+	}
+	inspection.Sections = append(inspection.Sections, inspectTransaction(coinbase, "Coinbase"))
+
+	coinbaseHash, err := chain.CoinbaseDigest(coinbaseHex)
+	if err != nil {
+		return inspection, err
+	}
+	merkleRoot, err := makeHeaderMerkleRoot(coinbaseHash, branch)
+	if err != nil {
+		return inspection, err
+	}
+	previous, err := reverseHex4Bytes(prevHash)
+	if err != nil {
+		return inspection, err
+	}
+	version, err := strconv.ParseUint(versionHex, 16, 32)
+	if err != nil {
+		return inspection, errors.Join(errors.New("bad version "+versionHex), err)
+	}
+	headerHex, err := blockHeader(uint(version), previous, merkleRoot, nonceTime, bits, nonce)
+	if err != nil {
+		return inspection, err
+	}
+	inspection.add("header", headerHex, "")
+
+	header, _ := hex.DecodeString(headerHex)
+	headerInspection, err := inspectHeader(header, chain)
+	if err != nil {
+		return inspection, err
+	}
+	inspection.Sections = append(inspection.Sections, headerInspection)
+
+	hashes, err := hashHeader(header, chain)
+	if err != nil {
+		return inspection, err
+	}
+	shareTarget, err := DifficultyToTarget(difficulty, chain.ShareMultiplier())
+	if err != nil {
+		return inspection, err
+	}
+	networkTarget, err := CompactToTarget(binary.LittleEndian.Uint32(header[72:76]))
+	if err != nil {
+		return inspection, err
+	}
+
+	verdict := Inspection{Title: "Verdict"}
+	verdict.add("pow hash", hashes.powHash, "")
+	verdict.add("share target", formatTarget(shareTarget), fmt.Sprintf("stratum difficulty %v", difficulty))
+	verdict.add("network target", formatTarget(networkTarget), "")
+	switch {
+	case jobID != submitJobID:
+		verdict.add("result", "stale", "submitted for a different job than the notify")
+	case HashMeetsTarget(hashes.pow, networkTarget):
+		verdict.add("result", "block candidate", "meets the network target")
+	case HashMeetsTarget(hashes.pow, shareTarget):
+		verdict.add("result", "valid share", "meets the share target")
+	case hashes.pow.Sign() == 0:
+		return inspection, errors.New("zero pow hash doesn't meet a zero share target")
+	default:
+		achieved := new(big.Float).Quo(new(big.Float).SetInt(shareTarget), new(big.Float).SetInt(hashes.pow))
+		ratio, _ := achieved.Float64()
+		verdict.add("result", "low difficulty", fmt.Sprintf("hash is worth %.6g of the required difficulty", ratio))
+	}
+	inspection.Sections = append(inspection.Sections, verdict)
+
+	return inspection, nil
+}
@@ -0,0 +1,25 @@
+package bitcoin
+
+import "testing"
+
+// A plugin answering with something other than a hash
+type brokenDigestChain struct {
+	Blockchain
+}
+
+func (brokenDigestChain) HeaderDigest(header string) (string, error) {
+	return "", nil
+}
+
+func TestInspectHeaderRejectsNonHashDigest(t *testing.T) {
+	header := "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+
+	_, err := InspectHeader(header, GetChain("litecoin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = InspectHeader(header, brokenDigestChain{GetChain("litecoin")})
+	if err == nil {
+		t.Fatal("inspected a header whose pow digest isn't a hash")
+	}
+}
@@ -0,0 +1,89 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+type CoinbaseRecipient struct {
+	Address      string
+	ScriptPubKey string
+	Percentage   float64 // 0.01 is 1% of the coinbase value
+}
+
+// Who the coinbase pays.  RewardScript receives whatever is left
+// after every recipient's share, so the outputs always add up to
+// the template's coinbase value.
+type CoinbasePayees struct {
+	RewardScript string
+	Recipients   []CoinbaseRecipient
+}
+
+type CoinbaseOutput struct {
+	ScriptPubKey string
+	Value        uint64 // Satoshis
+}
+
+func (o CoinbaseOutput) Serialize() string {
+	amount := hex.EncodeToString(eightLittleEndianBytes(o.Value))
+	return TransactionOut(amount, o.ScriptPubKey)
+}
+
+// Percentages are resolved to parts per billion so the split is exact integer math
+const percentagePrecision = 1_000_000_000
+
+func (p CoinbasePayees) Split(coinbaseValue uint64) ([]CoinbaseOutput, error) {
+	if p.RewardScript == "" {
+		return nil, errors.New("coinbase needs a reward script")
+	}
+
+	outputs := make([]CoinbaseOutput, 0, len(p.Recipients)+1)
+	remaining := coinbaseValue
+	totalShare := uint64(0)
+	for _, recipient := range p.Recipients {
+		if recipient.Percentage < 0 || recipient.Percentage > 1 {
+			return nil, fmt.Errorf("coinbase recipient %v has invalid percentage %v", recipient.Address, recipient.Percentage)
+		}
+		share := uint64(math.Round(recipient.Percentage * percentagePrecision))
+		totalShare += share
+		if totalShare > percentagePrecision {
+			return nil, errors.New("coinbase recipients add up to more than 100%")
+		}
+
+		amount := shareOf(coinbaseValue, share)
+		if amount == 0 {
+			continue
+		}
+
+		remaining -= amount
+		outputs = append(outputs, CoinbaseOutput{
+			ScriptPubKey: recipient.ScriptPubKey,
+			Value:        amount,
+		})
+	}
+
+	// Rounding dust stays with the reward output
+	outputs = append([]CoinbaseOutput{{
+		ScriptPubKey: p.RewardScript,
+		Value:        remaining,
+	}}, outputs...)
+
+	return outputs, nil
+}
+
+func shareOf(coinbaseValue, share uint64) uint64 {
+	high, low := bits.Mul64(coinbaseValue, share)
+	amount, _ := bits.Div64(high, low, percentagePrecision)
+	return amount
+}
+
+// What Split pays a recipient with this percentage of the coinbase value
+func CoinbaseShare(coinbaseValue uint64, percentage float64) (uint64, error) {
+	if percentage < 0 || percentage > 1 {
+		return 0, fmt.Errorf("invalid coinbase percentage %v", percentage)
+	}
+	return shareOf(coinbaseValue, uint64(math.Round(percentage*percentagePrecision))), nil
+}
@@ -0,0 +1,175 @@
+package bitcoin
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+// Sticky error reader over serialized blocks and transactions, check err once at the end
+type byteReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (r *byteReader) next(length int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if length < 0 || r.offset+length > len(r.data) {
+		r.err = fmt.Errorf("unexpected end of data at byte %v, wanted %v more", r.offset, length)
+		return nil
+	}
+	chunk := r.data[r.offset : r.offset+length]
+	r.offset += length
+	return chunk
+}
+
+func (r *byteReader) uint32() uint32 {
+	chunk := r.next(4)
+	if chunk == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(chunk)
+}
+
+func (r *byteReader) varUint() uint64 {
+	prefix := r.next(1)
+	if prefix == nil {
+		return 0
+	}
+	switch prefix[0] {
+	case 0xfd:
+		chunk := r.next(2)
+		if chunk == nil {
+			return 0
+		}
+		return uint64(binary.LittleEndian.Uint16(chunk))
+	case 0xfe:
+		return uint64(r.uint32())
+	case 0xff:
+		chunk := r.next(8)
+		if chunk == nil {
+			return 0
+		}
+		return binary.LittleEndian.Uint64(chunk)
+	default:
+		return uint64(prefix[0])
+	}
+}
+
+func (r *byteReader) varBytes() []byte {
+	length := r.varUint()
+	if length > uint64(len(r.data)) {
+		r.err = fmt.Errorf("length %v at byte %v runs past the data", length, r.offset)
+		return nil
+	}
+	return r.next(int(length))
+}
+
+type ParsedInput struct {
+	PreviousTxID  string // RPC byte order
+	PreviousIndex uint32
+	ScriptSig     []byte
+	Sequence      uint32
+	Witness       [][]byte
+}
+
+type ParsedOutput struct {
+	Value        uint64
+	ScriptPubKey []byte
+}
+
+type ParsedTransaction struct {
+	Version  uint32
+	Flags    byte // 0 for legacy serialization
+	Inputs   []ParsedInput
+	Outputs  []ParsedOutput
+	LockTime uint32
+	ID       string // Legacy serialization hash, RPC byte order
+	Size     int
+}
+
+func (t ParsedTransaction) IsCoinbase() bool {
+	return len(t.Inputs) == 1 && t.Inputs[0].PreviousIndex == 0xffffffff &&
+		t.Inputs[0].PreviousTxID == "0000000000000000000000000000000000000000000000000000000000000000"
+}
+
+func ParseTransaction(transactionHex string) (ParsedTransaction, error) {
+	raw, err := hex.DecodeString(transactionHex)
+	if err != nil {
+		return ParsedTransaction{}, err
+	}
+	reader := &byteReader{data: raw}
+	transaction := reader.transaction()
+	if reader.err == nil && reader.offset != len(raw) {
+		reader.err = fmt.Errorf("%v trailing bytes after the transaction", len(raw)-reader.offset)
+	}
+	return transaction, reader.err
+}
+
+// BIP144 segwit and Litecoin's MWEB flag, the MWEB body itself isn't decoded
+func (r *byteReader) transaction() ParsedTransaction {
+	var transaction ParsedTransaction
+	start := r.offset
+
+	transaction.Version = r.uint32()
+	bodyStart := r.offset
+	if r.err == nil && r.offset+2 <= len(r.data) && r.data[r.offset] == 0x00 {
+		transaction.Flags = r.next(2)[1]
+		bodyStart = r.offset
+	}
+
+	inputs := r.varUint()
+	for i := uint64(0); i < inputs && r.err == nil; i++ {
+		var input ParsedInput
+		input.PreviousTxID = hex.EncodeToString(reverse(r.next(32)))
+		input.PreviousIndex = r.uint32()
+		input.ScriptSig = r.varBytes()
+		input.Sequence = r.uint32()
+		transaction.Inputs = append(transaction.Inputs, input)
+	}
+
+	outputs := r.varUint()
+	for i := uint64(0); i < outputs && r.err == nil; i++ {
+		var output ParsedOutput
+		value := r.next(8)
+		if value != nil {
+			output.Value = binary.LittleEndian.Uint64(value)
+		}
+		output.ScriptPubKey = r.varBytes()
+		transaction.Outputs = append(transaction.Outputs, output)
+	}
+	bodyEnd := r.offset
+
+	if transaction.Flags&transactionFlagWitness != 0 {
+		for i := range transaction.Inputs {
+			items := r.varUint()
+			for j := uint64(0); j < items && r.err == nil; j++ {
+				transaction.Inputs[i].Witness = append(transaction.Inputs[i].Witness, r.varBytes())
+			}
+		}
+	}
+	if transaction.Flags&transactionFlagMWEB != 0 {
+		hasBody := r.next(1)
+		if hasBody != nil && hasBody[0] != 0 && r.err == nil {
+			r.err = fmt.Errorf("MWEB transaction bodies at byte %v aren't decoded", r.offset)
+		}
+	}
+
+	lockTimeStart := r.offset
+	transaction.LockTime = r.uint32()
+	if r.err != nil {
+		return transaction
+	}
+
+	legacy := append([]byte{}, r.data[start:start+4]...)
+	legacy = append(legacy, r.data[bodyStart:bodyEnd]...)
+	legacy = append(legacy, r.data[lockTimeStart:lockTimeStart+4]...)
+	id := doubleSha256Bytes(legacy)
+	transaction.ID = hex.EncodeToString(reverse(id[:]))
+	transaction.Size = r.offset - start
+
+	return transaction
+}
@@ -0,0 +1,273 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Declarative chain parameters, either built in or loaded from a chains file
+type ChainDefinition struct {
+	Name             string `json:"name"`
+	Algorithm        string `json:"algorithm"`
+	CoinbaseDigest   string `json:"coinbase_digest"`  // Defaults to sha256d
+	CoinbaseVersion  uint32 `json:"coinbase_version"` // Defaults to 1
+	MinConfirmations uint   `json:"min_confirmations"`
+	// Overrides the algorithm's stratum multiplier when non zero
+	ShareMultiplier float64 `json:"share_multiplier"`
+	// Checked against createauxblock's chainid when non zero
+	AuxChainID uint32 `json:"auxpow_chain_id"`
+	// getblocktemplate "rules", defaults to ["segwit"]
+	BlockTemplateRules []string `json:"gbt_rules"`
+	// Defaults to createauxblock with a little endian target
+	AuxRPC AuxRPCFlavour `json:"aux_rpc"`
+	// Smallest output in coins the network relays without a penalty, payouts
+	// below it are held back.  Zero leaves it to the node.
+	DustLimit float64 `json:"dust_limit"`
+
+	Mainnet AddressFormatDefinition `json:"mainnet"`
+	Testnet AddressFormatDefinition `json:"testnet"`
+	Regtest AddressFormatDefinition `json:"regtest"`
+}
+
+// Version bytes as hex strings, i.e. "1e" or ["32", "05"]
+type AddressFormatDefinition struct {
+	PubKeyHash []string `json:"pubkey_hash"`
+	ScriptHash []string `json:"script_hash"`
+	Bech32HRP  string   `json:"bech32_hrp"`
+}
+
+var algorithms = map[string]Algorithm{
+	Scrypt.Name:  Scrypt,
+	Sha256d.Name: Sha256d,
+}
+
+var digests = map[string]func([]byte) ([32]byte, error){
+	"sha256d": doubleSha256Digest,
+}
+
+var chainRegistry = struct {
+	sync.RWMutex
+	chains map[string]Blockchain
+}{chains: make(map[string]Blockchain)}
+
+func init() {
+	for _, definition := range builtinChains {
+		err := RegisterChain(definition)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Adds or replaces a chain, later definitions win so operators can override built-ins
+func RegisterChain(definition ChainDefinition) error {
+	chain, err := definition.build()
+	if err != nil {
+		return err
+	}
+
+	RegisterBlockchain(chain)
+
+	return nil
+}
+
+// Builds a chain without registering it, i.e. the local half of a plugin
+func DefineChain(definition ChainDefinition) (Blockchain, error) {
+	chain, err := definition.build()
+	if err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// Chains implemented outside of definitions, i.e. out of process plugins
+func RegisterBlockchain(chain Blockchain) {
+	chainRegistry.Lock()
+	chainRegistry.chains[chain.ChainName()] = chain
+	chainRegistry.Unlock()
+}
+
+func AlgorithmByName(name string) (Algorithm, bool) {
+	algorithm, exists := algorithms[name]
+	return algorithm, exists
+}
+
+// Registers every definition in a JSON array file
+func LoadChainDefinitions(fileName string) error {
+	fileBytes, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	var definitions []ChainDefinition
+	err = json.Unmarshal(fileBytes, &definitions)
+	if err != nil {
+		return errors.Join(errors.New("invalid chains file "+fileName), err)
+	}
+
+	for _, definition := range definitions {
+		err = RegisterChain(definition)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func lookupChain(chainName string) (Blockchain, bool) {
+	chainRegistry.RLock()
+	defer chainRegistry.RUnlock()
+	chain, exists := chainRegistry.chains[chainName]
+	return chain, exists
+}
+
+func (d ChainDefinition) build() (*definedChain, error) {
+	if d.Name == "" {
+		return nil, errors.New("chain definition without a name")
+	}
+
+	chain := &definedChain{definition: d}
+
+	var exists bool
+	chain.algorithm, exists = algorithms[d.Algorithm]
+	if !exists {
+		m := "%v: unknown algorithm %q"
+		m = fmt.Sprintf(m, d.Name, d.Algorithm)
+		return nil, errors.New(m)
+	}
+
+	if d.CoinbaseDigest == "" {
+		d.CoinbaseDigest = "sha256d"
+	}
+	chain.coinbaseDigest, exists = digests[d.CoinbaseDigest]
+	if !exists {
+		m := "%v: unknown coinbase digest %q"
+		m = fmt.Sprintf(m, d.Name, d.CoinbaseDigest)
+		return nil, errors.New(m)
+	}
+
+	var err error
+	networks := []*AddressFormat{&chain.networks.Mainnet, &chain.networks.Testnet, &chain.networks.Regtest}
+	for i, format := range []AddressFormatDefinition{d.Mainnet, d.Testnet, d.Regtest} {
+		*networks[i], err = format.build()
+		if err != nil {
+			return nil, errors.Join(errors.New(d.Name+": invalid address format"), err)
+		}
+	}
+
+	if d.CoinbaseVersion == 0 {
+		chain.definition.CoinbaseVersion = 1
+	}
+	if d.ShareMultiplier == 0 {
+		chain.definition.ShareMultiplier = chain.algorithm.ShareMultiplier
+	}
+	if d.BlockTemplateRules == nil {
+		chain.definition.BlockTemplateRules = []string{"segwit"}
+	}
+	chain.definition.AuxRPC = d.AuxRPC.withDefaults()
+	err = chain.definition.AuxRPC.validate()
+	if err != nil {
+		return nil, errors.Join(errors.New(d.Name+": invalid aux rpc"), err)
+	}
+
+	return chain, nil
+}
+
+func (d AddressFormatDefinition) build() (AddressFormat, error) {
+	var err error
+	format := AddressFormat{Bech32HRP: d.Bech32HRP}
+	format.PubKeyHash, err = versionBytes(d.PubKeyHash)
+	if err != nil {
+		return format, err
+	}
+	format.ScriptHash, err = versionBytes(d.ScriptHash)
+	return format, err
+}
+
+func versionBytes(hexBytes []string) ([]byte, error) {
+	versions := make([]byte, len(hexBytes))
+	for i, hexByte := range hexBytes {
+		decoded, err := hex.DecodeString(hexByte)
+		if err != nil || len(decoded) != 1 {
+			return nil, errors.New("version bytes must be one hex byte each: " + hexByte)
+		}
+		versions[i] = decoded[0]
+	}
+	return versions, nil
+}
+
+type definedChain struct {
+	definition     ChainDefinition
+	algorithm      Algorithm
+	coinbaseDigest func([]byte) ([32]byte, error)
+	networks       AddressNetworks
+}
+
+func (c *definedChain) ChainName() string {
+	return c.definition.Name
+}
+
+func (c *definedChain) Algorithm() Algorithm {
+	return c.algorithm
+}
+
+func (c *definedChain) CoinbaseDigest(coinbase string) (string, error) {
+	return hexDigest(c.coinbaseDigest, coinbase)
+}
+
+func (c *definedChain) HeaderDigest(header string) (string, error) {
+	return c.algorithm.HeaderDigest(header)
+}
+
+func (c *definedChain) CoinbaseDigestBytes(coinbase []byte) ([32]byte, error) {
+	return c.coinbaseDigest(coinbase)
+}
+
+func (c *definedChain) HeaderDigestBytes(header []byte) ([32]byte, error) {
+	return c.algorithm.digest(header)
+}
+
+func (c *definedChain) ShareMultiplier() float64 {
+	return c.definition.ShareMultiplier
+}
+
+func (c *definedChain) MinimumConfirmations() uint {
+	return c.definition.MinConfirmations
+}
+
+func (c *definedChain) AuxChainID() uint32 {
+	return c.definition.AuxChainID
+}
+
+func (c *definedChain) BlockTemplateRules() []string {
+	return c.definition.BlockTemplateRules
+}
+
+func (c *definedChain) AuxRPC() AuxRPCFlavour {
+	return c.definition.AuxRPC
+}
+
+func (c *definedChain) CoinbaseVersion() uint32 {
+	return c.definition.CoinbaseVersion
+}
+
+func (c *definedChain) DustLimit() float64 {
+	return c.definition.DustLimit
+}
+
+func (c *definedChain) AddressNetworks() AddressNetworks {
+	return c.networks
+}
+
+func (c *definedChain) ValidMainnetAddress(address string) bool {
+	return ValidAddress(address, c.networks.Mainnet)
+}
+
+func (c *definedChain) ValidTestnetAddress(address string) bool {
+	return ValidAddress(address, c.networks.Testnet)
+}
@@ -0,0 +1,76 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+// https://developer.bitcoin.org/devguide/transactions.html#standard-transactions
+
+const (
+	opZero        = 0x00
+	opOne         = 0x51
+	opSixteen     = 0x60
+	opReturn      = 0x6a
+	opDup         = 0x76
+	opEqual       = 0x87
+	opEqualVerify = 0x88
+	opHash160     = 0xa9
+	opCheckSig    = 0xac
+)
+
+func (a Address) ScriptPubKey() ([]byte, error) {
+	switch a.Type {
+	case AddressP2PKH:
+		if len(a.Program) != 20 {
+			return nil, errors.New("p2pkh address needs a 20 byte hash")
+		}
+		script := []byte{opDup, opHash160, 20}
+		script = append(script, a.Program...)
+		return append(script, opEqualVerify, opCheckSig), nil
+	case AddressP2SH:
+		if len(a.Program) != 20 {
+			return nil, errors.New("p2sh address needs a 20 byte hash")
+		}
+		script := []byte{opHash160, 20}
+		script = append(script, a.Program...)
+		return append(script, opEqual), nil
+	case AddressWitness:
+		// P2WPKH, P2WSH and P2TR are all <version opcode> <program push>
+		if a.Version > 16 || len(a.Program) < 2 || len(a.Program) > 40 {
+			return nil, errors.New("invalid witness program")
+		}
+		versionOpcode := byte(opZero)
+		if a.Version > 0 {
+			versionOpcode = opOne + a.Version - 1
+		}
+		script := []byte{versionOpcode, byte(len(a.Program))}
+		return append(script, a.Program...), nil
+	default:
+		return nil, errors.New("unknown address type")
+	}
+}
+
+// Builds the output script for an address without asking a wallet RPC
+func AddressToScriptPubKey(chain Blockchain, network, address string) (string, error) {
+	if scripter, ok := chain.(AddressScripter); ok {
+		return scripter.ScriptPubKey(network, address)
+	}
+
+	format, err := chain.AddressNetworks().ForNetwork(network)
+	if err != nil {
+		return "", err
+	}
+
+	decoded, err := DecodeAddress(address, format)
+	if err != nil {
+		return "", err
+	}
+
+	script, err := decoded.ScriptPubKey()
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(script), nil
+}
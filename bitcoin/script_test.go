@@ -0,0 +1,75 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Litecoin segwit addresses for BIP350's witness programs
+func litecoinSegwit(t *testing.T, version byte, program string) string {
+	address, err := SegwitAddressEncode("ltc", version, mustDecodeHex(t, program))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+func TestAddressToScriptPubKey(t *testing.T) {
+	litecoin := GetChain("litecoin")
+	keyHash := mustDecodeHex(t, genesisKeyHash)
+	tests := []struct {
+		kind, address, script string
+	}{
+		{"p2pkh", Base58CheckEncode(0x30, keyHash), "76a914" + genesisKeyHash + "88ac"},
+		{"p2sh", Base58CheckEncode(0x32, keyHash), "a914" + genesisKeyHash + "87"},
+		{"legacy p2sh", Base58CheckEncode(0x05, keyHash), "a914" + genesisKeyHash + "87"},
+		{"p2wpkh", litecoinSegwit(t, 0, "751e76e8199196d454941c45d1b3a323f1433bd6"), "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"p2wsh", litecoinSegwit(t, 0, "1863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"), "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+		{"p2tr", litecoinSegwit(t, 1, "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"), "512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+	}
+	for _, test := range tests {
+		script, err := AddressToScriptPubKey(litecoin, "main", test.address)
+		if err != nil {
+			t.Errorf("%v %v: %v", test.kind, test.address, err)
+			continue
+		}
+		if script != test.script {
+			t.Errorf("%v %v: got %v, want %v", test.kind, test.address, script, test.script)
+		}
+	}
+}
+
+func TestAddressToScriptPubKeyRejectsOtherNetworks(t *testing.T) {
+	keyHash := mustDecodeHex(t, genesisKeyHash)
+	testnetSegwit, err := SegwitAddressEncode("tltc", 0, mustDecodeHex(t, "751e76e8199196d454941c45d1b3a323f1433bd6"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		chain, network, address string
+	}{
+		{"litecoin", "main", Base58CheckEncode(0x6f, keyHash)},     // Testnet's
+		{"litecoin", "test", Base58CheckEncode(0x30, keyHash)},     // Mainnet's
+		{"litecoin", "main", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"}, // Bitcoin's
+		{"litecoin", "main", "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4"},
+		{"litecoin", "regtest", testnetSegwit},
+		{"dogecoin", "main", Base58CheckEncode(0x30, keyHash)},                                 // Litecoin's
+		{"dogecoin", "main", litecoinSegwit(t, 0, "751e76e8199196d454941c45d1b3a323f1433bd6")}, // Dogecoin has no segwit
+		{"dogecoin", "test", Base58CheckEncode(0x1e, keyHash)},
+		{"litecoin", "nonet", Base58CheckEncode(0x30, keyHash)},
+	}
+	for _, test := range tests {
+		script, err := AddressToScriptPubKey(GetChain(test.chain), test.network, test.address)
+		if err == nil {
+			t.Errorf("%v %v accepted %v as %v", test.chain, test.network, test.address, script)
+		}
+	}
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
@@ -19,18 +19,37 @@ func (s *Submission) Serialize() string {
 		s.TransactionBuffer
 }
 
-func (b *BitcoinBlock) createSubmissionHex() string {
+func (b *BitcoinBlock) createSubmissionHex() (string, error) {
+	err := b.Template.checkTransactionSerialization()
+	if err != nil {
+		return "", err
+	}
+
+	coinbase := b.coinbaseHex()
+	if b.Template.DefaultWitnessCommitment != "" {
+		coinbase, err = witnessCoinbase(coinbase)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	transactionCount := uint(len(b.Template.Transactions) + 1) // 1 for coinbase
 
 	submission := Submission{
-		Header:            b.header,
+		Header:            b.Header(),
 		TransactionCount:  varUint(transactionCount),
-		Coinbase:          b.coinbase,
+		Coinbase:          coinbase,
 		TransactionBuffer: b.buildTransactionBuffer(),
 	}
 
-	// submissionDebugOutput(submission.Header, submission.TransactionCount, submission.Coinbase, submission.TransactionBuffer, submission.Serialize())
-	return submission.Serialize()
+	serialized := submission.Serialize()
+	if b.Template.HasHogEx() {
+		// The extension block is an optional pointer, 01 marks it present
+		serialized = serialized + "01" + b.Template.MimbleWimble
+	}
+
+	// submissionDebugOutput(submission.Header, submission.TransactionCount, submission.Coinbase, submission.TransactionBuffer, serialized)
+	return serialized, nil
 }
 
 func (b *BitcoinBlock) buildTransactionBuffer() string {
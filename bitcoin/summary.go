@@ -0,0 +1,127 @@
+package bitcoin
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Set in the version of a block carrying a merged mining proof after its header
+const blockVersionAuxPow = 1 << 8
+
+// What a node's rawblock notification says about who mined the block
+type BlockSummary struct {
+	Hash         string // RPC byte order
+	PreviousHash string // RPC byte order
+	Height       uint   // From the BIP34 coinbase, 0 when the coinbase has none
+	Time         time.Time
+	Transactions uint64
+	MergeMined   bool
+
+	// The miner's coinbase text and first paying output.  A merge mined
+	// block's text comes from the parent coinbase, the pool's own.
+	Tag          string
+	PayoutScript string
+	PayoutType   string
+}
+
+func SummarizeBlock(raw []byte, chain Blockchain) (BlockSummary, error) {
+	var summary BlockSummary
+	if len(raw) < 80 {
+		return summary, errors.New("block shorter than its header")
+	}
+
+	// The block hash is the coinbase digest of the header, not its proof of work
+	digest, err := chain.CoinbaseDigest(hex.EncodeToString(raw[:80]))
+	if err != nil {
+		return summary, err
+	}
+	summary.Hash, err = reverseHexBytes(digest)
+	if err != nil {
+		return summary, err
+	}
+	summary.PreviousHash = hex.EncodeToString(reverse(raw[4:36]))
+	summary.Time = time.Unix(int64(binary.LittleEndian.Uint32(raw[68:72])), 0)
+
+	reader := &byteReader{data: raw, offset: 80}
+
+	version := binary.LittleEndian.Uint32(raw[0:4])
+	var parentScriptSig []byte
+	if chain.AuxChainID() != 0 && version&blockVersionAuxPow != 0 {
+		summary.MergeMined = true
+		parentScriptSig, err = reader.coinbaseTransaction()
+		if err != nil {
+			return summary, errors.Join(errors.New("parent coinbase"), err)
+		}
+		reader.next(32) // parent hash
+		reader.merkleBranch()
+		reader.merkleBranch()
+		reader.next(80) // parent header
+	}
+
+	summary.Transactions = reader.varUint()
+	if reader.err == nil && summary.Transactions == 0 {
+		return summary, errors.New("block without a coinbase")
+	}
+	coinbase := reader.transaction()
+	if reader.err != nil {
+		return summary, reader.err
+	}
+	if !coinbase.IsCoinbase() {
+		return summary, errors.New("first transaction isn't a coinbase")
+	}
+
+	scriptSig := coinbase.Inputs[0].ScriptSig
+	summary.Height = coinbaseHeight(scriptSig)
+	if summary.MergeMined {
+		scriptSig = parentScriptSig
+	}
+	summary.Tag = coinbaseText(scriptSig)
+
+	for _, output := range coinbase.Outputs {
+		if output.Value == 0 {
+			continue
+		}
+		summary.PayoutScript = hex.EncodeToString(output.ScriptPubKey)
+		summary.PayoutType = describeScript(output.ScriptPubKey)
+		break
+	}
+
+	return summary, nil
+}
+
+// BIP34 puts the height first as a minimal push
+func coinbaseHeight(scriptSig []byte) uint {
+	if len(scriptSig) == 0 || scriptSig[0] == 0 || scriptSig[0] > 8 || int(scriptSig[0]) >= len(scriptSig) {
+		return 0
+	}
+	height := new(big.Int).SetBytes(reverse(scriptSig[1 : 1+int(scriptSig[0])]))
+	return uint(height.Uint64())
+}
+
+// Printable runs long enough to be a pool's tag rather than chance bytes
+func coinbaseText(scriptSig []byte) string {
+	const shortestRun = 4
+
+	var runs []string
+	var run strings.Builder
+	flush := func() {
+		if run.Len() >= shortestRun {
+			runs = append(runs, run.String())
+		}
+		run.Reset()
+	}
+	for _, b := range scriptSig {
+		if b >= 0x20 && b < 0x7f {
+			run.WriteByte(b)
+			continue
+		}
+		flush()
+	}
+	flush()
+
+	return strings.Join(runs, " ")
+}
@@ -0,0 +1,35 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+const (
+	genesisHeader   = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+	genesisCoinbase = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+)
+
+// Naming a block never needs its proof of work, which a chain may only be
+// able to compute slowly or remotely
+func TestSummarizeBlockOnlyHashesForTheBlockHash(t *testing.T) {
+	raw, err := hex.DecodeString(genesisHeader + "01" + genesisCoinbase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := SummarizeBlock(raw, brokenDigestChain{GetChain("bitcoin")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Hash != "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f" {
+		t.Errorf("hash %v, want the genesis block's in RPC order", summary.Hash)
+	}
+	if summary.PreviousHash != strings.Repeat("0", 64) || summary.Transactions != 1 {
+		t.Errorf("previous %v with %v transactions", summary.PreviousHash, summary.Transactions)
+	}
+	if !strings.Contains(summary.Tag, "The Times 03/Jan/2009") {
+		t.Errorf("tag %q, want the coinbase text", summary.Tag)
+	}
+}
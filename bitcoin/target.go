@@ -3,76 +3,146 @@ package bitcoin
 import (
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"strconv"
 )
 
 // https://developer.bitcoin.org/reference/block_chain.html#target-nbits
 
+// Big endian hex, as getblocktemplate and parsed aux blocks carry it
 type Target string
 
-const highestTarget = "00000000ffff0000000000000000000000000000000000000000000000000000"
-
 func (t *Target) ToBig() (*big.Int, bool) {
 	return new(big.Int).SetString(string(*t), 16)
 }
 
-func (t *Target) ToDifficulty() (float64, big.Accuracy) {
-	highestTargetBig, success := new(big.Int).SetString(highestTarget, 16)
-	if !success {
-		panic("Failed to convert highest target value to big int")
+// Bitcoin's difficulty 1 target, compact 0x1d00ffff.  Every chain's
+// difficulty is measured against it, stratum difficulty is then scaled by
+// the algorithm's share multiplier.
+var diff1Target = new(big.Int).Lsh(big.NewInt(0xffff), 208)
+
+var maxTarget = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+const (
+	compactSignBit      = 0x00800000
+	compactMantissaMask = 0x007fffff
+)
+
+// Expands nBits like CBigNum::SetCompact, rejecting the negative and
+// overflowing encodings consensus code refuses
+func CompactToTarget(bits uint32) (*big.Int, error) {
+	exponent := uint(bits >> 24)
+	mantissa := uint64(bits & compactMantissaMask)
+	// Small exponents shift mantissa bytes out before the checks look at it
+	if exponent <= 3 {
+		mantissa >>= 8 * (3 - exponent)
 	}
-	highestTargetBigFloat := new(big.Float).SetInt(highestTargetBig)
 
-	targetBig, success := t.ToBig()
-	if !success {
-		panic("Failed to convert target to big int")
+	if mantissa != 0 && bits&compactSignBit != 0 {
+		return nil, fmt.Errorf("compact target %08x is negative", bits)
+	}
+	if mantissa != 0 && (exponent > 34 ||
+		(mantissa > 0xff && exponent > 33) ||
+		(mantissa > 0xffff && exponent > 32)) {
+		return nil, fmt.Errorf("compact target %08x overflows 256 bits", bits)
 	}
-	targetBigFloat := new(big.Float).SetInt(targetBig)
-	difficulty := new(big.Float).Quo(highestTargetBigFloat, targetBigFloat)
 
-	return difficulty.Float64()
+	target := new(big.Int).SetUint64(mantissa)
+	if exponent > 3 {
+		target.Lsh(target, 8*(exponent-3))
+	}
+	return target, nil
 }
 
-func TargetFromDifficulty(difficulty float64) (Target, big.Accuracy) {
-	highestTargetBig, success := new(big.Int).SetString(highestTarget, 16)
-	if !success {
-		panic("Failed to convert highest target value to big int")
+// Encodes a target the way nodes put it in headers, truncating to the
+// three most significant bytes
+func TargetToCompact(target *big.Int) uint32 {
+	if target == nil || target.Sign() <= 0 {
+		return 0
 	}
-	highestTargetBigFloat := new(big.Float).SetInt(highestTargetBig)
-
-	difficultyBigFloat := new(big.Float).SetFloat64(difficulty)
 
-	targetBigFloat := new(big.Float).Quo(highestTargetBigFloat, difficultyBigFloat)
+	size := uint((target.BitLen() + 7) / 8)
+	var mantissa uint64
+	if size <= 3 {
+		mantissa = target.Uint64() << (8 * (3 - size))
+	} else {
+		mantissa = new(big.Int).Rsh(target, 8*(size-3)).Uint64()
+	}
 
-	targetBigInt, bigAccuracy := targetBigFloat.Int(nil)
-	targetHex := targetBigInt.Text(16)
+	// The top mantissa bit is the sign, move a byte into the exponent instead
+	if mantissa&compactSignBit != 0 {
+		mantissa >>= 8
+		size++
+	}
 
-	return Target(targetHex), bigAccuracy
+	return uint32(size)<<24 | uint32(mantissa)
 }
 
+// Template and header bits are 8 hex characters, most significant first
 func TargetFromBits(bitsHex string) (Target, error) {
-	l := len(bitsHex)
-	if l < 8 {
-		return "", errors.New("string too short")
-	}
-	if l%2 == 0 {
-		return "", errors.New("string must be even length")
+	if len(bitsHex) != 8 {
+		return "", errors.New("bits must be 8 hex characters, got " + bitsHex)
 	}
 
-	exponent := bitsHex[:2]
-	exponentValue, err := strconv.ParseInt(exponent, 16, 8)
+	bits, err := strconv.ParseUint(bitsHex, 16, 32)
 	if err != nil {
 		return "", err
 	}
-	significand := bitsHex[3:]
-	significandValue, err := strconv.ParseInt(significand, 16, 8)
+
+	target, err := CompactToTarget(uint32(bits))
 	if err != nil {
 		return "", err
 	}
 
-	target := significandValue*256 ^ (exponentValue - 3)
-	targetHex := fmt.Sprintf("%x", target)
+	return Target(formatTarget(target)), nil
+}
+
+// The largest hash a share of this difficulty may have, exactly
+// floor(diff1 * shareMultiplier / difficulty).  Use a multiplier of 1 for
+// Bitcoin difficulty.
+func DifficultyToTarget(difficulty, shareMultiplier float64) (*big.Int, error) {
+	if math.IsNaN(difficulty) || math.IsInf(difficulty, 0) || difficulty <= 0 {
+		return nil, fmt.Errorf("invalid difficulty %v", difficulty)
+	}
+	if math.IsNaN(shareMultiplier) || math.IsInf(shareMultiplier, 0) || shareMultiplier <= 0 {
+		return nil, fmt.Errorf("invalid share multiplier %v", shareMultiplier)
+	}
+
+	ratio := new(big.Rat).SetInt(diff1Target)
+	ratio.Mul(ratio, new(big.Rat).SetFloat64(shareMultiplier))
+	ratio.Quo(ratio, new(big.Rat).SetFloat64(difficulty))
+
+	target := new(big.Int).Quo(ratio.Num(), ratio.Denom())
+	if target.Cmp(maxTarget) > 0 {
+		target.Set(maxTarget)
+	}
+
+	return target, nil
+}
+
+// Inverse of DifficultyToTarget, rounded to the nearest float64
+func TargetToDifficulty(target *big.Int, shareMultiplier float64) float64 {
+	if target == nil || target.Sign() <= 0 {
+		return 0
+	}
+
+	ratio := new(big.Rat).SetInt(diff1Target)
+	ratio.Mul(ratio, new(big.Rat).SetFloat64(shareMultiplier))
+	ratio.Quo(ratio, new(big.Rat).SetInt(target))
+
+	difficulty, _ := ratio.Float64()
+	return difficulty
+}
+
+// Proof of work is met when the hash, read as a number, doesn't exceed the target
+func HashMeetsTarget(hash, target *big.Int) bool {
+	if hash == nil || target == nil {
+		return false
+	}
+	return hash.Cmp(target) <= 0
+}
 
-	return Target(targetHex), nil
+func formatTarget(target *big.Int) string {
+	return fmt.Sprintf("%064x", target)
 }
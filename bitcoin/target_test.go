@@ -0,0 +1,144 @@
+package bitcoin
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func hexTarget(t *testing.T, target string) *big.Int {
+	value, ok := new(big.Int).SetString(target, 16)
+	if !ok {
+		t.Fatalf("bad test target %v", target)
+	}
+	return value
+}
+
+// Bitcoin Core's SetCompact/GetCompact vectors from arith_uint256_tests
+func TestCompactToTarget(t *testing.T) {
+	tests := []struct {
+		bits    uint32
+		target  string
+		compact uint32 // What the target encodes back to
+		invalid bool
+	}{
+		{bits: 0x00000000, target: "0", compact: 0},
+		{bits: 0x00123456, target: "0", compact: 0},
+		{bits: 0x01003456, target: "0", compact: 0},
+		{bits: 0x02000056, target: "0", compact: 0},
+		{bits: 0x03000000, target: "0", compact: 0},
+		{bits: 0x04000000, target: "0", compact: 0},
+		{bits: 0x00923456, target: "0", compact: 0},
+		{bits: 0x01803456, target: "0", compact: 0},
+		{bits: 0x02800056, target: "0", compact: 0},
+		{bits: 0x03800000, target: "0", compact: 0},
+		{bits: 0x04800000, target: "0", compact: 0},
+		{bits: 0x01123456, target: "12", compact: 0x01120000},
+		{bits: 0x02123456, target: "1234", compact: 0x02123400},
+		{bits: 0x03123456, target: "123456", compact: 0x03123456},
+		{bits: 0x04123456, target: "12345600", compact: 0x04123456},
+		{bits: 0x05009234, target: "92340000", compact: 0x05009234},
+		{bits: 0x20123456, target: "1234560000000000000000000000000000000000000000000000000000000000", compact: 0x20123456},
+		{bits: 0x1d00ffff, target: "ffff0000000000000000000000000000000000000000000000000000", compact: 0x1d00ffff},
+		{bits: 0x1b0404cb, target: "404cb000000000000000000000000000000000000000000000000", compact: 0x1b0404cb},
+
+		// Negative
+		{bits: 0x01fedcba, invalid: true},
+		{bits: 0x04923456, invalid: true},
+		{bits: 0x1d80ffff, invalid: true},
+
+		// Overflowing 256 bits
+		{bits: 0xff123456, invalid: true},
+		{bits: 0x23000001, invalid: true},
+		{bits: 0x22000100, invalid: true},
+		{bits: 0x21010000, invalid: true},
+	}
+	for _, test := range tests {
+		target, err := CompactToTarget(test.bits)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("%08x: accepted as %x", test.bits, target)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%08x: %v", test.bits, err)
+			continue
+		}
+		if target.Cmp(hexTarget(t, test.target)) != 0 {
+			t.Errorf("%08x: got %x, want %v", test.bits, target, test.target)
+		}
+		if compact := TargetToCompact(target); compact != test.compact {
+			t.Errorf("%08x: encodes back to %08x, want %08x", test.bits, compact, test.compact)
+		}
+	}
+}
+
+func TestTargetToCompact(t *testing.T) {
+	tests := []struct {
+		target  string
+		compact uint32
+	}{
+		{"80", 0x02008000},         // Top mantissa bit moves into the exponent
+		{"800000", 0x04008000},     // Likewise for a full three bytes
+		{"123456789a", 0x05123456}, // Truncated to three bytes
+		{"ffff0000000000000000000000000000000000000000000000000000", 0x1d00ffff},
+	}
+	for _, test := range tests {
+		compact := TargetToCompact(hexTarget(t, test.target))
+		if compact != test.compact {
+			t.Errorf("%v: got %08x, want %08x", test.target, compact, test.compact)
+		}
+		target, err := CompactToTarget(compact)
+		if err != nil || TargetToCompact(target) != compact {
+			t.Errorf("%v: %08x doesn't round trip (%v)", test.target, compact, err)
+		}
+	}
+	if TargetToCompact(nil) != 0 || TargetToCompact(big.NewInt(-1)) != 0 {
+		t.Error("nil and negative targets should encode as zero")
+	}
+}
+
+func TestDifficultyToTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		difficulty float64
+		multiplier float64
+		target     string
+	}{
+		{"sha256d difficulty 1", 1, Sha256d.ShareMultiplier, "ffff0000000000000000000000000000000000000000000000000000"},
+		{"sha256d difficulty 2", 2, Sha256d.ShareMultiplier, "7fff8000000000000000000000000000000000000000000000000000"},
+		{"sha256d difficulty 3", 3, Sha256d.ShareMultiplier, "55550000000000000000000000000000000000000000000000000000"},
+		{"sha256d difficulty 0.5", 0.5, Sha256d.ShareMultiplier, "1fffe0000000000000000000000000000000000000000000000000000"},
+		{"scrypt stratum difficulty 1", 1, Scrypt.ShareMultiplier, "ffff00000000000000000000000000000000000000000000000000000000"},
+		{"scrypt stratum difficulty 65536", 65536, Scrypt.ShareMultiplier, "ffff0000000000000000000000000000000000000000000000000000"},
+		{"scrypt stratum difficulty 1024", 1024, Scrypt.ShareMultiplier, "3fffc00000000000000000000000000000000000000000000000000000"},
+		{"clamped to 256 bits", 1e-20, Scrypt.ShareMultiplier, "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"},
+	}
+	for _, test := range tests {
+		target, err := DifficultyToTarget(test.difficulty, test.multiplier)
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		if target.Cmp(hexTarget(t, test.target)) != 0 {
+			t.Errorf("%v: got %x, want %v", test.name, target, test.target)
+		}
+		if test.difficulty >= 1e-10 {
+			difficulty := TargetToDifficulty(target, test.multiplier)
+			if math.Abs(difficulty-test.difficulty) > test.difficulty*1e-12 {
+				t.Errorf("%v: round trips to difficulty %v", test.name, difficulty)
+			}
+		}
+	}
+
+	invalid := []struct{ difficulty, multiplier float64 }{
+		{0, 1}, {-1, 1}, {math.NaN(), 1}, {math.Inf(1), 1}, {1, 0}, {1, math.NaN()},
+	}
+	for _, test := range invalid {
+		_, err := DifficultyToTarget(test.difficulty, test.multiplier)
+		if err == nil {
+			t.Errorf("difficulty %v with multiplier %v: accepted", test.difficulty, test.multiplier)
+		}
+	}
+}
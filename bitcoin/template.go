@@ -1,5 +1,7 @@
 package bitcoin
 
+import "encoding/json"
+
 type Transaction struct {
 	Data string `json:"data"`
 	ID   string `json:"txid"`
@@ -17,4 +19,8 @@ type Template struct {
 	Transactions             []Transaction `json:"transactions"`
 	CurrentTime              uint          `json:"curtime"`
 	MimbleWimble             string        `json:"mweb"`
+
+	// getblocktemplate's result as the node sent it, for coinbase builders
+	// that need fields this struct doesn't keep
+	Raw json.RawMessage `json:"-"`
 }
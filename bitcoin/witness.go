@@ -0,0 +1,95 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// https://github.com/bitcoin/bips/blob/master/bip-0141.mediawiki#commitment-structure
+// https://github.com/litecoin-project/lips/blob/master/lip-0003.mediawiki
+
+const (
+	// default_witness_commitment is built with an all zero reserved value
+	witnessReservedValue = "0000000000000000000000000000000000000000000000000000000000000000"
+
+	transactionFlagWitness = 0x01
+	transactionFlagMWEB    = 0x08 // Litecoin's HogEx and MWEB transactions
+)
+
+// The coinbase hashed into the merkle root is the legacy serialization.
+// In the block itself it carries the segwit marker and the reserved value
+// as the single witness stack item of its input.
+func witnessCoinbase(coinbase string) (string, error) {
+	// version (4) + marker-less body + lock time (4)
+	if len(coinbase) < 16 || len(coinbase)%2 != 0 {
+		return "", errors.New("coinbase too short to carry a witness")
+	}
+
+	version := coinbase[:8]
+	body := coinbase[8 : len(coinbase)-8]
+	lockTime := coinbase[len(coinbase)-8:]
+
+	var builder strings.Builder
+	builder.Grow(len(coinbase) + 4 + 4 + len(witnessReservedValue))
+	builder.WriteString(version)
+	builder.WriteString("0001") // marker + flag
+	builder.WriteString(body)
+	builder.WriteString("01") // one stack item
+	builder.WriteString(varUint(uint(len(witnessReservedValue) / 2)))
+	builder.WriteString(witnessReservedValue)
+	builder.WriteString(lockTime)
+
+	return builder.String(), nil
+}
+
+// Reads the serialization flags of a raw transaction, 0 for legacy serialization
+func transactionFlags(transactionHex string) (byte, error) {
+	if len(transactionHex) < 12 {
+		return 0, errors.New("transaction too short")
+	}
+	marker, err := hex.DecodeString(transactionHex[8:12])
+	if err != nil {
+		return 0, err
+	}
+	if marker[0] != 0x00 {
+		return 0, nil
+	}
+	if marker[1] == 0 {
+		return 0, errors.New("transaction has a segwit marker without flags")
+	}
+	return marker[1], nil
+}
+
+// Checks every template transaction's serialization against what the block can carry
+func (t *Template) checkTransactionSerialization() error {
+	segwit := t.DefaultWitnessCommitment != ""
+	for i, transaction := range t.Transactions {
+		flags, err := transactionFlags(transaction.Data)
+		if err != nil {
+			return fmt.Errorf("template transaction %v (%v): %v", i, transaction.ID, err)
+		}
+		if flags&transactionFlagWitness != 0 && !segwit {
+			return fmt.Errorf("template transaction %v (%v) has witness data but the template has no witness commitment", i, transaction.ID)
+		}
+		if flags&transactionFlagMWEB != 0 && t.MimbleWimble == "" {
+			return fmt.Errorf("template transaction %v (%v) is MWEB serialized but the template has no mweb block", i, transaction.ID)
+		}
+	}
+
+	if t.MimbleWimble != "" && !t.HasHogEx() {
+		return errors.New("template has an mweb block but its last transaction is not the HogEx")
+	}
+
+	return nil
+}
+
+// Litecoin Core only serializes the MWEB extension block after a HogEx transaction
+func (t *Template) HasHogEx() bool {
+	if len(t.Transactions) < 1 {
+		return false
+	}
+	flags, err := transactionFlags(t.Transactions[len(t.Transactions)-1].Data)
+	return err == nil && flags&transactionFlagMWEB != 0
+}
@@ -0,0 +1,139 @@
+package chainevents
+
+import (
+	"sync"
+	"time"
+)
+
+// ZMQ topics a node can publish, hashblock is always subscribed
+const (
+	TopicHashBlock = "hashblock"
+	TopicRawBlock  = "rawblock"
+	TopicHashTx    = "hashtx"
+	TopicRawTx     = "rawtx"
+)
+
+// A decoded block notification, transactions are only counted
+type Event struct {
+	Chain    string    `json:"chain"`
+	Topic    string    `json:"topic"`
+	Sequence uint32    `json:"sequence"`
+	Hash     string    `json:"hash"`
+	Height   uint      `json:"height,omitempty"`
+	Tag      string    `json:"tag,omitempty"`    // Coinbase text of whoever mined it
+	Payout   string    `json:"payout,omitempty"` // Their first paying coinbase script
+	Ours     bool      `json:"ours"`
+	LostTo   string    `json:"lost_to,omitempty"` // Our hash when this block beat ours to the height
+	Created  time.Time `json:"created"`
+}
+
+type TopicStats struct {
+	Messages     uint64    `json:"messages"`
+	Missed       uint64    `json:"missed"` // Gaps in the node's sequence counter
+	LastSequence uint32    `json:"last_sequence"`
+	Last         time.Time `json:"last"`
+}
+
+type ChainStats struct {
+	Topics      map[string]TopicStats `json:"topics"`
+	OurBlocks   uint64                `json:"our_blocks"`
+	OtherBlocks uint64                `json:"other_blocks"`
+	LostRaces   uint64                `json:"lost_races"`
+	Mempool     uint64                `json:"mempool"` // Transactions announced since the last template
+}
+
+// Keep enough history for the API without growing forever
+const historyLength = 100
+
+var (
+	mutex   sync.RWMutex
+	history []Event
+	stats   = make(map[string]*ChainStats)
+)
+
+func chainStats(chain string) *ChainStats {
+	chainStats, exists := stats[chain]
+	if !exists {
+		chainStats = &ChainStats{Topics: make(map[string]TopicStats)}
+		stats[chain] = chainStats
+	}
+	return chainStats
+}
+
+// Counts a message on any topic along with how many before it never arrived.
+// Returns the transactions announced since the chain's last template.
+func Received(chain, topic string, sequence, missed uint32) uint64 {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	chainStats := chainStats(chain)
+	topicStats := chainStats.Topics[topic]
+	topicStats.Messages++
+	topicStats.Missed += uint64(missed)
+	topicStats.LastSequence = sequence
+	topicStats.Last = time.Now()
+	chainStats.Topics[topic] = topicStats
+
+	if topic == TopicHashTx || topic == TopicRawTx {
+		chainStats.Mempool++
+	}
+	return chainStats.Mempool
+}
+
+// A new template covers everything announced so far
+func TemplateRefreshed(chain string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	chainStats(chain).Mempool = 0
+}
+
+func Record(event Event) {
+	if event.Created.IsZero() {
+		event.Created = time.Now()
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	chainStats := chainStats(event.Chain)
+	switch {
+	case event.Ours:
+		chainStats.OurBlocks++
+	case event.LostTo != "":
+		chainStats.LostRaces++
+		chainStats.OtherBlocks++
+	default:
+		chainStats.OtherBlocks++
+	}
+
+	history = append(history, event)
+	if len(history) > historyLength {
+		history = history[len(history)-historyLength:]
+	}
+}
+
+func Recent() []Event {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	recent := make([]Event, len(history))
+	copy(recent, history)
+	return recent
+}
+
+// chain name => its notification counters
+func Stats() map[string]ChainStats {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	snapshot := make(map[string]ChainStats, len(stats))
+	for chain, chainStats := range stats {
+		copied := *chainStats
+		copied.Topics = make(map[string]TopicStats, len(chainStats.Topics))
+		for topic, topicStats := range chainStats.Topics {
+			copied.Topics[topic] = topicStats
+		}
+		snapshot[chain] = copied
+	}
+	return snapshot
+}
@@ -0,0 +1,150 @@
+package chainplugin
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/chainplugin/pluginpb"
+	"designs.capital/dogepool/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Serves a built-in chain over the plugin protocol, a reference for plugin
+// authors and what the conformance check runs against
+type BuiltinServer struct {
+	pluginpb.UnimplementedChainPluginServer
+
+	Chain bitcoin.Blockchain
+	Node  *rpc.RPCClient // Optional, templates and blocks go through the pool's node RPC without it
+	// Asks the pool to hash, decode addresses and build coinbases through
+	// the plugin instead of locally
+	Remote bool
+}
+
+func (s *BuiltinServer) Describe(context.Context, *pluginpb.DescribeRequest) (*pluginpb.Description, error) {
+	auxRPC := s.Chain.AuxRPC()
+	description := &pluginpb.Description{
+		Name:             s.Chain.ChainName(),
+		Algorithm:        s.Chain.Algorithm().Name,
+		ShareMultiplier:  s.Chain.ShareMultiplier(),
+		MinConfirmations: uint32(s.Chain.MinimumConfirmations()),
+		AuxpowChainId:    s.Chain.AuxChainID(),
+		TemplateRules:    s.Chain.BlockTemplateRules(),
+		CoinbaseVersion:  s.Chain.CoinbaseVersion(),
+		AuxRpc:           &pluginpb.AuxRPC{Method: auxRPC.Method, TargetByteOrder: auxRPC.TargetByteOrder},
+		RemoteDigests:    s.Remote,
+		BuildsCoinbase:   s.Remote,
+		HandlesBlocks:    s.Node != nil,
+	}
+	if !s.Remote {
+		networks := s.Chain.AddressNetworks()
+		description.AddressNetworks = &pluginpb.AddressNetworks{
+			Main:    addressFormat(networks.Mainnet),
+			Test:    addressFormat(networks.Testnet),
+			Regtest: addressFormat(networks.Regtest),
+		}
+	}
+	return description, nil
+}
+
+func addressFormat(format bitcoin.AddressFormat) *pluginpb.AddressFormat {
+	return &pluginpb.AddressFormat{
+		PubKeyHash: format.PubKeyHash,
+		ScriptHash: format.ScriptHash,
+		Bech32Hrp:  format.Bech32HRP,
+	}
+}
+
+func (s *BuiltinServer) HeaderDigest(_ context.Context, request *pluginpb.DigestRequest) (*pluginpb.DigestReply, error) {
+	return digestReply(s.Chain.HeaderDigest(hex.EncodeToString(request.Data)))
+}
+
+func (s *BuiltinServer) CoinbaseDigest(_ context.Context, request *pluginpb.DigestRequest) (*pluginpb.DigestReply, error) {
+	return digestReply(s.Chain.CoinbaseDigest(hex.EncodeToString(request.Data)))
+}
+
+func digestReply(digestHex string, err error) (*pluginpb.DigestReply, error) {
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	digest, err := hex.DecodeString(digestHex)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginpb.DigestReply{Digest: digest}, nil
+}
+
+func (s *BuiltinServer) BuildCoinbase(_ context.Context, request *pluginpb.CoinbaseRequest) (*pluginpb.CoinbaseReply, error) {
+	var template bitcoin.Template
+	err := json.Unmarshal(request.Template, &template)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid template: "+err.Error())
+	}
+
+	payees := bitcoin.CoinbasePayees{RewardScript: hex.EncodeToString(request.RewardScript)}
+	for _, recipient := range request.Recipients {
+		payees.Recipients = append(payees.Recipients, bitcoin.CoinbaseRecipient{
+			ScriptPubKey: hex.EncodeToString(recipient.ScriptPubkey),
+			Percentage:   recipient.Percentage,
+		})
+	}
+
+	initial, final, err := bitcoin.BuildCoinbase(&template, s.Chain, string(request.Arbitrary), int(request.ExtranonceLength), payees)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	reply := &pluginpb.CoinbaseReply{}
+	reply.Initial, err = hex.DecodeString(initial)
+	if err != nil {
+		return nil, err
+	}
+	reply.Final, err = hex.DecodeString(final)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (s *BuiltinServer) ScriptPubKey(_ context.Context, request *pluginpb.AddressRequest) (*pluginpb.AddressReply, error) {
+	scriptHex, err := bitcoin.AddressToScriptPubKey(s.Chain, request.Network, request.Address)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	script, err := hex.DecodeString(scriptHex)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginpb.AddressReply{ScriptPubkey: script}, nil
+}
+
+func (s *BuiltinServer) GetBlockTemplate(ctx context.Context, request *pluginpb.TemplateRequest) (*pluginpb.TemplateReply, error) {
+	if s.Node == nil {
+		return nil, status.Error(codes.Unimplemented, "no node configured")
+	}
+	s.Node.TemplateRules = request.Rules
+	template, err := s.Node.GetBlockTemplate(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &pluginpb.TemplateReply{Template: template}, nil
+}
+
+func (s *BuiltinServer) SubmitBlock(ctx context.Context, request *pluginpb.SubmitRequest) (*pluginpb.SubmitReply, error) {
+	if s.Node == nil {
+		return nil, status.Error(codes.Unimplemented, "no node configured")
+	}
+	err := s.Node.SubmitBlock(ctx, hex.EncodeToString(request.Block))
+	var rejection *rpc.SubmitBlockError
+	if errors.As(err, &rejection) {
+		return &pluginpb.SubmitReply{RejectReason: rejection.Reason}, nil
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &pluginpb.SubmitReply{}, nil
+}
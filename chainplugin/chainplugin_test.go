@@ -0,0 +1,98 @@
+package chainplugin
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/chainplugin/pluginpb"
+)
+
+func serve(t *testing.T, plugin pluginpb.ChainPluginServer) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go Serve(listener, plugin)
+	t.Cleanup(func() { listener.Close() })
+	return listener.Addr().String()
+}
+
+func TestBuiltinChainsConform(t *testing.T) {
+	for _, chainName := range []string{"litecoin", "dogecoin", "bitcoin"} {
+		for _, remote := range []bool{false, true} {
+			reference := bitcoin.GetChain(chainName)
+			address := serve(t, &BuiltinServer{Chain: reference, Remote: remote})
+
+			chain, err := Dial(address, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = CheckConformance(chain, reference)
+			if err != nil {
+				t.Errorf("%v (remote %v): %v", chainName, remote, err)
+			}
+			chain.Close()
+		}
+	}
+}
+
+// Reports an algorithm the pool doesn't know
+type customAlgorithm struct {
+	BuiltinServer
+	remoteDigests bool
+}
+
+func (p *customAlgorithm) Describe(ctx context.Context, request *pluginpb.DescribeRequest) (*pluginpb.Description, error) {
+	description, err := p.BuiltinServer.Describe(ctx, request)
+	description.Algorithm = "x11"
+	description.RemoteDigests = p.remoteDigests
+	return description, err
+}
+
+func TestUnknownAlgorithmNeedsRemoteDigests(t *testing.T) {
+	reference := bitcoin.GetChain("litecoin")
+
+	address := serve(t, &customAlgorithm{BuiltinServer: BuiltinServer{Chain: reference}})
+	_, err := Dial(address, 5*time.Second)
+	if err == nil {
+		t.Fatal("dialed a plugin with an unknown algorithm hashed locally")
+	}
+
+	address = serve(t, &customAlgorithm{BuiltinServer: BuiltinServer{Chain: reference}, remoteDigests: true})
+	chain, err := Dial(address, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Close()
+
+	if chain.Algorithm().Name != "x11" {
+		t.Errorf("algorithm %v, want x11", chain.Algorithm().Name)
+	}
+	if chain.ShareMultiplier() != reference.ShareMultiplier() {
+		t.Errorf("share multiplier %v, want the plugin's %v", chain.ShareMultiplier(), reference.ShareMultiplier())
+	}
+	got, err := chain.Algorithm().HeaderDigest(sampleHeader)
+	want, _ := reference.HeaderDigest(sampleHeader)
+	if err != nil || got != want {
+		t.Errorf("header digest %v (%v), want the plugin's %v", got, err, want)
+	}
+}
+
+func TestBlocksWithoutNode(t *testing.T) {
+	chain, err := Dial(serve(t, &BuiltinServer{Chain: bitcoin.GetChain("litecoin")}), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Close()
+
+	if chain.HandlesBlocks() {
+		t.Error("handles blocks without a node")
+	}
+	_, err = chain.GetBlockTemplate(nil)
+	if err == nil {
+		t.Error("template without a node")
+	}
+}
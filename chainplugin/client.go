@@ -0,0 +1,335 @@
+package chainplugin
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/chainplugin/pluginpb"
+	"designs.capital/dogepool/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// A plugin process the pool treats like any built-in bitcoin.Blockchain.
+// Everything the description lets the pool do itself - hashing with a
+// built-in algorithm, decoding declared address formats, laying out a
+// Bitcoin coinbase - stays local, the rest is a call to the plugin.
+type RemoteChain struct {
+	address     string
+	timeout     time.Duration
+	conn        *grpc.ClientConn
+	client      pluginpb.ChainPluginClient
+	description *pluginpb.Description
+
+	local     bitcoin.Blockchain
+	digester  bitcoin.ByteDigester
+	algorithm bitcoin.Algorithm
+}
+
+func Dial(address string, timeout time.Duration) (*RemoteChain, error) {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	chain := &RemoteChain{
+		address: address,
+		timeout: timeout,
+		conn:    conn,
+		client:  pluginpb.NewChainPluginClient(conn),
+	}
+
+	ctx, cancel := chain.context()
+	defer cancel()
+	chain.description, err = chain.client.Describe(ctx, &pluginpb.DescribeRequest{})
+	if err != nil {
+		conn.Close()
+		return nil, errors.Join(errors.New("chain plugin unreachable: "+address), err)
+	}
+
+	err = chain.defineLocally()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return chain, nil
+}
+
+func (c *RemoteChain) Close() error {
+	return c.conn.Close()
+}
+
+func (c *RemoteChain) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+// Plugin errors carry the plugin's message, anything else the transport's
+func (c *RemoteChain) callError(method string, err error) error {
+	m := "chain plugin %v %v: %v"
+	m = fmt.Sprintf(m, c.description.GetName(), method, status.Convert(err).Message())
+	return errors.New(m)
+}
+
+// The pool's own chain built from the description's parameters
+func (c *RemoteChain) defineLocally() error {
+	d := c.description
+	definition := bitcoin.ChainDefinition{
+		Name:               d.Name,
+		Algorithm:          d.Algorithm,
+		CoinbaseVersion:    d.CoinbaseVersion,
+		MinConfirmations:   uint(d.MinConfirmations),
+		ShareMultiplier:    d.ShareMultiplier,
+		AuxChainID:         d.AuxpowChainId,
+		BlockTemplateRules: d.TemplateRules,
+		AuxRPC: bitcoin.AuxRPCFlavour{
+			Method:          d.GetAuxRpc().GetMethod(),
+			TargetByteOrder: d.GetAuxRpc().GetTargetByteOrder(),
+		},
+		Mainnet: formatDefinition(d.GetAddressNetworks().GetMain()),
+		Testnet: formatDefinition(d.GetAddressNetworks().GetTest()),
+		Regtest: formatDefinition(d.GetAddressNetworks().GetRegtest()),
+	}
+	if len(definition.BlockTemplateRules) == 0 {
+		definition.BlockTemplateRules = nil
+	}
+
+	_, known := bitcoin.AlgorithmByName(d.Algorithm)
+	if !known && !d.RemoteDigests {
+		m := "chain plugin %v (%v): unknown algorithm %q, set remote_digests to hash with the plugin"
+		m = fmt.Sprintf(m, d.Name, c.address, d.Algorithm)
+		return errors.New(m)
+	}
+	if !known {
+		definition.Algorithm = bitcoin.Sha256d.Name // Only its parameters are used, the plugin hashes
+	}
+
+	var err error
+	c.local, err = bitcoin.DefineChain(definition)
+	if err != nil {
+		return errors.Join(fmt.Errorf("chain plugin %v (%v)", d.Name, c.address), err)
+	}
+	c.digester, _ = c.local.(bitcoin.ByteDigester)
+
+	c.algorithm = c.local.Algorithm()
+	c.algorithm.ShareMultiplier = c.local.ShareMultiplier()
+	if d.RemoteDigests {
+		c.algorithm.Name = d.Algorithm
+		c.algorithm.HeaderDigest = c.HeaderDigest
+	}
+
+	return nil
+}
+
+func formatDefinition(format *pluginpb.AddressFormat) bitcoin.AddressFormatDefinition {
+	definition := bitcoin.AddressFormatDefinition{Bech32HRP: format.GetBech32Hrp()}
+	for _, version := range format.GetPubKeyHash() {
+		definition.PubKeyHash = append(definition.PubKeyHash, hex.EncodeToString([]byte{version}))
+	}
+	for _, version := range format.GetScriptHash() {
+		definition.ScriptHash = append(definition.ScriptHash, hex.EncodeToString([]byte{version}))
+	}
+	return definition
+}
+
+func (c *RemoteChain) ChainName() string {
+	return c.description.Name
+}
+
+func (c *RemoteChain) Algorithm() bitcoin.Algorithm {
+	return c.algorithm
+}
+
+func (c *RemoteChain) CoinbaseDigest(coinbase string) (string, error) {
+	if !c.description.RemoteDigests {
+		return c.local.CoinbaseDigest(coinbase)
+	}
+	return c.remoteDigest("CoinbaseDigest", c.client.CoinbaseDigest, coinbase)
+}
+
+func (c *RemoteChain) HeaderDigest(header string) (string, error) {
+	if !c.description.RemoteDigests {
+		return c.local.HeaderDigest(header)
+	}
+	return c.remoteDigest("HeaderDigest", c.client.HeaderDigest, header)
+}
+
+func (c *RemoteChain) CoinbaseDigestBytes(coinbase []byte) ([32]byte, error) {
+	if !c.description.RemoteDigests {
+		return c.digester.CoinbaseDigestBytes(coinbase)
+	}
+	return c.remoteDigestBytes("CoinbaseDigest", c.client.CoinbaseDigest, coinbase)
+}
+
+func (c *RemoteChain) HeaderDigestBytes(header []byte) ([32]byte, error) {
+	if !c.description.RemoteDigests {
+		return c.digester.HeaderDigestBytes(header)
+	}
+	return c.remoteDigestBytes("HeaderDigest", c.client.HeaderDigest, header)
+}
+
+type digestCall func(context.Context, *pluginpb.DigestRequest, ...grpc.CallOption) (*pluginpb.DigestReply, error)
+
+func (c *RemoteChain) remoteDigest(method string, call digestCall, dataHex string) (string, error) {
+	data, err := hex.DecodeString(dataHex)
+	if err != nil {
+		return "", err
+	}
+	digest, err := c.remoteDigestBytes(method, call, data)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest[:]), nil
+}
+
+func (c *RemoteChain) remoteDigestBytes(method string, call digestCall, data []byte) ([32]byte, error) {
+	var digest [32]byte
+
+	ctx, cancel := c.context()
+	defer cancel()
+	reply, err := call(ctx, &pluginpb.DigestRequest{Data: data})
+	if err != nil {
+		return digest, c.callError(method, err)
+	}
+	if len(reply.Digest) != len(digest) {
+		m := "chain plugin %v %v: %v byte digest, expected %v"
+		return digest, fmt.Errorf(m, c.description.Name, method, len(reply.Digest), len(digest))
+	}
+	copy(digest[:], reply.Digest)
+	return digest, nil
+}
+
+func (c *RemoteChain) BuildCoinbase(template *bitcoin.Template, arbitrary string, extranonceLength int, payees bitcoin.CoinbasePayees) (string, string, error) {
+	if !c.description.BuildsCoinbase {
+		return bitcoin.BuildCoinbase(template, c, arbitrary, extranonceLength, payees)
+	}
+	return c.remoteCoinbase(template, arbitrary, extranonceLength, payees)
+}
+
+func (c *RemoteChain) remoteCoinbase(template *bitcoin.Template, arbitrary string, extranonceLength int, payees bitcoin.CoinbasePayees) (string, string, error) {
+	request := &pluginpb.CoinbaseRequest{
+		Template:         template.Raw,
+		Arbitrary:        []byte(arbitrary),
+		ExtranonceLength: uint32(extranonceLength),
+	}
+	var err error
+	if request.Template == nil {
+		request.Template, err = json.Marshal(template)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	request.RewardScript, err = hex.DecodeString(payees.RewardScript)
+	if err != nil {
+		return "", "", err
+	}
+	for _, recipient := range payees.Recipients {
+		script, err := hex.DecodeString(recipient.ScriptPubKey)
+		if err != nil {
+			return "", "", err
+		}
+		request.Recipients = append(request.Recipients, &pluginpb.CoinbaseRecipient{
+			ScriptPubkey: script,
+			Percentage:   recipient.Percentage,
+		})
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	reply, err := c.client.BuildCoinbase(ctx, request)
+	if err != nil {
+		return "", "", c.callError("BuildCoinbase", err)
+	}
+	return hex.EncodeToString(reply.Initial), hex.EncodeToString(reply.Final), nil
+}
+
+func (c *RemoteChain) ShareMultiplier() float64 {
+	return c.algorithm.ShareMultiplier
+}
+
+func (c *RemoteChain) MinimumConfirmations() uint {
+	return c.local.MinimumConfirmations()
+}
+
+func (c *RemoteChain) AuxChainID() uint32 {
+	return c.local.AuxChainID()
+}
+
+func (c *RemoteChain) BlockTemplateRules() []string {
+	return c.local.BlockTemplateRules()
+}
+
+func (c *RemoteChain) AuxRPC() bitcoin.AuxRPCFlavour {
+	return c.local.AuxRPC()
+}
+
+func (c *RemoteChain) CoinbaseVersion() uint32 {
+	return c.local.CoinbaseVersion()
+}
+
+// Empty unless the plugin declares its formats, ScriptPubKey asks it then
+func (c *RemoteChain) AddressNetworks() bitcoin.AddressNetworks {
+	return c.local.AddressNetworks()
+}
+
+func (c *RemoteChain) ValidMainnetAddress(address string) bool {
+	_, err := c.ScriptPubKey("main", address)
+	return err == nil
+}
+
+func (c *RemoteChain) ValidTestnetAddress(address string) bool {
+	_, err := c.ScriptPubKey("test", address)
+	return err == nil
+}
+
+func (c *RemoteChain) ScriptPubKey(network, address string) (string, error) {
+	if c.description.AddressNetworks != nil {
+		return bitcoin.AddressToScriptPubKey(c.local, network, address)
+	}
+	return c.remoteScriptPubKey(network, address)
+}
+
+func (c *RemoteChain) remoteScriptPubKey(network, address string) (string, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	reply, err := c.client.ScriptPubKey(ctx, &pluginpb.AddressRequest{Network: network, Address: address})
+	if err != nil {
+		return "", c.callError("ScriptPubKey", err)
+	}
+	return hex.EncodeToString(reply.ScriptPubkey), nil
+}
+
+func (c *RemoteChain) HandlesBlocks() bool {
+	return c.description.HandlesBlocks
+}
+
+func (c *RemoteChain) GetBlockTemplate(rules []string) (json.RawMessage, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	reply, err := c.client.GetBlockTemplate(ctx, &pluginpb.TemplateRequest{Rules: rules})
+	if err != nil {
+		return nil, c.callError("GetBlockTemplate", err)
+	}
+	return reply.Template, nil
+}
+
+func (c *RemoteChain) SubmitBlock(submission string) error {
+	block, err := hex.DecodeString(submission)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	reply, err := c.client.SubmitBlock(ctx, &pluginpb.SubmitRequest{Block: block})
+	if err != nil {
+		return c.callError("SubmitBlock", err)
+	}
+	return rpc.SubmitReasonError("submitblock", reply.RejectReason)
+}
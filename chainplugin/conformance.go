@@ -0,0 +1,125 @@
+package chainplugin
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"designs.capital/dogepool/bitcoin"
+)
+
+// Bitcoin's genesis header, any 80 bytes would do
+const sampleHeader = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+
+// Compares a plugin against the built-in chain it reimplements, one error per
+// mismatch.  Every call the plugin's description enables goes to the plugin,
+// ScriptPubKey always does.
+func CheckConformance(remote *RemoteChain, reference bitcoin.Blockchain) error {
+	var failures []error
+	fail := func(format string, args ...any) {
+		failures = append(failures, fmt.Errorf(format, args...))
+	}
+
+	if remote.ChainName() != reference.ChainName() {
+		fail("chain name: got %v, want %v", remote.ChainName(), reference.ChainName())
+	}
+	if remote.Algorithm().Name != reference.Algorithm().Name {
+		fail("algorithm: got %v, want %v", remote.Algorithm().Name, reference.Algorithm().Name)
+	}
+	if remote.ShareMultiplier() != reference.ShareMultiplier() {
+		fail("share multiplier: got %v, want %v", remote.ShareMultiplier(), reference.ShareMultiplier())
+	}
+	if remote.CoinbaseVersion() != reference.CoinbaseVersion() {
+		fail("coinbase version: got %v, want %v", remote.CoinbaseVersion(), reference.CoinbaseVersion())
+	}
+
+	digests := []struct {
+		name              string
+		remote, reference func(string) (string, error)
+	}{
+		{"header digest", remote.HeaderDigest, reference.HeaderDigest},
+		{"coinbase digest", remote.CoinbaseDigest, reference.CoinbaseDigest},
+	}
+	for _, digest := range digests {
+		got, err := digest.remote(sampleHeader)
+		want, _ := digest.reference(sampleHeader)
+		if err != nil || got != want {
+			fail("%v: got %v (%v), want %v", digest.name, got, err, want)
+		}
+	}
+
+	networks := reference.AddressNetworks()
+	if remote.description.AddressNetworks != nil && !reflect.DeepEqual(remote.AddressNetworks(), networks) {
+		fail("address networks: got %+v, want %+v", remote.AddressNetworks(), networks)
+	}
+	for _, network := range []string{"main", "test", "regtest"} {
+		format, _ := networks.ForNetwork(network)
+		for _, address := range sampleAddresses(format) {
+			want, _ := bitcoin.AddressToScriptPubKey(reference, network, address)
+			got, err := remote.remoteScriptPubKey(network, address)
+			if err != nil || got != want {
+				fail("%v address %v: got %v (%v), want %v", network, address, got, err, want)
+			}
+			got, err = remote.ScriptPubKey(network, address)
+			if err != nil || got != want {
+				fail("%v address %v as the pool decodes it: got %v (%v), want %v", network, address, got, err, want)
+			}
+		}
+	}
+
+	_, err := remote.remoteScriptPubKey("main", "notanaddress")
+	if err == nil {
+		fail("accepted an invalid address")
+	}
+
+	template, payees := sampleCoinbase()
+	wantInitial, wantFinal, _ := bitcoin.BuildCoinbase(template, reference, "/dogepool/", 8, payees)
+	initial, final, err := remote.BuildCoinbase(template, "/dogepool/", 8, payees)
+	if err != nil || initial != wantInitial || final != wantFinal {
+		fail("coinbase: got %v %v (%v), want %v %v", initial, final, err, wantInitial, wantFinal)
+	}
+
+	return errors.Join(failures...)
+}
+
+func sampleAddresses(format bitcoin.AddressFormat) []string {
+	program := make([]byte, 20)
+	for i := range program {
+		program[i] = byte(i + 1)
+	}
+
+	var addresses []string
+	for _, version := range format.PubKeyHash {
+		addresses = append(addresses, bitcoin.Base58CheckEncode(version, program))
+	}
+	for _, version := range format.ScriptHash {
+		addresses = append(addresses, bitcoin.Base58CheckEncode(version, program))
+	}
+	if format.Bech32HRP != "" {
+		address, err := bitcoin.SegwitAddressEncode(format.Bech32HRP, 0, program)
+		if err == nil {
+			addresses = append(addresses, address)
+		}
+	}
+
+	return addresses
+}
+
+// A segwit template paying the pool and a 1% fee recipient
+func sampleCoinbase() (*bitcoin.Template, bitcoin.CoinbasePayees) {
+	template := &bitcoin.Template{
+		Version:                  0x20000000,
+		Height:                   2500000,
+		CoinBaseValue:            625000000,
+		DefaultWitnessCommitment: "6a24aa21a9ede2f61c3f71d1defd3fa999dfa36953755c690689799962b48bebd836974e8cf9",
+		Bits:                     "1d00ffff",
+		CurrentTime:              1700000000,
+	}
+	payees := bitcoin.CoinbasePayees{
+		RewardScript: "76a9140102030405060708090a0b0c0d0e0f101112131488ac",
+		Recipients: []bitcoin.CoinbaseRecipient{
+			{ScriptPubKey: "a914141312111009080706050403020100ffeeddccbbaa87", Percentage: 0.01},
+		},
+	}
+	return template, payees
+}
@@ -0,0 +1,58 @@
+// An example chain plugin serving a built-in chain over the plugin protocol.
+// Run with -check to verify the protocol round trips against the built-in,
+// add -remote to have the pool hash, decode and build coinbases through it.
+//
+//	go run ./chainplugin/example -chain dogecoin -listen 127.0.0.1:7001 -check
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/chainplugin"
+	"designs.capital/dogepool/rpc"
+)
+
+func main() {
+	chainName := flag.String("chain", "dogecoin", "built-in chain to serve")
+	listen := flag.String("listen", "127.0.0.1:7001", "address to serve the plugin on")
+	rpcURL := flag.String("rpc-url", "", "optional node RPC for templates and submissions")
+	rpcUser := flag.String("rpc-user", "", "")
+	rpcPassword := flag.String("rpc-password", "", "")
+	remote := flag.Bool("remote", false, "serve digests, addresses and coinbases instead of describing them")
+	check := flag.Bool("check", false, "run the conformance check against the built-in chain and exit")
+	flag.Parse()
+
+	plugin := &chainplugin.BuiltinServer{Chain: bitcoin.GetChain(*chainName), Remote: *remote}
+	if *rpcURL != "" {
+		plugin.Node = rpc.NewRPCClient(*chainName, *rpcURL, *rpcUser, *rpcPassword, "10s")
+	}
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !*check {
+		log.Printf("Serving %v plugin on %v\n", *chainName, listener.Addr())
+		log.Fatal(chainplugin.Serve(listener, plugin))
+	}
+
+	go chainplugin.Serve(listener, plugin)
+
+	chain, err := chainplugin.Dial(listener.Addr().String(), 5*time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer chain.Close()
+	err = chainplugin.CheckConformance(chain, plugin.Chain)
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	log.Printf("✅ %v plugin conforms\n", *chainName)
+}
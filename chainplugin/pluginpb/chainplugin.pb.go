@@ -0,0 +1,1068 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: chainplugin.proto
+
+package pluginpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DescribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeRequest) Reset() {
+	*x = DescribeRequest{}
+	mi := &file_chainplugin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeRequest) ProtoMessage() {}
+
+func (x *DescribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeRequest.ProtoReflect.Descriptor instead.
+func (*DescribeRequest) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{0}
+}
+
+type AddressFormat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PubKeyHash    []byte                 `protobuf:"bytes,1,opt,name=pub_key_hash,json=pubKeyHash,proto3" json:"pub_key_hash,omitempty"` // P2PKH version bytes
+	ScriptHash    []byte                 `protobuf:"bytes,2,opt,name=script_hash,json=scriptHash,proto3" json:"script_hash,omitempty"`   // P2SH version bytes
+	Bech32Hrp     string                 `protobuf:"bytes,3,opt,name=bech32_hrp,json=bech32Hrp,proto3" json:"bech32_hrp,omitempty"`      // Empty without segwit addresses
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddressFormat) Reset() {
+	*x = AddressFormat{}
+	mi := &file_chainplugin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddressFormat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddressFormat) ProtoMessage() {}
+
+func (x *AddressFormat) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddressFormat.ProtoReflect.Descriptor instead.
+func (*AddressFormat) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AddressFormat) GetPubKeyHash() []byte {
+	if x != nil {
+		return x.PubKeyHash
+	}
+	return nil
+}
+
+func (x *AddressFormat) GetScriptHash() []byte {
+	if x != nil {
+		return x.ScriptHash
+	}
+	return nil
+}
+
+func (x *AddressFormat) GetBech32Hrp() string {
+	if x != nil {
+		return x.Bech32Hrp
+	}
+	return ""
+}
+
+// Lets the pool decode Base58Check and Bech32 addresses itself, leave it
+// unset when only ScriptPubKey understands the chain's addresses
+type AddressNetworks struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Main          *AddressFormat         `protobuf:"bytes,1,opt,name=main,proto3" json:"main,omitempty"`
+	Test          *AddressFormat         `protobuf:"bytes,2,opt,name=test,proto3" json:"test,omitempty"`
+	Regtest       *AddressFormat         `protobuf:"bytes,3,opt,name=regtest,proto3" json:"regtest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddressNetworks) Reset() {
+	*x = AddressNetworks{}
+	mi := &file_chainplugin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddressNetworks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddressNetworks) ProtoMessage() {}
+
+func (x *AddressNetworks) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddressNetworks.ProtoReflect.Descriptor instead.
+func (*AddressNetworks) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AddressNetworks) GetMain() *AddressFormat {
+	if x != nil {
+		return x.Main
+	}
+	return nil
+}
+
+func (x *AddressNetworks) GetTest() *AddressFormat {
+	if x != nil {
+		return x.Test
+	}
+	return nil
+}
+
+func (x *AddressNetworks) GetRegtest() *AddressFormat {
+	if x != nil {
+		return x.Regtest
+	}
+	return nil
+}
+
+type AuxRPC struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Method          string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`                                            // "createauxblock" or "getauxblock"
+	TargetByteOrder string                 `protobuf:"bytes,2,opt,name=target_byte_order,json=targetByteOrder,proto3" json:"target_byte_order,omitempty"` // "little" or "big"
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AuxRPC) Reset() {
+	*x = AuxRPC{}
+	mi := &file_chainplugin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuxRPC) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuxRPC) ProtoMessage() {}
+
+func (x *AuxRPC) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuxRPC.ProtoReflect.Descriptor instead.
+func (*AuxRPC) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AuxRPC) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *AuxRPC) GetTargetByteOrder() string {
+	if x != nil {
+		return x.TargetByteOrder
+	}
+	return ""
+}
+
+type Description struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// A built-in algorithm the pool hashes locally, or any name when
+	// remote_digests is set
+	Algorithm        string           `protobuf:"bytes,2,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	ShareMultiplier  float64          `protobuf:"fixed64,3,opt,name=share_multiplier,json=shareMultiplier,proto3" json:"share_multiplier,omitempty"` // The algorithm's when zero
+	MinConfirmations uint32           `protobuf:"varint,4,opt,name=min_confirmations,json=minConfirmations,proto3" json:"min_confirmations,omitempty"`
+	AuxpowChainId    uint32           `protobuf:"varint,5,opt,name=auxpow_chain_id,json=auxpowChainId,proto3" json:"auxpow_chain_id,omitempty"`
+	TemplateRules    []string         `protobuf:"bytes,6,rep,name=template_rules,json=templateRules,proto3" json:"template_rules,omitempty"`
+	CoinbaseVersion  uint32           `protobuf:"varint,7,opt,name=coinbase_version,json=coinbaseVersion,proto3" json:"coinbase_version,omitempty"`
+	AuxRpc           *AuxRPC          `protobuf:"bytes,8,opt,name=aux_rpc,json=auxRpc,proto3" json:"aux_rpc,omitempty"`
+	AddressNetworks  *AddressNetworks `protobuf:"bytes,9,opt,name=address_networks,json=addressNetworks,proto3" json:"address_networks,omitempty"`
+	RemoteDigests    bool             `protobuf:"varint,10,opt,name=remote_digests,json=remoteDigests,proto3" json:"remote_digests,omitempty"`    // Every share is hashed by the plugin, one call each
+	BuildsCoinbase   bool             `protobuf:"varint,11,opt,name=builds_coinbase,json=buildsCoinbase,proto3" json:"builds_coinbase,omitempty"` // The plugin lays out the coinbase for each template
+	HandlesBlocks    bool             `protobuf:"varint,12,opt,name=handles_blocks,json=handlesBlocks,proto3" json:"handles_blocks,omitempty"`    // Templates and blocks skip the node RPC
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Description) Reset() {
+	*x = Description{}
+	mi := &file_chainplugin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Description) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Description) ProtoMessage() {}
+
+func (x *Description) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Description.ProtoReflect.Descriptor instead.
+func (*Description) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Description) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Description) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *Description) GetShareMultiplier() float64 {
+	if x != nil {
+		return x.ShareMultiplier
+	}
+	return 0
+}
+
+func (x *Description) GetMinConfirmations() uint32 {
+	if x != nil {
+		return x.MinConfirmations
+	}
+	return 0
+}
+
+func (x *Description) GetAuxpowChainId() uint32 {
+	if x != nil {
+		return x.AuxpowChainId
+	}
+	return 0
+}
+
+func (x *Description) GetTemplateRules() []string {
+	if x != nil {
+		return x.TemplateRules
+	}
+	return nil
+}
+
+func (x *Description) GetCoinbaseVersion() uint32 {
+	if x != nil {
+		return x.CoinbaseVersion
+	}
+	return 0
+}
+
+func (x *Description) GetAuxRpc() *AuxRPC {
+	if x != nil {
+		return x.AuxRpc
+	}
+	return nil
+}
+
+func (x *Description) GetAddressNetworks() *AddressNetworks {
+	if x != nil {
+		return x.AddressNetworks
+	}
+	return nil
+}
+
+func (x *Description) GetRemoteDigests() bool {
+	if x != nil {
+		return x.RemoteDigests
+	}
+	return false
+}
+
+func (x *Description) GetBuildsCoinbase() bool {
+	if x != nil {
+		return x.BuildsCoinbase
+	}
+	return false
+}
+
+func (x *Description) GetHandlesBlocks() bool {
+	if x != nil {
+		return x.HandlesBlocks
+	}
+	return false
+}
+
+type DigestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DigestRequest) Reset() {
+	*x = DigestRequest{}
+	mi := &file_chainplugin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DigestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DigestRequest) ProtoMessage() {}
+
+func (x *DigestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DigestRequest.ProtoReflect.Descriptor instead.
+func (*DigestRequest) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DigestRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type DigestReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Digest        []byte                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"` // In the digest's natural byte order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DigestReply) Reset() {
+	*x = DigestReply{}
+	mi := &file_chainplugin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DigestReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DigestReply) ProtoMessage() {}
+
+func (x *DigestReply) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DigestReply.ProtoReflect.Descriptor instead.
+func (*DigestReply) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DigestReply) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+type CoinbaseRecipient struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScriptPubkey  []byte                 `protobuf:"bytes,1,opt,name=script_pubkey,json=scriptPubkey,proto3" json:"script_pubkey,omitempty"`
+	Percentage    float64                `protobuf:"fixed64,2,opt,name=percentage,proto3" json:"percentage,omitempty"` // 0.01 is 1% of the coinbase value
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CoinbaseRecipient) Reset() {
+	*x = CoinbaseRecipient{}
+	mi := &file_chainplugin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CoinbaseRecipient) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoinbaseRecipient) ProtoMessage() {}
+
+func (x *CoinbaseRecipient) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoinbaseRecipient.ProtoReflect.Descriptor instead.
+func (*CoinbaseRecipient) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CoinbaseRecipient) GetScriptPubkey() []byte {
+	if x != nil {
+		return x.ScriptPubkey
+	}
+	return nil
+}
+
+func (x *CoinbaseRecipient) GetPercentage() float64 {
+	if x != nil {
+		return x.Percentage
+	}
+	return 0
+}
+
+type CoinbaseRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Template []byte                 `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"` // getblocktemplate's result, JSON
+	// Written after the extranonce: the pool's signature and any merged
+	// mining commitment
+	Arbitrary        []byte               `protobuf:"bytes,2,opt,name=arbitrary,proto3" json:"arbitrary,omitempty"`
+	ExtranonceLength uint32               `protobuf:"varint,3,opt,name=extranonce_length,json=extranonceLength,proto3" json:"extranonce_length,omitempty"`
+	RewardScript     []byte               `protobuf:"bytes,4,opt,name=reward_script,json=rewardScript,proto3" json:"reward_script,omitempty"` // Receives what the recipients leave
+	Recipients       []*CoinbaseRecipient `protobuf:"bytes,5,rep,name=recipients,proto3" json:"recipients,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CoinbaseRequest) Reset() {
+	*x = CoinbaseRequest{}
+	mi := &file_chainplugin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CoinbaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoinbaseRequest) ProtoMessage() {}
+
+func (x *CoinbaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoinbaseRequest.ProtoReflect.Descriptor instead.
+func (*CoinbaseRequest) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CoinbaseRequest) GetTemplate() []byte {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+func (x *CoinbaseRequest) GetArbitrary() []byte {
+	if x != nil {
+		return x.Arbitrary
+	}
+	return nil
+}
+
+func (x *CoinbaseRequest) GetExtranonceLength() uint32 {
+	if x != nil {
+		return x.ExtranonceLength
+	}
+	return 0
+}
+
+func (x *CoinbaseRequest) GetRewardScript() []byte {
+	if x != nil {
+		return x.RewardScript
+	}
+	return nil
+}
+
+func (x *CoinbaseRequest) GetRecipients() []*CoinbaseRecipient {
+	if x != nil {
+		return x.Recipients
+	}
+	return nil
+}
+
+// The legacy serialized coinbase either side of the extranonce
+type CoinbaseReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Initial       []byte                 `protobuf:"bytes,1,opt,name=initial,proto3" json:"initial,omitempty"`
+	Final         []byte                 `protobuf:"bytes,2,opt,name=final,proto3" json:"final,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CoinbaseReply) Reset() {
+	*x = CoinbaseReply{}
+	mi := &file_chainplugin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CoinbaseReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoinbaseReply) ProtoMessage() {}
+
+func (x *CoinbaseReply) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoinbaseReply.ProtoReflect.Descriptor instead.
+func (*CoinbaseReply) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CoinbaseReply) GetInitial() []byte {
+	if x != nil {
+		return x.Initial
+	}
+	return nil
+}
+
+func (x *CoinbaseReply) GetFinal() []byte {
+	if x != nil {
+		return x.Final
+	}
+	return nil
+}
+
+type AddressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Network       string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"` // getblockchaininfo's "chain"
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddressRequest) Reset() {
+	*x = AddressRequest{}
+	mi := &file_chainplugin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddressRequest) ProtoMessage() {}
+
+func (x *AddressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddressRequest.ProtoReflect.Descriptor instead.
+func (*AddressRequest) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AddressRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *AddressRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+type AddressReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScriptPubkey  []byte                 `protobuf:"bytes,1,opt,name=script_pubkey,json=scriptPubkey,proto3" json:"script_pubkey,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddressReply) Reset() {
+	*x = AddressReply{}
+	mi := &file_chainplugin_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddressReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddressReply) ProtoMessage() {}
+
+func (x *AddressReply) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddressReply.ProtoReflect.Descriptor instead.
+func (*AddressReply) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AddressReply) GetScriptPubkey() []byte {
+	if x != nil {
+		return x.ScriptPubkey
+	}
+	return nil
+}
+
+type TemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []string               `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TemplateRequest) Reset() {
+	*x = TemplateRequest{}
+	mi := &file_chainplugin_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TemplateRequest) ProtoMessage() {}
+
+func (x *TemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TemplateRequest.ProtoReflect.Descriptor instead.
+func (*TemplateRequest) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TemplateRequest) GetRules() []string {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type TemplateReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Template      []byte                 `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"` // getblocktemplate's result, JSON
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TemplateReply) Reset() {
+	*x = TemplateReply{}
+	mi := &file_chainplugin_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TemplateReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TemplateReply) ProtoMessage() {}
+
+func (x *TemplateReply) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TemplateReply.ProtoReflect.Descriptor instead.
+func (*TemplateReply) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *TemplateReply) GetTemplate() []byte {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+type SubmitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Block         []byte                 `protobuf:"bytes,1,opt,name=block,proto3" json:"block,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitRequest) Reset() {
+	*x = SubmitRequest{}
+	mi := &file_chainplugin_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitRequest) ProtoMessage() {}
+
+func (x *SubmitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitRequest.ProtoReflect.Descriptor instead.
+func (*SubmitRequest) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SubmitRequest) GetBlock() []byte {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+type SubmitReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RejectReason  string                 `protobuf:"bytes,1,opt,name=reject_reason,json=rejectReason,proto3" json:"reject_reason,omitempty"` // BIP22 reason, empty when accepted
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitReply) Reset() {
+	*x = SubmitReply{}
+	mi := &file_chainplugin_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitReply) ProtoMessage() {}
+
+func (x *SubmitReply) ProtoReflect() protoreflect.Message {
+	mi := &file_chainplugin_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitReply.ProtoReflect.Descriptor instead.
+func (*SubmitReply) Descriptor() ([]byte, []int) {
+	return file_chainplugin_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SubmitReply) GetRejectReason() string {
+	if x != nil {
+		return x.RejectReason
+	}
+	return ""
+}
+
+var File_chainplugin_proto protoreflect.FileDescriptor
+
+const file_chainplugin_proto_rawDesc = "" +
+	"\n" +
+	"\x11chainplugin.proto\x12\x17dogepool.chainplugin.v1\"\x11\n" +
+	"\x0fDescribeRequest\"q\n" +
+	"\rAddressFormat\x12 \n" +
+	"\fpub_key_hash\x18\x01 \x01(\fR\n" +
+	"pubKeyHash\x12\x1f\n" +
+	"\vscript_hash\x18\x02 \x01(\fR\n" +
+	"scriptHash\x12\x1d\n" +
+	"\n" +
+	"bech32_hrp\x18\x03 \x01(\tR\tbech32Hrp\"\xcb\x01\n" +
+	"\x0fAddressNetworks\x12:\n" +
+	"\x04main\x18\x01 \x01(\v2&.dogepool.chainplugin.v1.AddressFormatR\x04main\x12:\n" +
+	"\x04test\x18\x02 \x01(\v2&.dogepool.chainplugin.v1.AddressFormatR\x04test\x12@\n" +
+	"\aregtest\x18\x03 \x01(\v2&.dogepool.chainplugin.v1.AddressFormatR\aregtest\"L\n" +
+	"\x06AuxRPC\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12*\n" +
+	"\x11target_byte_order\x18\x02 \x01(\tR\x0ftargetByteOrder\"\x97\x04\n" +
+	"\vDescription\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\talgorithm\x18\x02 \x01(\tR\talgorithm\x12)\n" +
+	"\x10share_multiplier\x18\x03 \x01(\x01R\x0fshareMultiplier\x12+\n" +
+	"\x11min_confirmations\x18\x04 \x01(\rR\x10minConfirmations\x12&\n" +
+	"\x0fauxpow_chain_id\x18\x05 \x01(\rR\rauxpowChainId\x12%\n" +
+	"\x0etemplate_rules\x18\x06 \x03(\tR\rtemplateRules\x12)\n" +
+	"\x10coinbase_version\x18\a \x01(\rR\x0fcoinbaseVersion\x128\n" +
+	"\aaux_rpc\x18\b \x01(\v2\x1f.dogepool.chainplugin.v1.AuxRPCR\x06auxRpc\x12S\n" +
+	"\x10address_networks\x18\t \x01(\v2(.dogepool.chainplugin.v1.AddressNetworksR\x0faddressNetworks\x12%\n" +
+	"\x0eremote_digests\x18\n" +
+	" \x01(\bR\rremoteDigests\x12'\n" +
+	"\x0fbuilds_coinbase\x18\v \x01(\bR\x0ebuildsCoinbase\x12%\n" +
+	"\x0ehandles_blocks\x18\f \x01(\bR\rhandlesBlocks\"#\n" +
+	"\rDigestRequest\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"%\n" +
+	"\vDigestReply\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\fR\x06digest\"X\n" +
+	"\x11CoinbaseRecipient\x12#\n" +
+	"\rscript_pubkey\x18\x01 \x01(\fR\fscriptPubkey\x12\x1e\n" +
+	"\n" +
+	"percentage\x18\x02 \x01(\x01R\n" +
+	"percentage\"\xe9\x01\n" +
+	"\x0fCoinbaseRequest\x12\x1a\n" +
+	"\btemplate\x18\x01 \x01(\fR\btemplate\x12\x1c\n" +
+	"\tarbitrary\x18\x02 \x01(\fR\tarbitrary\x12+\n" +
+	"\x11extranonce_length\x18\x03 \x01(\rR\x10extranonceLength\x12#\n" +
+	"\rreward_script\x18\x04 \x01(\fR\frewardScript\x12J\n" +
+	"\n" +
+	"recipients\x18\x05 \x03(\v2*.dogepool.chainplugin.v1.CoinbaseRecipientR\n" +
+	"recipients\"?\n" +
+	"\rCoinbaseReply\x12\x18\n" +
+	"\ainitial\x18\x01 \x01(\fR\ainitial\x12\x14\n" +
+	"\x05final\x18\x02 \x01(\fR\x05final\"D\n" +
+	"\x0eAddressRequest\x12\x18\n" +
+	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\"3\n" +
+	"\fAddressReply\x12#\n" +
+	"\rscript_pubkey\x18\x01 \x01(\fR\fscriptPubkey\"'\n" +
+	"\x0fTemplateRequest\x12\x14\n" +
+	"\x05rules\x18\x01 \x03(\tR\x05rules\"+\n" +
+	"\rTemplateReply\x12\x1a\n" +
+	"\btemplate\x18\x01 \x01(\fR\btemplate\"%\n" +
+	"\rSubmitRequest\x12\x14\n" +
+	"\x05block\x18\x01 \x01(\fR\x05block\"2\n" +
+	"\vSubmitReply\x12#\n" +
+	"\rreject_reason\x18\x01 \x01(\tR\frejectReason2\xad\x05\n" +
+	"\vChainPlugin\x12Z\n" +
+	"\bDescribe\x12(.dogepool.chainplugin.v1.DescribeRequest\x1a$.dogepool.chainplugin.v1.Description\x12\\\n" +
+	"\fHeaderDigest\x12&.dogepool.chainplugin.v1.DigestRequest\x1a$.dogepool.chainplugin.v1.DigestReply\x12^\n" +
+	"\x0eCoinbaseDigest\x12&.dogepool.chainplugin.v1.DigestRequest\x1a$.dogepool.chainplugin.v1.DigestReply\x12a\n" +
+	"\rBuildCoinbase\x12(.dogepool.chainplugin.v1.CoinbaseRequest\x1a&.dogepool.chainplugin.v1.CoinbaseReply\x12^\n" +
+	"\fScriptPubKey\x12'.dogepool.chainplugin.v1.AddressRequest\x1a%.dogepool.chainplugin.v1.AddressReply\x12d\n" +
+	"\x10GetBlockTemplate\x12(.dogepool.chainplugin.v1.TemplateRequest\x1a&.dogepool.chainplugin.v1.TemplateReply\x12[\n" +
+	"\vSubmitBlock\x12&.dogepool.chainplugin.v1.SubmitRequest\x1a$.dogepool.chainplugin.v1.SubmitReplyB/Z-designs.capital/dogepool/chainplugin/pluginpbb\x06proto3"
+
+var (
+	file_chainplugin_proto_rawDescOnce sync.Once
+	file_chainplugin_proto_rawDescData []byte
+)
+
+func file_chainplugin_proto_rawDescGZIP() []byte {
+	file_chainplugin_proto_rawDescOnce.Do(func() {
+		file_chainplugin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_chainplugin_proto_rawDesc), len(file_chainplugin_proto_rawDesc)))
+	})
+	return file_chainplugin_proto_rawDescData
+}
+
+var file_chainplugin_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_chainplugin_proto_goTypes = []any{
+	(*DescribeRequest)(nil),   // 0: dogepool.chainplugin.v1.DescribeRequest
+	(*AddressFormat)(nil),     // 1: dogepool.chainplugin.v1.AddressFormat
+	(*AddressNetworks)(nil),   // 2: dogepool.chainplugin.v1.AddressNetworks
+	(*AuxRPC)(nil),            // 3: dogepool.chainplugin.v1.AuxRPC
+	(*Description)(nil),       // 4: dogepool.chainplugin.v1.Description
+	(*DigestRequest)(nil),     // 5: dogepool.chainplugin.v1.DigestRequest
+	(*DigestReply)(nil),       // 6: dogepool.chainplugin.v1.DigestReply
+	(*CoinbaseRecipient)(nil), // 7: dogepool.chainplugin.v1.CoinbaseRecipient
+	(*CoinbaseRequest)(nil),   // 8: dogepool.chainplugin.v1.CoinbaseRequest
+	(*CoinbaseReply)(nil),     // 9: dogepool.chainplugin.v1.CoinbaseReply
+	(*AddressRequest)(nil),    // 10: dogepool.chainplugin.v1.AddressRequest
+	(*AddressReply)(nil),      // 11: dogepool.chainplugin.v1.AddressReply
+	(*TemplateRequest)(nil),   // 12: dogepool.chainplugin.v1.TemplateRequest
+	(*TemplateReply)(nil),     // 13: dogepool.chainplugin.v1.TemplateReply
+	(*SubmitRequest)(nil),     // 14: dogepool.chainplugin.v1.SubmitRequest
+	(*SubmitReply)(nil),       // 15: dogepool.chainplugin.v1.SubmitReply
+}
+var file_chainplugin_proto_depIdxs = []int32{
+	1,  // 0: dogepool.chainplugin.v1.AddressNetworks.main:type_name -> dogepool.chainplugin.v1.AddressFormat
+	1,  // 1: dogepool.chainplugin.v1.AddressNetworks.test:type_name -> dogepool.chainplugin.v1.AddressFormat
+	1,  // 2: dogepool.chainplugin.v1.AddressNetworks.regtest:type_name -> dogepool.chainplugin.v1.AddressFormat
+	3,  // 3: dogepool.chainplugin.v1.Description.aux_rpc:type_name -> dogepool.chainplugin.v1.AuxRPC
+	2,  // 4: dogepool.chainplugin.v1.Description.address_networks:type_name -> dogepool.chainplugin.v1.AddressNetworks
+	7,  // 5: dogepool.chainplugin.v1.CoinbaseRequest.recipients:type_name -> dogepool.chainplugin.v1.CoinbaseRecipient
+	0,  // 6: dogepool.chainplugin.v1.ChainPlugin.Describe:input_type -> dogepool.chainplugin.v1.DescribeRequest
+	5,  // 7: dogepool.chainplugin.v1.ChainPlugin.HeaderDigest:input_type -> dogepool.chainplugin.v1.DigestRequest
+	5,  // 8: dogepool.chainplugin.v1.ChainPlugin.CoinbaseDigest:input_type -> dogepool.chainplugin.v1.DigestRequest
+	8,  // 9: dogepool.chainplugin.v1.ChainPlugin.BuildCoinbase:input_type -> dogepool.chainplugin.v1.CoinbaseRequest
+	10, // 10: dogepool.chainplugin.v1.ChainPlugin.ScriptPubKey:input_type -> dogepool.chainplugin.v1.AddressRequest
+	12, // 11: dogepool.chainplugin.v1.ChainPlugin.GetBlockTemplate:input_type -> dogepool.chainplugin.v1.TemplateRequest
+	14, // 12: dogepool.chainplugin.v1.ChainPlugin.SubmitBlock:input_type -> dogepool.chainplugin.v1.SubmitRequest
+	4,  // 13: dogepool.chainplugin.v1.ChainPlugin.Describe:output_type -> dogepool.chainplugin.v1.Description
+	6,  // 14: dogepool.chainplugin.v1.ChainPlugin.HeaderDigest:output_type -> dogepool.chainplugin.v1.DigestReply
+	6,  // 15: dogepool.chainplugin.v1.ChainPlugin.CoinbaseDigest:output_type -> dogepool.chainplugin.v1.DigestReply
+	9,  // 16: dogepool.chainplugin.v1.ChainPlugin.BuildCoinbase:output_type -> dogepool.chainplugin.v1.CoinbaseReply
+	11, // 17: dogepool.chainplugin.v1.ChainPlugin.ScriptPubKey:output_type -> dogepool.chainplugin.v1.AddressReply
+	13, // 18: dogepool.chainplugin.v1.ChainPlugin.GetBlockTemplate:output_type -> dogepool.chainplugin.v1.TemplateReply
+	15, // 19: dogepool.chainplugin.v1.ChainPlugin.SubmitBlock:output_type -> dogepool.chainplugin.v1.SubmitReply
+	13, // [13:20] is the sub-list for method output_type
+	6,  // [6:13] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_chainplugin_proto_init() }
+func file_chainplugin_proto_init() {
+	if File_chainplugin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_chainplugin_proto_rawDesc), len(file_chainplugin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_chainplugin_proto_goTypes,
+		DependencyIndexes: file_chainplugin_proto_depIdxs,
+		MessageInfos:      file_chainplugin_proto_msgTypes,
+	}.Build()
+	File_chainplugin_proto = out.File
+	file_chainplugin_proto_goTypes = nil
+	file_chainplugin_proto_depIdxs = nil
+}
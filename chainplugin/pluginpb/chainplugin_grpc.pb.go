@@ -0,0 +1,365 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: chainplugin.proto
+
+package pluginpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ChainPlugin_Describe_FullMethodName         = "/dogepool.chainplugin.v1.ChainPlugin/Describe"
+	ChainPlugin_HeaderDigest_FullMethodName     = "/dogepool.chainplugin.v1.ChainPlugin/HeaderDigest"
+	ChainPlugin_CoinbaseDigest_FullMethodName   = "/dogepool.chainplugin.v1.ChainPlugin/CoinbaseDigest"
+	ChainPlugin_BuildCoinbase_FullMethodName    = "/dogepool.chainplugin.v1.ChainPlugin/BuildCoinbase"
+	ChainPlugin_ScriptPubKey_FullMethodName     = "/dogepool.chainplugin.v1.ChainPlugin/ScriptPubKey"
+	ChainPlugin_GetBlockTemplate_FullMethodName = "/dogepool.chainplugin.v1.ChainPlugin/GetBlockTemplate"
+	ChainPlugin_SubmitBlock_FullMethodName      = "/dogepool.chainplugin.v1.ChainPlugin/SubmitBlock"
+)
+
+// ChainPluginClient is the client API for ChainPlugin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Chains with unusual rules can run in their own process, in any language
+// with a gRPC implementation.  Regenerate the Go code in pluginpb with
+// `buf generate` from this directory.
+type ChainPluginClient interface {
+	Describe(ctx context.Context, in *DescribeRequest, opts ...grpc.CallOption) (*Description, error)
+	// Only called when the description sets remote_digests
+	HeaderDigest(ctx context.Context, in *DigestRequest, opts ...grpc.CallOption) (*DigestReply, error)
+	CoinbaseDigest(ctx context.Context, in *DigestRequest, opts ...grpc.CallOption) (*DigestReply, error)
+	// Only called when the description sets builds_coinbase
+	BuildCoinbase(ctx context.Context, in *CoinbaseRequest, opts ...grpc.CallOption) (*CoinbaseReply, error)
+	// Invalid addresses are answered with INVALID_ARGUMENT
+	ScriptPubKey(ctx context.Context, in *AddressRequest, opts ...grpc.CallOption) (*AddressReply, error)
+	// Only called when the description sets handles_blocks
+	GetBlockTemplate(ctx context.Context, in *TemplateRequest, opts ...grpc.CallOption) (*TemplateReply, error)
+	SubmitBlock(ctx context.Context, in *SubmitRequest, opts ...grpc.CallOption) (*SubmitReply, error)
+}
+
+type chainPluginClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChainPluginClient(cc grpc.ClientConnInterface) ChainPluginClient {
+	return &chainPluginClient{cc}
+}
+
+func (c *chainPluginClient) Describe(ctx context.Context, in *DescribeRequest, opts ...grpc.CallOption) (*Description, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Description)
+	err := c.cc.Invoke(ctx, ChainPlugin_Describe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainPluginClient) HeaderDigest(ctx context.Context, in *DigestRequest, opts ...grpc.CallOption) (*DigestReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DigestReply)
+	err := c.cc.Invoke(ctx, ChainPlugin_HeaderDigest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainPluginClient) CoinbaseDigest(ctx context.Context, in *DigestRequest, opts ...grpc.CallOption) (*DigestReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DigestReply)
+	err := c.cc.Invoke(ctx, ChainPlugin_CoinbaseDigest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainPluginClient) BuildCoinbase(ctx context.Context, in *CoinbaseRequest, opts ...grpc.CallOption) (*CoinbaseReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CoinbaseReply)
+	err := c.cc.Invoke(ctx, ChainPlugin_BuildCoinbase_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainPluginClient) ScriptPubKey(ctx context.Context, in *AddressRequest, opts ...grpc.CallOption) (*AddressReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddressReply)
+	err := c.cc.Invoke(ctx, ChainPlugin_ScriptPubKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainPluginClient) GetBlockTemplate(ctx context.Context, in *TemplateRequest, opts ...grpc.CallOption) (*TemplateReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TemplateReply)
+	err := c.cc.Invoke(ctx, ChainPlugin_GetBlockTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainPluginClient) SubmitBlock(ctx context.Context, in *SubmitRequest, opts ...grpc.CallOption) (*SubmitReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitReply)
+	err := c.cc.Invoke(ctx, ChainPlugin_SubmitBlock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChainPluginServer is the server API for ChainPlugin service.
+// All implementations must embed UnimplementedChainPluginServer
+// for forward compatibility.
+//
+// Chains with unusual rules can run in their own process, in any language
+// with a gRPC implementation.  Regenerate the Go code in pluginpb with
+// `buf generate` from this directory.
+type ChainPluginServer interface {
+	Describe(context.Context, *DescribeRequest) (*Description, error)
+	// Only called when the description sets remote_digests
+	HeaderDigest(context.Context, *DigestRequest) (*DigestReply, error)
+	CoinbaseDigest(context.Context, *DigestRequest) (*DigestReply, error)
+	// Only called when the description sets builds_coinbase
+	BuildCoinbase(context.Context, *CoinbaseRequest) (*CoinbaseReply, error)
+	// Invalid addresses are answered with INVALID_ARGUMENT
+	ScriptPubKey(context.Context, *AddressRequest) (*AddressReply, error)
+	// Only called when the description sets handles_blocks
+	GetBlockTemplate(context.Context, *TemplateRequest) (*TemplateReply, error)
+	SubmitBlock(context.Context, *SubmitRequest) (*SubmitReply, error)
+	mustEmbedUnimplementedChainPluginServer()
+}
+
+// UnimplementedChainPluginServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedChainPluginServer struct{}
+
+func (UnimplementedChainPluginServer) Describe(context.Context, *DescribeRequest) (*Description, error) {
+	return nil, status.Error(codes.Unimplemented, "method Describe not implemented")
+}
+func (UnimplementedChainPluginServer) HeaderDigest(context.Context, *DigestRequest) (*DigestReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method HeaderDigest not implemented")
+}
+func (UnimplementedChainPluginServer) CoinbaseDigest(context.Context, *DigestRequest) (*DigestReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method CoinbaseDigest not implemented")
+}
+func (UnimplementedChainPluginServer) BuildCoinbase(context.Context, *CoinbaseRequest) (*CoinbaseReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method BuildCoinbase not implemented")
+}
+func (UnimplementedChainPluginServer) ScriptPubKey(context.Context, *AddressRequest) (*AddressReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method ScriptPubKey not implemented")
+}
+func (UnimplementedChainPluginServer) GetBlockTemplate(context.Context, *TemplateRequest) (*TemplateReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetBlockTemplate not implemented")
+}
+func (UnimplementedChainPluginServer) SubmitBlock(context.Context, *SubmitRequest) (*SubmitReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method SubmitBlock not implemented")
+}
+func (UnimplementedChainPluginServer) mustEmbedUnimplementedChainPluginServer() {}
+func (UnimplementedChainPluginServer) testEmbeddedByValue()                     {}
+
+// UnsafeChainPluginServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChainPluginServer will
+// result in compilation errors.
+type UnsafeChainPluginServer interface {
+	mustEmbedUnimplementedChainPluginServer()
+}
+
+func RegisterChainPluginServer(s grpc.ServiceRegistrar, srv ChainPluginServer) {
+	// If the following call panics, it indicates UnimplementedChainPluginServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ChainPlugin_ServiceDesc, srv)
+}
+
+func _ChainPlugin_Describe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainPluginServer).Describe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChainPlugin_Describe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainPluginServer).Describe(ctx, req.(*DescribeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChainPlugin_HeaderDigest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DigestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainPluginServer).HeaderDigest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChainPlugin_HeaderDigest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainPluginServer).HeaderDigest(ctx, req.(*DigestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChainPlugin_CoinbaseDigest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DigestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainPluginServer).CoinbaseDigest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChainPlugin_CoinbaseDigest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainPluginServer).CoinbaseDigest(ctx, req.(*DigestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChainPlugin_BuildCoinbase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CoinbaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainPluginServer).BuildCoinbase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChainPlugin_BuildCoinbase_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainPluginServer).BuildCoinbase(ctx, req.(*CoinbaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChainPlugin_ScriptPubKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainPluginServer).ScriptPubKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChainPlugin_ScriptPubKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainPluginServer).ScriptPubKey(ctx, req.(*AddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChainPlugin_GetBlockTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainPluginServer).GetBlockTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChainPlugin_GetBlockTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainPluginServer).GetBlockTemplate(ctx, req.(*TemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChainPlugin_SubmitBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainPluginServer).SubmitBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChainPlugin_SubmitBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainPluginServer).SubmitBlock(ctx, req.(*SubmitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChainPlugin_ServiceDesc is the grpc.ServiceDesc for ChainPlugin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChainPlugin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dogepool.chainplugin.v1.ChainPlugin",
+	HandlerType: (*ChainPluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Describe",
+			Handler:    _ChainPlugin_Describe_Handler,
+		},
+		{
+			MethodName: "HeaderDigest",
+			Handler:    _ChainPlugin_HeaderDigest_Handler,
+		},
+		{
+			MethodName: "CoinbaseDigest",
+			Handler:    _ChainPlugin_CoinbaseDigest_Handler,
+		},
+		{
+			MethodName: "BuildCoinbase",
+			Handler:    _ChainPlugin_BuildCoinbase_Handler,
+		},
+		{
+			MethodName: "ScriptPubKey",
+			Handler:    _ChainPlugin_ScriptPubKey_Handler,
+		},
+		{
+			MethodName: "GetBlockTemplate",
+			Handler:    _ChainPlugin_GetBlockTemplate_Handler,
+		},
+		{
+			MethodName: "SubmitBlock",
+			Handler:    _ChainPlugin_SubmitBlock_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "chainplugin.proto",
+}
@@ -0,0 +1,15 @@
+package chainplugin
+
+import (
+	"net"
+
+	"designs.capital/dogepool/chainplugin/pluginpb"
+	"google.golang.org/grpc"
+)
+
+// Blocks serving connections until the listener closes
+func Serve(listener net.Listener, plugin pluginpb.ChainPluginServer) error {
+	server := grpc.NewServer()
+	pluginpb.RegisterChainPluginServer(server, plugin)
+	return server.Serve(listener)
+}
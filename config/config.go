@@ -5,6 +5,8 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
+	"time"
 )
 
 type coinNodeConfig struct {
@@ -15,6 +17,16 @@ type coinNodeConfig struct {
 	Timeout      string `json:"timeout"`
 	NotifyURL    string `json:"block_notify_url"`
 	RewardTo     string `json:"reward_to"`
+
+	// Instead of rpc_password: the node's .cookie, or a file or environment variable holding user:password
+	RPC_CookieFile      string `json:"rpc_cookie_file"`
+	RPC_CredentialsFile string `json:"rpc_credentials_file"`
+	RPC_CredentialsEnv  string `json:"rpc_credentials_env"`
+	// Method name => duration, i.e. a longer "submitblock" timeout
+	MethodTimeouts map[string]string `json:"method_timeouts"`
+
+	// Published on block_notify_url besides hashblock: rawblock, hashtx or rawtx
+	ZMQTopics []string `json:"zmq_topics"`
 }
 
 type blockChainNodesConfigMap map[string][]coinNodeConfig // coin name => [] of blockNodes
@@ -55,6 +67,39 @@ type Chain struct {
 	RewardFrom           string      `json:"reward_from"`
 	MinerMinimumPayment  float32     `json:"miner_min_payment"`
 	PoolRewardRecipients []recipient `json:"pool_rewards"`
+	// Pay pool_rewards as their own coinbase outputs instead of through balances.
+	// Only the primary chain builds its own coinbase.
+	PoolRewardsInCoinbase bool `json:"pool_rewards_in_coinbase"`
+
+	// An encrypted wallet's passphrase is read from a file or variable at each
+	// payout, never from this config.  At most one may be set.
+	WalletPassphraseFile string `json:"wallet_passphrase_file"`
+	WalletPassphraseEnv  string `json:"wallet_passphrase_env"`
+	WalletUnlockTimeout  string `json:"wallet_unlock_timeout"` // Longest a payout keeps the wallet unlocked
+
+	// Who pays the payout transaction's fee, see the FeePolicy constants
+	FeePolicy     string  `json:"fee_policy"`
+	FixedFee      float64 `json:"fixed_fee"`       // FIXED: taken from every payout
+	FeeConfTarget int     `json:"fee_conf_target"` // Blocks for estimatesmartfee to aim at, 0 keeps the wallet's fee rate
+	DustLimit     float64 `json:"dust_limit"`      // Overrides the chain's dust limit
+
+	// Unlock blocks with getblockheader and getrawtransaction instead of the
+	// wallet, for nodes without one or coinbases paying another wallet
+	UnlockFromChain bool `json:"unlock_from_chain"`
+}
+
+const (
+	FeePolicyPool     = "POOL"     // The pool wallet pays, miners receive their full balance
+	FeePolicySubtract = "SUBTRACT" // The transaction fee is split across payouts by amount
+	FeePolicyFixed    = "FIXED"    // Every payout gives up fixed_fee, the pool wallet pays the real fee
+)
+
+// Upper case, POOL when unset
+func (c Chain) Fees() string {
+	if c.FeePolicy == "" {
+		return FeePolicyPool
+	}
+	return strings.ToUpper(c.FeePolicy)
 }
 
 type Chains map[string]Chain // chainName => chain payout config
@@ -63,6 +108,23 @@ type PayoutsConfig struct {
 	Interval string `json:"interval"`
 	Scheme   string `json:"scheme"`
 	Chains   `json:"chains"`
+
+	// How long confirmed blocks are checked for reorgs, defaults to a day
+	ReorgWindow string `json:"reorg_window"`
+}
+
+func (p PayoutsConfig) ReorgWindowDuration(fallback time.Duration) (time.Duration, error) {
+	if p.ReorgWindow == "" {
+		return fallback, nil
+	}
+	return time.ParseDuration(p.ReorgWindow)
+}
+
+// Blocks pay the finder's address directly in the coinbase
+const TrueSoloScheme = "TRUE_SOLO"
+
+func (p PayoutsConfig) TrueSolo() bool {
+	return strings.ToUpper(p.Scheme) == TrueSoloScheme
 }
 
 type Config struct {
@@ -73,14 +135,31 @@ type Config struct {
 	MaxConnections     int                      `json:"max_connections"`
 	ConnectionTimeout  string                   `json:"connection_timeout"`
 	PoolDifficulty     float64                  `json:"pool_difficulty"`
+	ValidationWorkers  int                      `json:"validation_workers"`   // Share hashing goroutines, 0 uses the CPU count
+	MaxInflightSubmits int                      `json:"max_inflight_submits"` // Queued submits per session before replying busy
 	BlockChainOrder    `json:"merged_blockchain_order"`
-	ShareFlushInterval string        `json:"share_flush_interval"`
-	HashrateWindow     string        `json:"hashrate_window"`
-	PoolStatsInterval  string        `json:"pool_stats_interval"`
-	Persister          sqlConfig     `json:"persistence"`
-	API                apiConfig     `json:"api"`
-	Payouts            PayoutsConfig `json:"payouts"`
-	AppStatsInterval   string        `json:"app_stats_interval"`
+	ChainsFile         string            `json:"chains_file"`   // Optional chain definitions added to the built-ins
+	ChainPlugins       map[string]string `json:"chain_plugins"` // chain name => plugin host:port
+	NodeProbeInterval  string            `json:"node_probe_interval"`
+	ShareFlushInterval string            `json:"share_flush_interval"`
+	HashrateWindow     string            `json:"hashrate_window"`
+	PoolStatsInterval  string            `json:"pool_stats_interval"`
+	Persister          sqlConfig         `json:"persistence"`
+	API                apiConfig         `json:"api"`
+	Payouts            PayoutsConfig     `json:"payouts"`
+	AppStatsInterval   string            `json:"app_stats_interval"`
+
+	// How long a node may sit behind or off its peers' tip before it's dropped
+	NodeDivergenceThreshold string `json:"node_divergence_threshold"`
+
+	// New work once this many transactions are announced over hashtx or rawtx,
+	// but no sooner than the interval after the last template.  0 disables.
+	MempoolRefreshTransactions int    `json:"mempool_refresh_transactions"`
+	MempoolRefreshInterval     string `json:"mempool_refresh_interval"`
+
+	// How often the active nodes' tips are checked against our work, in
+	// case ZMQ went quiet.  Defaults to 30s.
+	TemplatePollInterval string `json:"template_poll_interval"`
 }
 
 func LoadConfig(fileName string) *Config {
@@ -0,0 +1,145 @@
+package inspect
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"designs.capital/dogepool/bitcoin"
+)
+
+const usage = `usage: dogepool [-config config.json] inspect <kind> [flags] <hex | ->
+
+kinds:
+  header   decode an 80 byte block header and recompute its hashes
+  tx       decode a transaction, coinbases are annotated
+  block    decode a full block as submitted
+  auxpow   decode an AuxPoW and, with -aux-hash, verify it
+  share    rebuild a share from a captured mining.notify and mining.submit
+
+Pass - instead of hex to read it from stdin.  Chains from the config's
+chains_file and chain_plugins are available when the config exists.`
+
+// Runs `dogepool inspect`, args exclude "inspect" itself
+func Run(args []string, out io.Writer) error {
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	kind, args := args[0], args[1:]
+
+	flags := flag.NewFlagSet("inspect "+kind, flag.ContinueOnError)
+	chainName := flags.String("chain", "litecoin", "chain whose algorithm hashes the header")
+	auxHash := flags.String("aux-hash", "", "auxpow: createauxblock hash to verify against")
+	auxChainID := flags.Uint("aux-chain-id", 0, "auxpow: aux chain ID")
+	auxTarget := flags.String("aux-target", "", "auxpow: big endian aux target")
+	notify := flags.String("notify", "", "share: mining.notify message or params")
+	submit := flags.String("submit", "", "share: mining.submit message or params")
+	extranonce1 := flags.String("extranonce1", "", "share: the session's extranonce1")
+	difficulty := flags.Float64("difficulty", 0, "share: stratum difficulty the miner was set to")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	chain, err := lookupChain(*chainName)
+	if err != nil {
+		return err
+	}
+
+	var inspection bitcoin.Inspection
+	switch kind {
+	case "header", "tx", "block", "auxpow":
+		input, err := hexArgument(flags.Args())
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case "header":
+			inspection, err = bitcoin.InspectHeader(input, chain)
+		case "tx":
+			inspection, err = bitcoin.InspectTransaction(input)
+		case "block":
+			inspection, err = bitcoin.InspectBlock(input, chain)
+		case "auxpow":
+			var target *big.Int
+			if *auxTarget != "" {
+				target, _ = new(big.Int).SetString(*auxTarget, 16)
+			}
+			inspection, err = bitcoin.InspectAuxPow(input, chain, *auxHash, uint32(*auxChainID), target)
+		}
+		if err != nil {
+			return err
+		}
+	case "share":
+		notifyParams, err := stratumParams(*notify)
+		if err != nil {
+			return errors.Join(errors.New("-notify"), err)
+		}
+		submitParams, err := stratumParams(*submit)
+		if err != nil {
+			return errors.Join(errors.New("-submit"), err)
+		}
+		if *difficulty <= 0 {
+			return errors.New("-difficulty is required for shares")
+		}
+		inspection, err = bitcoin.InspectShare(notifyParams, submitParams, *extranonce1, *difficulty, chain)
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New(usage)
+	}
+
+	inspection.Print(out)
+	return nil
+}
+
+func lookupChain(chainName string) (chain bitcoin.Blockchain, err error) {
+	defer func() {
+		if recover() != nil {
+			err = errors.New("unknown chain: " + chainName)
+		}
+	}()
+	return bitcoin.GetChain(chainName), nil
+}
+
+func hexArgument(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("expected one hex argument, or - for stdin")
+	}
+	if args[0] != "-" {
+		return strings.TrimSpace(args[0]), nil
+	}
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(input)), nil
+}
+
+// Accepts a whole stratum message as logged or just its params array
+func stratumParams(message string) (bitcoin.Work, error) {
+	if message == "" {
+		return nil, errors.New("missing")
+	}
+
+	var request struct {
+		Params bitcoin.Work `json:"params"`
+	}
+	err := json.Unmarshal([]byte(message), &request)
+	if err == nil && request.Params != nil {
+		return request.Params, nil
+	}
+
+	var params bitcoin.Work
+	err = json.Unmarshal([]byte(message), &params)
+	if err != nil {
+		return nil, fmt.Errorf("not a stratum message or params array: %v", err)
+	}
+	return params, nil
+}
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"runtime"
 	"time"
 
+	"designs.capital/dogepool/alerts"
 	"designs.capital/dogepool/api"
+	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/chainevents"
+	"designs.capital/dogepool/chainplugin"
 	"designs.capital/dogepool/config"
+	"designs.capital/dogepool/inspect"
 	"designs.capital/dogepool/payouts"
 	"designs.capital/dogepool/persistence"
 	"designs.capital/dogepool/pool"
@@ -16,11 +23,24 @@ import (
 )
 
 func main() {
-	configFileName := parseCommandLineOptions()
+	configFileName, inspectConfigFileName := parseCommandLineOptions()
+	if configFileName == "inspect" {
+		// Chains from the pool's chains file and plugins can be inspected too
+		if _, err := os.Stat(inspectConfigFileName); err == nil {
+			loadChains(config.LoadConfig(inspectConfigFileName))
+		}
+		err := inspect.Run(flag.Args()[1:], os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	if configFileName == "" {
 		configFileName = "config.json"
 	}
 	configuration := config.LoadConfig(configFileName)
+	loadChains(configuration)
 
 	err := persistence.MakePersister(configuration)
 	if err != nil {
@@ -30,14 +50,25 @@ func main() {
 	rpcManagers := makeRPCManagers(configuration)
 	startPoolServer(configuration, rpcManagers)
 	startStatManager(configuration)
-	startAPIServer(configuration)
+	startAPIServer(configuration, rpcManagers)
 	startPayoutService(configuration, rpcManagers)
 	startAppStatsService(configuration)
 }
 
-func parseCommandLineOptions() string {
+func parseCommandLineOptions() (string, string) {
+	inspectConfigFileName := flag.String("config", "config.json", "config whose chains file and plugins inspect loads, skipped when missing")
 	flag.Parse()
-	return flag.Arg(0)
+	return flag.Arg(0), *inspectConfigFileName
+}
+
+func loadChains(configuration *config.Config) {
+	if configuration.ChainsFile != "" {
+		err := bitcoin.LoadChainDefinitions(configuration.ChainsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	registerChainPlugins(configuration)
 }
 
 func startPoolServer(configuration *config.Config, managers map[string]*rpc.Manager) *pool.PoolServer {
@@ -47,15 +78,16 @@ func startPoolServer(configuration *config.Config, managers map[string]*rpc.Mana
 	return poolServer
 }
 
-func startAPIServer(configuration *config.Config) {
-	go api.ListenAndServe(configuration)
+func startAPIServer(configuration *config.Config, managers map[string]*rpc.Manager) {
+	go api.ListenAndServe(configuration, managers)
 	log.Println("Started API on port: " + configuration.API.Port)
 }
 
 func startStatManager(configuration *config.Config) {
 	hashrateWindow := mustParseDuration(configuration.HashrateWindow)
 	statsRecordInterval := mustParseDuration(configuration.PoolStatsInterval)
-	go persistence.UpdateStatsOnInterval(configuration.PoolName, hashrateWindow, statsRecordInterval)
+	hashrateConstant := bitcoin.GetChain(configuration.GetPrimary()).Algorithm().HashrateConstant
+	go persistence.UpdateStatsOnInterval(configuration.PoolName, hashrateConstant, hashrateWindow, statsRecordInterval)
 	log.Printf("Stat Manager running every %v with a hashrate window of %v\n", statsRecordInterval, hashrateWindow)
 }
 
@@ -75,11 +107,36 @@ func startAppStatsService(configuration *config.Config) {
 		log.Printf("Total Goroutines: %v", runtime.NumGoroutine())
 		log.Printf("Total System Memory: %v", memStats.Sys)
 		log.Printf("Total Memory Allocated: %v", memStats.TotalAlloc)
+		for chain, chainStats := range chainevents.Stats() {
+			for topic, topicStats := range chainStats.Topics {
+				log.Printf("%v %v notifications: %v, missed: %v", chain, topic, topicStats.Messages, topicStats.Missed)
+			}
+			log.Printf("%v blocks ours: %v, others: %v, lost races: %v", chain, chainStats.OurBlocks, chainStats.OtherBlocks, chainStats.LostRaces)
+		}
 		fmt.Println("STATS END")
 		time.Sleep(interval)
 	}
 }
 
+func registerChainPlugins(configuration *config.Config) {
+	for chainName, address := range configuration.ChainPlugins {
+		chain, err := chainplugin.Dial(address, 10*time.Second)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if chain.ChainName() != chainName {
+			log.Fatalf("Chain plugin at %v serves %v, not %v\n", address, chain.ChainName(), chainName)
+		}
+		bitcoin.RegisterBlockchain(chain)
+		log.Printf("Registered %v chain plugin at %v\n", chainName, address)
+	}
+}
+
+const (
+	defaultNodeProbeInterval       = "15s"
+	defaultNodeDivergenceThreshold = "2m"
+)
+
 func makeRPCManagers(configuration *config.Config) map[string]*rpc.Manager {
 	managers := make(map[string]*rpc.Manager)
 	for _, chain := range configuration.BlockChainOrder {
@@ -92,15 +149,62 @@ func makeRPCManagers(configuration *config.Config) map[string]*rpc.Manager {
 				Username: nodeConfig.RPC_Username,
 				Password: nodeConfig.RPC_Password,
 				Timeout:  nodeConfig.Timeout,
+
+				CookieFile:      nodeConfig.RPC_CookieFile,
+				CredentialsFile: nodeConfig.RPC_CredentialsFile,
+				CredentialsEnv:  nodeConfig.RPC_CredentialsEnv,
+
+				MethodTimeouts: nodeConfig.MethodTimeouts,
+				TemplateRules:  bitcoin.GetChain(chain).BlockTemplateRules(),
 			}
 		}
-		// TODO move interval to config if accepted
-		manager := rpc.MakeRPCManager(chain, rpcConfig, "1h")
-		managers[chain] = &manager
+		probeInterval := configuration.NodeProbeInterval
+		if probeInterval == "" {
+			probeInterval = defaultNodeProbeInterval
+		}
+		divergenceThreshold := configuration.NodeDivergenceThreshold
+		if divergenceThreshold == "" {
+			divergenceThreshold = defaultNodeDivergenceThreshold
+		}
+		manager := rpc.MakeRPCManager(chain, rpcConfig, probeInterval, divergenceThreshold)
+		manager.OnDivergence = recordDivergence(configuration.PoolName)
+		manager.StartProbing(context.Background())
+		managers[chain] = manager
 	}
 	return managers
 }
 
+// Alerts on and keeps every node that leaves or rejoins its peers' tip.
+// Losing the active node is critical, the manager has already moved off it.
+func recordDivergence(poolID string) func(rpc.DivergenceEvent) {
+	return func(event rpc.DivergenceEvent) {
+		severity := alerts.Warning
+		if event.Kind == rpc.DivergenceResolved {
+			severity = alerts.Info
+		} else if event.Active {
+			severity = alerts.Critical
+		}
+		alerts.Raise(severity, event.Chain, event.String())
+
+		err := persistence.Divergences.Insert(persistence.Divergence{
+			PoolID:         poolID,
+			Chain:          event.Chain,
+			Node:           event.Node,
+			Active:         event.Active,
+			Kind:           event.Kind,
+			NodeHeight:     event.NodeHeight,
+			NodeTip:        event.NodeTip,
+			MajorityHeight: event.MajorityHeight,
+			MajorityTip:    event.MajorityTip,
+			Since:          event.Since,
+			Created:        event.Detected,
+		})
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
 func mustParseDuration(s string) time.Duration {
 	value, err := time.ParseDuration(s)
 	if err != nil {
@@ -15,6 +15,8 @@ func payoutSchemeFactory(schemeName string, config *config.Config) Scheme {
 		return PPLNS{config}
 	case "SOLO":
 		return SOLO{}
+	case "TRUE_SOLO": // Only blocks found before switching to true solo are paid by the wallet
+		return SOLO{}
 	default:
 		panic("Unknown payout scheme: " + schemeName)
 	}
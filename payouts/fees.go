@@ -0,0 +1,171 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+
+	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/config"
+	"designs.capital/dogepool/rpc"
+)
+
+const satoshisPerCoin = 1e8
+
+// What a chain's payout transaction sends once fees and dust are settled
+type payoutPlan struct {
+	outputs  map[string]float64 // Address => amount sent
+	heldBack map[string]float64 // Too small to send this round, the balance stays
+}
+
+func (p payoutPlan) total() float64 {
+	total := 0.0
+	for _, amount := range p.outputs {
+		total += amount
+	}
+	return total
+}
+
+// Works out what each address receives under the chain's fee policy, holding
+// back anything the network would treat as dust
+func planPayout(ctx context.Context, node *rpc.RPCClient, chain string, payoutConfig config.Chain, balances map[string]float64) (payoutPlan, error) {
+	plan := payoutPlan{
+		outputs:  make(map[string]float64),
+		heldBack: make(map[string]float64),
+	}
+	dust := dustLimit(chain, payoutConfig)
+
+	eligible := make(map[string]float64)
+	for address, amount := range balances {
+		if amount < dust {
+			plan.heldBack[address] = amount
+			continue
+		}
+		eligible[address] = amount
+	}
+
+	switch payoutConfig.Fees() {
+	case config.FeePolicyPool:
+		for address, amount := range eligible {
+			plan.outputs[address] = floorToSatoshi(amount)
+		}
+	case config.FeePolicyFixed:
+		if payoutConfig.FixedFee < 0 {
+			return plan, errors.New(chain + ": fixed_fee can't be negative")
+		}
+		for address, amount := range eligible {
+			plan.outputs[address] = floorToSatoshi(amount - payoutConfig.FixedFee)
+		}
+	case config.FeePolicySubtract:
+		// Dropping an output changes the fee, so quote again until every
+		// remaining output clears the dust limit
+		for len(eligible) > 0 {
+			fee, err := node.QuoteFee(ctx, eligible)
+			if err != nil {
+				return plan, errors.Join(errors.New(chain+": failed to quote the payout fee"), err)
+			}
+			plan.outputs = subtractProportionally(eligible, fee)
+
+			dropped := false
+			for address, amount := range plan.outputs {
+				if amount < dust || amount <= 0 {
+					plan.heldBack[address] = eligible[address]
+					delete(eligible, address)
+					dropped = true
+				}
+			}
+			if !dropped {
+				break
+			}
+		}
+	default:
+		m := "%v: unknown fee_policy %v, expected %v, %v or %v"
+		m = fmt.Sprintf(m, chain, payoutConfig.FeePolicy, config.FeePolicyPool, config.FeePolicySubtract, config.FeePolicyFixed)
+		return plan, errors.New(m)
+	}
+
+	for address, amount := range plan.outputs {
+		if amount < dust || amount <= 0 {
+			plan.heldBack[address] = balances[address]
+			delete(plan.outputs, address)
+		}
+	}
+
+	return plan, nil
+}
+
+// Each output gives up the share of fee its amount is of the total,
+// rounded so the outputs never cover less than the fee
+func subtractProportionally(amounts map[string]float64, fee float64) map[string]float64 {
+	total := 0.0
+	for _, amount := range amounts {
+		total += amount
+	}
+
+	outputs := make(map[string]float64, len(amounts))
+	for address, amount := range amounts {
+		outputs[address] = floorToSatoshi(amount - fee*amount/total)
+	}
+	return outputs
+}
+
+// What a payout's recipient gave up to fees: their share of the quoted fee
+// under SUBTRACT, fixed_fee under FIXED and nothing when the pool pays
+func deductedFee(balance, sent float64) float64 {
+	return math.Max(0, math.Round((balance-sent)*satoshisPerCoin)/satoshisPerCoin)
+}
+
+func floorToSatoshi(amount float64) float64 {
+	// The small nudge keeps amounts like 0.29 from flooring to 0.28999999
+	return math.Floor(amount*satoshisPerCoin+1e-6) / satoshisPerCoin
+}
+
+func dustLimit(chain string, payoutConfig config.Chain) float64 {
+	if payoutConfig.DustLimit > 0 {
+		return payoutConfig.DustLimit
+	}
+	limiter, ok := bitcoin.GetChain(chain).(bitcoin.DustLimiter)
+	if !ok {
+		return 0
+	}
+	return limiter.DustLimit()
+}
+
+// Points the wallet at the current fee estimate.  Chains without fee
+// estimation, or without enough data for one, keep the wallet's own rate.
+func applyFeeRate(ctx context.Context, node *rpc.RPCClient, chain string, payoutConfig config.Chain) error {
+	if payoutConfig.FeeConfTarget <= 0 {
+		return nil
+	}
+
+	feeRate, err := node.EstimateSmartFee(ctx, payoutConfig.FeeConfTarget)
+	if err != nil {
+		log.Printf("⚠️  %v payouts keep the wallet's fee rate: %v", chain, err)
+		return nil
+	}
+
+	err = node.SetTxFee(ctx, feeRate)
+	if errors.Is(err, rpc.ErrMethodNotFound) {
+		log.Printf("⚠️  %v payouts keep the wallet's fee rate: %v", chain, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("%v payouts use a fee rate of %v per kB", chain, feeRate)
+	return nil
+}
+
+// The fee the sent transaction actually paid.  The payout is already out,
+// so a wallet that can't say is logged rather than failing the round.
+func paidFee(ctx context.Context, node *rpc.RPCClient, chain, transactionID string) float64 {
+	transaction, err := node.GetTransaction(ctx, transactionID)
+	if err != nil {
+		log.Printf("⚠️  %v payout %v fee unknown: %v", chain, transactionID, err)
+		return 0
+	}
+	return math.Abs(transaction.Fee)
+}
@@ -0,0 +1,53 @@
+package payouts
+
+import (
+	"context"
+	"testing"
+
+	"designs.capital/dogepool/config"
+)
+
+// The pool and fixed policies plan without asking the node for a fee quote
+func TestPaymentFeeIsWhatTheRecipientGaveUp(t *testing.T) {
+	balances := map[string]float64{"a": 1, "b": 0.29, "c": 12.34567891}
+
+	tests := []struct {
+		policy   string
+		fixedFee float64
+		fees     map[string]float64
+	}{
+		{config.FeePolicyPool, 0, map[string]float64{"a": 0, "b": 0, "c": 0}},
+		{config.FeePolicyFixed, 0.001, map[string]float64{"a": 0.001, "b": 0.001, "c": 0.001}},
+		{config.FeePolicyFixed, 0.01234567, map[string]float64{"a": 0.01234567, "b": 0.01234567, "c": 0.01234567}},
+	}
+	for _, test := range tests {
+		payoutConfig := config.Chain{FeePolicy: test.policy, FixedFee: test.fixedFee}
+		plan, err := planPayout(context.Background(), nil, "dogecoin", payoutConfig, balances)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for address, want := range test.fees {
+			sent, found := plan.outputs[address]
+			if !found {
+				t.Fatalf("%v: %v held back", test.policy, address)
+			}
+			if fee := deductedFee(balances[address], sent); fee != want {
+				t.Errorf("%v %v: fee %v, want %v", test.policy, address, fee, want)
+			}
+		}
+	}
+}
+
+func TestSubtractedFeesCoverTheQuote(t *testing.T) {
+	balances := map[string]float64{"a": 1, "b": 0.29, "c": 12.34567891}
+	const quote = 0.0123
+
+	outputs := subtractProportionally(balances, quote)
+	total := 0.0
+	for address, sent := range outputs {
+		total += deductedFee(balances[address], sent)
+	}
+	if total < quote || total > quote+0.00000003 {
+		t.Errorf("recipients gave up %v for a %v fee", total, quote)
+	}
+}
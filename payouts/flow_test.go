@@ -0,0 +1,156 @@
+package payouts
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"designs.capital/dogepool/config"
+	"designs.capital/dogepool/persistence"
+	"designs.capital/dogepool/rpc"
+)
+
+// The regtest node pool/flow_test.go mines its block against, here as the
+// chain and wallet that confirm and pay it
+const flowFixtures = "../pool/testdata/block-flow.json"
+
+const (
+	flowMiner    = "myVAEip8wkzC956Goo5bMN5RipDVVyt7sx"
+	flowWallet   = "mviZBGSXEhfY6CoPm5HDE1sxHx2uqbFPT8"
+	flowOperator = "mjeYgcNNrbdBy9WtUVvCd12bgKwsPsT5vP"
+)
+
+func flowNode(t *testing.T) (*rpc.FakeNode, []rpc.Fixture) {
+	node, err := rpc.NewFakeNode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { node.Close() })
+	fixtures, err := rpc.LoadFixtures(flowFixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.AddFixtures(fixtures...)
+	return node, fixtures
+}
+
+// The row pool.submitCandidate records, read back from the node's view of
+// the block
+func foundBlock(t *testing.T, fixtures []rpc.Fixture) persistence.Found {
+	for _, fixture := range fixtures {
+		if fixture.Method != "getblock" {
+			continue
+		}
+		var block rpc.GetBlockReply
+		err := json.Unmarshal(fixture.Result, &block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hash []string
+		err = json.Unmarshal(fixture.Params, &hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		coinbase, err := reverseHexBytes(block.Transactions[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return persistence.Found{
+			PoolID:                      "flow",
+			Chain:                       "litecoin",
+			Type:                        "primary",
+			BlockHeight:                 uint(block.Height),
+			Hash:                        hash[0],
+			TransactionConfirmationData: coinbase,
+			Miner:                       flowMiner,
+			Status:                      persistence.StatusSubmitted,
+			Created:                     time.Now(),
+		}
+	}
+	t.Fatal(flowFixtures + " has no getblock fixture")
+	return persistence.Found{}
+}
+
+func TestFoundBlockIsConfirmedAndPaid(t *testing.T) {
+	node, fixtures := flowNode(t)
+	contents, err := json.Marshal(map[string]any{
+		"pool_name": "flow",
+		"blockchains": map[string]any{"litecoin": []map[string]string{{
+			"name":      "regtest",
+			"rpc_url":   node.URL,
+			"timeout":   "5s",
+			"reward_to": flowWallet,
+		}}},
+		"payouts": map[string]any{
+			"scheme": "SOLO",
+			"chains": map[string]any{"litecoin": map[string]any{
+				"reward_from":  flowWallet,
+				"pool_rewards": []map[string]any{{"address": flowOperator, "percentage": 0.01}},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var configuration config.Config
+	err = json.Unmarshal(contents, &configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configuration.BlockChainOrder = []string{"litecoin"}
+	managers := map[string]*rpc.Manager{"litecoin": rpc.MakeRPCManager("litecoin", []rpc.Config{{Name: "regtest", URL: node.URL, Timeout: "5s"}}, "1m", "1m")}
+
+	found := foundBlock(t, fixtures)
+	blocks, err := classifyBlocks(persistence.FoundBlocks{found}, &configuration, managers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmed := blocks[0]
+	confirmed.Status = nextStatus(found.Status, confirmed.Status)
+	if confirmed.Status != persistence.StatusConfirmed || confirmed.Reward != 50 {
+		t.Fatalf("block %v with reward %v, want confirmed with 50", confirmed.Status, confirmed.Reward)
+	}
+
+	credits, _, err := calculateBlockRewards(confirmed, &configuration, managers["litecoin"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var balances []persistence.Balance
+	for _, credit := range credits {
+		balances = append(balances, persistence.Balance{PoolID: "flow", Chain: "litecoin", Address: credit.Address, Amount: credit.Amount})
+	}
+
+	payouts, err := bitcoinTryManyPayments(balances, &configuration, managers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payout := payouts["litecoin"]
+	if payout.transactionID != "5d1c0b3a9e8f7d6c5b4a39281706f5e4d3c2b1a09f8e7d6c5b4a392817060504" || payout.fee != 0.0000452 {
+		t.Errorf("payout %v paid %v, want the wallet's transaction and fee", payout.transactionID, payout.fee)
+	}
+
+	sends := node.Calls("sendmany")
+	if len(sends) != 1 {
+		t.Fatalf("%v sendmany calls, want 1", len(sends))
+	}
+	var params []json.RawMessage
+	err = json.Unmarshal(sends[0].Params, &params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outputs map[string]float64
+	err = json.Unmarshal(params[1], &outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{flowOperator: 0.5, flowMiner: 49.5}
+	if len(outputs) != len(want) {
+		t.Fatalf("sent %v, want %v", outputs, want)
+	}
+	for address, amount := range want {
+		if math.Abs(outputs[address]-amount) > 1e-8 {
+			t.Errorf("sent %v to %v, want %v", outputs[address], address, amount)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package payouts
+
+import (
+	"fmt"
+	"log"
+
+	"designs.capital/dogepool/alerts"
+	"designs.capital/dogepool/config"
+	"designs.capital/dogepool/persistence"
+	"designs.capital/dogepool/rpc"
+)
+
+// A block after this round's look at the chain, with the status it had before
+type settledBlock struct {
+	persistence.Found
+	previousStatus string
+}
+
+// Where a block goes from its stored status given what classifying it
+// against the chain found.  Once confirmed the only way out is a reorg,
+// orphaned and reorged blocks never come back.
+func nextStatus(stored, observed string) string {
+	switch stored {
+	case persistence.StatusSubmitted, persistence.StatusPending:
+		switch observed {
+		case persistence.StatusConfirmed, persistence.StatusOrphaned:
+			return observed
+		}
+		return persistence.StatusPending // The node knows it, it's maturing
+	case persistence.StatusConfirmed:
+		if observed == persistence.StatusOrphaned {
+			return persistence.StatusReorged
+		}
+		return persistence.StatusConfirmed
+	}
+	return stored
+}
+
+// Saves what changed for each block, crediting newly confirmed blocks and
+// reversing reorged ones.  Reports whether every block was settled, payouts
+// wait for the next round when one wasn't.
+func settleBlocks(blocks []settledBlock, config *config.Config, rpcManagers map[string]*rpc.Manager) bool {
+	settled := true
+	for _, block := range blocks {
+		err := settleBlock(block, config, rpcManagers)
+		if err != nil {
+			log.Printf("failed to settle %v block %v: %v", block.Chain, block.BlockHeight, err)
+			settled = false
+		}
+	}
+	return settled
+}
+
+func settleBlock(block settledBlock, config *config.Config, rpcManagers map[string]*rpc.Manager) error {
+	switch {
+	case block.Status == persistence.StatusReorged:
+		m := "%v block %v (%v) left the chain after it confirmed, reversing its rewards"
+		m = fmt.Sprintf(m, block.Chain, block.BlockHeight, block.Hash)
+		alerts.Raise(alerts.Critical, block.Chain, m)
+		return persistence.Balances.RevertBlock(block.Found)
+
+	case block.Status != persistence.StatusConfirmed || block.previousStatus == persistence.StatusConfirmed:
+		return persistence.Blocks.Update(block.Found)
+
+	case block.Source == persistence.SourceSolo:
+		log.Printf("%v block %v paid the finder in its coinbase, nothing to credit", block.Chain, block.BlockHeight)
+		return persistence.Blocks.Update(block.Found)
+	}
+
+	rpcManager, exists := rpcManagers[block.Chain]
+	if !exists {
+		panic("payouts.Manager: Blockchain not found - " + block.Chain)
+	}
+
+	credits, cutoffTime, err := calculateBlockRewards(block.Found, config, rpcManager)
+	if err != nil {
+		return err
+	}
+
+	// Left pending if this fails, so the next round credits it again
+	err = persistence.Balances.CreditBlock(block.Found, credits)
+	if err != nil {
+		return err
+	}
+
+	// Clear old shares
+	err = persistence.Shares.DeleteSharesBefore(config.PoolName, cutoffTime)
+	if err != nil {
+		log.Println("payouts.DeleteShares(): " + err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package payouts
+
+import (
+	"testing"
+
+	"designs.capital/dogepool/persistence"
+)
+
+func TestNextStatus(t *testing.T) {
+	const (
+		submitted = persistence.StatusSubmitted
+		pending   = persistence.StatusPending
+		confirmed = persistence.StatusConfirmed
+		orphaned  = persistence.StatusOrphaned
+		reorged   = persistence.StatusReorged
+	)
+
+	tests := []struct {
+		stored, observed, want string
+	}{
+		{submitted, submitted, pending},
+		{submitted, pending, pending},
+		{submitted, confirmed, confirmed},
+		{submitted, orphaned, orphaned},
+		{pending, submitted, pending},
+		{pending, confirmed, confirmed},
+		{pending, orphaned, orphaned},
+
+		// Only a reorg takes a credited block off confirmed
+		{confirmed, submitted, confirmed},
+		{confirmed, pending, confirmed},
+		{confirmed, confirmed, confirmed},
+		{confirmed, orphaned, reorged},
+
+		// Settled for good
+		{orphaned, confirmed, orphaned},
+		{orphaned, pending, orphaned},
+		{reorged, confirmed, reorged},
+		{reorged, orphaned, reorged},
+	}
+	for _, test := range tests {
+		got := nextStatus(test.stored, test.observed)
+		if got != test.want {
+			t.Errorf("%v observed %v: got %v, want %v", test.stored, test.observed, got, test.want)
+		}
+	}
+}
@@ -2,55 +2,43 @@ package payouts
 
 import (
 	"log"
+	"strings"
 	"time"
 
+	"designs.capital/dogepool/alerts"
 	"designs.capital/dogepool/config"
 	"designs.capital/dogepool/persistence"
 	"designs.capital/dogepool/rpc"
 )
 
 func RunManager(config *config.Config, rpcManagers map[string]*rpc.Manager, interval time.Duration) {
-	var blocks persistence.FoundBlocks
-	var err error
-	var cutoffTime time.Time
 	for {
 		time.Sleep(interval)
 
 		log.Println("Checking block confirmations")
 
 		// Unlock Loop
-		blocks, err = unlockBlocks(config.PoolName, rpcManagers)
+		blocks, err := unlockBlocks(config, rpcManagers)
 		if err != nil {
 			log.Println(err)
 			continue
 		}
 
-		// Calculate Rewards loop
-		for _, confirmed := range blocks.GetConfirmed() {
-			rpcManager, exists := rpcManagers[confirmed.Chain]
-			if !exists {
-				panic("payouts.Manager: Blockchain not found - " + confirmed.Chain)
-			}
-
-			cutoffTime, err = calculateBlockRewards(confirmed, config, rpcManager)
-			if err != nil {
-				log.Println(err)
-				continue
-			}
-
-			// Clear old shares
-			err = persistence.Shares.DeleteSharesBefore(config.PoolName, cutoffTime)
-			if err != nil {
-				log.Println("payouts.DeleteShares(): " + err.Error())
-			}
+		// Credit confirmed blocks, reverse reorged ones
+		if !settleBlocks(blocks, config, rpcManagers) {
+			log.Println("Payouts wait until every block is settled")
+			continue
 		}
 
-		// Save block updates
-		for _, block := range blocks {
-			err = persistence.Blocks.Update(block)
-			if err != nil {
-				log.Println(err)
-			}
+		discrepancies, err := persistence.Balances.LedgerDiscrepancies(config.PoolName)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		if len(discrepancies) > 0 {
+			m := "Payouts held, the balance ledger is inconsistent: " + strings.Join(discrepancies, "; ")
+			alerts.Raise(alerts.Critical, "payouts", m)
+			continue
 		}
 
 		// Actual payouts
@@ -1,6 +1,7 @@
 package payouts
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -28,16 +29,16 @@ func payoutBalances(config *config.Config, rpcManagers map[string]*rpc.Manager)
 	}
 
 	// Send payments
-	transactionConfirmation, err := bitcoinTryManyPayments(balances, config, rpcManagers)
+	payoutsByChain, err := bitcoinTryManyPayments(balances, config, rpcManagers)
 	if err != nil {
 		return err
 	}
 
 	for _, balance := range balances {
-		confirmation, found := transactionConfirmation[balance.Chain]
+		payout, found := payoutsByChain[balance.Chain]
 		if !found {
 			m := "failed to find payment confirmation for %v on chain %v"
-			m = fmt.Sprintf(balance.Address, balance.Chain)
+			m = fmt.Sprintf(m, balance.Address, balance.Chain)
 			return errors.New(m)
 		}
 
@@ -47,19 +48,26 @@ func payoutBalances(config *config.Config, rpcManagers map[string]*rpc.Manager)
 			return err
 		}
 
+		sent, found := payout.outputs[address]
+		if !found {
+			log.Printf("%v payout of %v to %v held back below the dust limit", balance.Chain, balance.Amount, address)
+			continue
+		}
+
 		err = persistence.Payments.Insert(persistence.Payment{
 			PoolID:                      balance.PoolID,
 			Chain:                       balance.Chain,
 			Address:                     address,
-			Amount:                      balance.Amount,
+			Amount:                      sent,
+			Fee:                         deductedFee(balance.Amount, sent),
 			Created:                     time.Now(),
-			TransactionConfirmationData: confirmation,
+			TransactionConfirmationData: payout.transactionID,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Reset Balance
+		// Reset Balance, fees taken from the payout included
 		usage := "Paid balance to miner"
 		err = persistence.Balances.AddAmount(config.PoolName, balance.Chain, balance.Address, usage, balance.Amount*-1)
 		if err != nil {
@@ -70,10 +78,16 @@ func payoutBalances(config *config.Config, rpcManagers map[string]*rpc.Manager)
 	return nil
 }
 
+type chainPayout struct {
+	payoutPlan
+	transactionID string
+	fee           float64
+}
+
 // TODO move to bitcoin aka the chain package.
-func bitcoinTryManyPayments(balances []persistence.Balance, config *config.Config, rpcManagers map[string]*rpc.Manager) (map[string]string, error) {
+func bitcoinTryManyPayments(balances []persistence.Balance, config *config.Config, rpcManagers map[string]*rpc.Manager) (map[string]chainPayout, error) {
 	transactionsGroupedByChain := make(map[string]map[string]float64)
-	transactionConfirmationByChain := make(map[string]string)
+	payoutsByChain := make(map[string]chainPayout)
 
 	for _, balance := range balances {
 		chainBalances, exists := transactionsGroupedByChain[balance.Chain]
@@ -83,7 +97,7 @@ func bitcoinTryManyPayments(balances []persistence.Balance, config *config.Confi
 
 		address, err := findBalanceAddress(balance, config)
 		if err != nil {
-			return transactionConfirmationByChain, err
+			return payoutsByChain, err
 		}
 
 		chainBalances[address] = balance.Amount
@@ -93,24 +107,53 @@ func bitcoinTryManyPayments(balances []persistence.Balance, config *config.Confi
 	for chain, transactions := range transactionsGroupedByChain {
 		client, exists := rpcManagers[chain]
 		if !exists {
-			return transactionConfirmationByChain, errors.New("payouts.bitcoinTryManyPayments() - failed to find chain rpc: " + chain)
+			return payoutsByChain, errors.New("payouts.bitcoinTryManyPayments() - failed to find chain rpc: " + chain)
+		}
+		payoutConfig := config.Payouts.Chains[chain]
+		passphrase, err := walletPassphrase(chain, payoutConfig)
+		if err != nil {
+			return payoutsByChain, err
+		}
+		unlockTimeout, err := walletUnlockTimeout(payoutConfig)
+		if err != nil {
+			return payoutsByChain, err
 		}
+
+		ctx := context.Background()
 		node := client.GetActiveClient()
-		transactionID, err := node.SendMany(transactions)
+		err = applyFeeRate(ctx, node, chain, payoutConfig)
+		if err != nil {
+			return payoutsByChain, err
+		}
+		plan, err := planPayout(ctx, node, chain, payoutConfig, transactions)
+		if err != nil {
+			return payoutsByChain, err
+		}
+		payout := chainPayout{payoutPlan: plan}
+		if len(plan.outputs) == 0 {
+			payoutsByChain[chain] = payout
+			continue
+		}
+
+		err = node.WithUnlockedWallet(ctx, passphrase, unlockTimeout, func(ctx context.Context) error {
+			var err error
+			payout.transactionID, err = node.SendMany(ctx, plan.outputs)
+			return err
+		})
 		if err != nil {
 			m := "failed to send %v payments"
 			m = fmt.Sprintf(m, chain)
-			context := errors.New(m)
-			err = errors.Join(context, err)
-			return transactionConfirmationByChain, err
+			err = errors.Join(errors.New(m), err)
+			return payoutsByChain, err
 		}
 
-		transactionConfirmationByChain[chain] = transactionID
+		payout.fee = paidFee(ctx, node, chain, payout.transactionID)
+		payoutsByChain[chain] = payout
 
-		log.Printf("%v Payouts Transaction ID: %v\n", chain, transactionID)
+		log.Printf("%v Payouts Transaction ID: %v, fee %v\n", chain, payout.transactionID, payout.fee)
 	}
 
-	return transactionConfirmationByChain, nil
+	return payoutsByChain, nil
 }
 
 // TODO - move this to REWARDS?
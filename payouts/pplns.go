@@ -14,7 +14,7 @@ type PPLNS struct {
 	config *config.Config
 }
 
-func (scheme PPLNS) UpdateMinerBalances(poolID string, blockReward float64, confirmed persistence.Found) (time.Time, error) {
+func (scheme PPLNS) MinerCredits(poolID string, blockReward float64, confirmed persistence.Found) ([]persistence.BlockCredit, time.Time, error) {
 	emptyTime, cutoffTime := time.Time{}, time.Time{}
 	before := confirmed.Created
 	inclusive := true
@@ -32,7 +32,7 @@ func (scheme PPLNS) UpdateMinerBalances(poolID string, blockReward float64, conf
 	for !done {
 		page, err := persistence.Shares.GetSharesBefore(poolID, before, inclusive, pageSize)
 		if err != nil {
-			return emptyTime, err
+			return nil, emptyTime, err
 		}
 
 		inclusive = false
@@ -57,7 +57,7 @@ func (scheme PPLNS) UpdateMinerBalances(poolID string, blockReward float64, conf
 			remainingReward -= reward
 
 			if remainingReward <= 0 {
-				return emptyTime, errors.New("PPLNS payout overflow! - we awarded more than we have.  Awards not persisted")
+				return nil, emptyTime, errors.New("PPLNS payout overflow! - we awarded more than we have.  Awards not persisted")
 			}
 
 		}
@@ -71,19 +71,15 @@ func (scheme PPLNS) UpdateMinerBalances(poolID string, blockReward float64, conf
 		before = page[pageLength-1].Created
 	}
 
-	var err error
+	var credits []persistence.BlockCredit
 	for miner, reward := range minerRewards {
 		log.Printf("Awarding %v %v PPLNS reward to miner %v for work on %v block %v\n",
 			reward, confirmed.Chain, confirmed.Miner, confirmed.Chain, confirmed.BlockHeight)
 
 		usage := "PPLNS REWARD FOR BLOCK %v"
 		usage = fmt.Sprintf(usage, confirmed.BlockHeight)
-		err = persistence.Balances.AddAmount(poolID, confirmed.Chain, miner, usage, reward)
-		if err != nil {
-			context := errors.New("failed to add balances: ")
-			return emptyTime, errors.Join(context, err)
-		}
+		credits = append(credits, persistence.BlockCredit{Address: miner, Usage: usage, Amount: reward})
 	}
 
-	return cutoffTime, nil
+	return credits, cutoffTime, nil
 }
@@ -11,7 +11,7 @@ import (
 
 type PROP struct{}
 
-func (PROP) UpdateMinerBalances(poolID string, blockReward float64, confirmed persistence.Found) (time.Time, error) {
+func (PROP) MinerCredits(poolID string, blockReward float64, confirmed persistence.Found) ([]persistence.BlockCredit, time.Time, error) {
 	emptyTime, cutoffTime := time.Time{}, time.Time{}
 	before := confirmed.Created
 	minerShares, minerScores := make(map[string]float64), make(map[string]float64)
@@ -24,7 +24,7 @@ func (PROP) UpdateMinerBalances(poolID string, blockReward float64, confirmed pe
 	for !done {
 		page, err := persistence.Shares.GetSharesBefore(poolID, before, inclusive, pageSize)
 		if err != nil {
-			return emptyTime, err
+			return nil, emptyTime, err
 		}
 
 		inclusive = false
@@ -68,21 +68,18 @@ func (PROP) UpdateMinerBalances(poolID string, blockReward float64, confirmed pe
 	}
 
 	if remainingReward <= 0 {
-		return emptyTime, errors.New("PROP payout overflow! - we awarded more than we have.  Awards not persisted")
+		return nil, emptyTime, errors.New("PROP payout overflow! - we awarded more than we have.  Awards not persisted")
 	}
 
-	var err error
+	var credits []persistence.BlockCredit
 	for miner, reward := range minerRewards {
 		log.Printf("Awarding %v %v PROP reward to miner %v for work on %v block %v\n",
 			reward, confirmed.Chain, confirmed.Miner, confirmed.Chain, confirmed.BlockHeight)
 
 		usage := "PROP REWARD FOR BLOCK %v"
 		usage = fmt.Sprintf(usage, confirmed.BlockHeight)
-		err = persistence.Balances.AddAmount(poolID, confirmed.Chain, miner, usage, reward)
-		if err != nil {
-			return emptyTime, err
-		}
+		credits = append(credits, persistence.BlockCredit{Address: miner, Usage: usage, Amount: reward})
 	}
 
-	return cutoffTime, nil
+	return credits, cutoffTime, nil
 }
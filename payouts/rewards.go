@@ -1,59 +1,101 @@
 package payouts
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"time"
 
+	"designs.capital/dogepool/bitcoin"
 	"designs.capital/dogepool/config"
 	"designs.capital/dogepool/persistence"
 	"designs.capital/dogepool/rpc"
 )
 
-func calculateBlockRewards(confirmed persistence.Found, config *config.Config, rpcManager *rpc.Manager) (time.Time, error) {
-	remainingReward, err := calculatePoolReward(confirmed, config, rpcManager)
+// Everything the block pays through balances, for the pool's recipients and then the miners
+func calculateBlockRewards(confirmed persistence.Found, config *config.Config, rpcManager *rpc.Manager) ([]persistence.BlockCredit, time.Time, error) {
+	remainingReward, poolCredits, err := calculatePoolReward(confirmed, config, rpcManager)
 	if err != nil {
-		return time.Time{}, err
+		return nil, time.Time{}, err
 	}
 
-	return calculateMinerRewards(remainingReward, confirmed, config)
+	minerCredits, cutoffTime, err := calculateMinerRewards(remainingReward, confirmed, config)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return append(poolCredits, minerCredits...), cutoffTime, nil
 }
 
-func calculatePoolReward(confirmed persistence.Found, config *config.Config, rpcManager *rpc.Manager) (float64, error) {
+func calculatePoolReward(confirmed persistence.Found, config *config.Config, rpcManager *rpc.Manager) (float64, []persistence.BlockCredit, error) {
 	remainingReward := confirmed.Reward
 	payoutConfig, exists := config.Payouts.Chains[confirmed.Chain]
 	if !exists {
-		return 0, errors.New("calculatePoolReward(): failed to find payout config for: " + confirmed.Chain)
+		return 0, nil, errors.New("calculatePoolReward(): failed to find payout config for: " + confirmed.Chain)
 	}
 
-	for _, poolRecipient := range payoutConfig.PoolRewardRecipients {
-		recipientAmount := poolRecipient.Percentage * confirmed.Reward
-		remainingReward -= recipientAmount
+	var credits []persistence.BlockCredit
 
+	// Recipients with their own coinbase output were paid on-chain and
+	// aren't part of the wallet's coinbase amount
+	paidInCoinbase := payoutConfig.PoolRewardsInCoinbase && confirmed.Chain == config.GetPrimary()
+
+	for _, poolRecipient := range payoutConfig.PoolRewardRecipients {
 		chain, exists := config.BlockchainNodes[confirmed.Chain]
 		if !exists {
-			return 0, errors.New("calculatePoolReward(): failed to get blockchain node for : " + confirmed.Chain)
+			return 0, nil, errors.New("calculatePoolReward(): failed to get blockchain node for : " + confirmed.Chain)
 		}
 		node := chain[rpcManager.GetIndex()]
+		if poolRecipient.Address == node.RewardTo && paidInCoinbase {
+			// The wallet got what the other recipients left, the pool's share is of the whole coinbase
+			share, err := coinbaseShare(confirmed, rpcManager, poolRecipient.Percentage)
+			if err != nil {
+				return 0, nil, err
+			}
+			remainingReward -= share
+			continue
+		}
 		if poolRecipient.Address == node.RewardTo { // The block chain reward address is the same as the pool reward address
+			remainingReward -= poolRecipient.Percentage * confirmed.Reward
+			continue
+		}
+		if paidInCoinbase {
 			continue
 		}
 
+		recipientAmount := poolRecipient.Percentage * confirmed.Reward
+		remainingReward -= recipientAmount
+
 		log.Printf("Crediting %v with %v %v", poolRecipient.Address, recipientAmount, confirmed.Chain)
 		usage := "Reward for block %v"
 		usage = fmt.Sprintf(usage, confirmed.BlockHeight)
-		err := persistence.Balances.AddAmount(config.PoolName, confirmed.Chain, poolRecipient.Address, usage, recipientAmount)
-		if err != nil {
-			return 0, err
-		}
+		credits = append(credits, persistence.BlockCredit{Address: poolRecipient.Address, Usage: usage, Amount: recipientAmount})
 	}
 
-	return remainingReward, nil
+	return remainingReward, credits, nil
+}
+
+// A recipient's share of the block's coinbase value, split the way the
+// coinbase outputs were
+func coinbaseShare(confirmed persistence.Found, rpcManager *rpc.Manager, percentage float64) (float64, error) {
+	value, err := rpcManager.GetActiveClient().GetCoinbaseValue(context.Background(), confirmed.Hash)
+	if err != nil {
+		m := "calculatePoolReward(): failed to fetch the coinbase of %v block %v"
+		m = fmt.Sprintf(m, confirmed.Chain, confirmed.BlockHeight)
+		return 0, errors.Join(errors.New(m), err)
+	}
+
+	share, err := bitcoin.CoinbaseShare(uint64(math.Round(value*1e8)), percentage)
+	if err != nil {
+		return 0, err
+	}
+	return float64(share) / 1e8, nil
 }
 
-func calculateMinerRewards(remainingReward float64, confirmed persistence.Found, config *config.Config) (time.Time, error) {
+func calculateMinerRewards(remainingReward float64, confirmed persistence.Found, config *config.Config) ([]persistence.BlockCredit, time.Time, error) {
 	payoutSchemeName := config.Payouts.Scheme
 	payoutScheme := payoutSchemeFactory(payoutSchemeName, config)
-	return payoutScheme.UpdateMinerBalances(config.PoolName, remainingReward, confirmed)
+	return payoutScheme.MinerCredits(config.PoolName, remainingReward, confirmed)
 }
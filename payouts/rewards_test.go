@@ -0,0 +1,118 @@
+package payouts
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/config"
+	"designs.capital/dogepool/persistence"
+	"designs.capital/dogepool/rpc"
+)
+
+// With pool rewards in the coinbase, the miners' credits, the pool's share
+// kept by the wallet and the other recipients' outputs are the whole coinbase
+func TestPoolRewardInCoinbaseAddsUpToTheCoinbase(t *testing.T) {
+	const (
+		wallet   = "pool wallet"
+		operator = "operator"
+		miner    = "miner"
+
+		coinbaseValue = 5_000_012_345
+		poolShare     = 65_000_160 // 1.3%, rounded down
+		operatorShare = 38_500_095 // 0.77%, rounded down
+	)
+
+	payees := bitcoin.CoinbasePayees{
+		RewardScript: "51",
+		Recipients:   []bitcoin.CoinbaseRecipient{{Address: operator, ScriptPubKey: "52", Percentage: 0.0077}},
+	}
+	outputs, err := payees.Split(coinbaseValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != 2 || outputs[1].Value != operatorShare {
+		t.Fatalf("coinbase outputs %v, want the reward and the operator's %v", outputs, operatorShare)
+	}
+
+	const hash = "74397e8a82e0c411b6558af3ef9961748b8d4aab7955173f6032c23ab38a5353"
+	vout := make([]map[string]any, len(outputs))
+	for i, output := range outputs {
+		vout[i] = map[string]any{"value": float64(output.Value) / 1e8, "n": i}
+	}
+	// getblock at verbosity 2, the only call the rewards make
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string `json:"method"`
+			Params []any  `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		if request.Method != "getblock" || len(request.Params) != 2 || request.Params[0] != hash || request.Params[1] != 2.0 {
+			t.Errorf("asked for %v %v, want the block at verbosity 2", request.Method, request.Params)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"result": map[string]any{"hash": hash, "tx": []any{map[string]any{"vout": vout}}},
+			"error":  nil,
+			"id":     1,
+		})
+	}))
+	defer node.Close()
+
+	contents, err := json.Marshal(map[string]any{
+		"pool_name": "rewards",
+		"blockchains": map[string]any{"litecoin": []map[string]string{{
+			"name":      "regtest",
+			"rpc_url":   node.URL,
+			"timeout":   "5s",
+			"reward_to": wallet,
+		}}},
+		"payouts": map[string]any{
+			"scheme": "SOLO",
+			"chains": map[string]any{"litecoin": map[string]any{
+				"pool_rewards_in_coinbase": true,
+				"pool_rewards": []map[string]any{
+					{"address": wallet, "percentage": 0.013},
+					{"address": operator, "percentage": 0.0077},
+				},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var configuration config.Config
+	err = json.Unmarshal(contents, &configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configuration.BlockChainOrder = []string{"litecoin"}
+	manager := rpc.MakeRPCManager("litecoin", []rpc.Config{{Name: "regtest", URL: node.URL, Timeout: "5s"}}, "1m", "1m")
+
+	confirmed := persistence.Found{
+		Chain:       "litecoin",
+		BlockHeight: 101,
+		Hash:        hash,
+		Miner:       miner,
+		Status:      persistence.StatusConfirmed,
+		Reward:      float64(outputs[0].Value) / 1e8, // What the wallet sees
+	}
+	credits, _, err := calculateBlockRewards(confirmed, &configuration, manager)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credited := uint64(0)
+	for _, credit := range credits {
+		if credit.Address != miner {
+			t.Errorf("credited %v to %v, the operator is paid on chain", credit.Amount, credit.Address)
+		}
+		credited += uint64(math.Round(credit.Amount * 1e8))
+	}
+	if credited+poolShare+operatorShare != coinbaseValue {
+		t.Errorf("credited %v, the pool keeps %v and the operator got %v: %v short of the coinbase's %v",
+			credited, poolShare, operatorShare, int64(coinbaseValue)-int64(credited+poolShare+operatorShare), coinbaseValue)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"designs.capital/dogepool/persistence"
 )
 
+// Works out what each miner is owed for a block.  The credits are posted
+// together with the block's confirmation.
 type Scheme interface {
-	UpdateMinerBalances(poolID string, remainingReward float64, confirmed persistence.Found) (time.Time, error)
+	MinerCredits(poolID string, remainingReward float64, confirmed persistence.Found) ([]persistence.BlockCredit, time.Time, error)
 }
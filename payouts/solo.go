@@ -10,11 +10,12 @@ import (
 
 type SOLO struct{}
 
-func (SOLO) UpdateMinerBalances(poolID string, remainingReward float64, confirmed persistence.Found) (time.Time, error) {
+func (SOLO) MinerCredits(poolID string, remainingReward float64, confirmed persistence.Found) ([]persistence.BlockCredit, time.Time, error) {
 	log.Printf("Awarding %v %v SOLO reward to miner %v for work on %v block %v\n",
 		remainingReward, confirmed.Chain, confirmed.Miner, confirmed.Chain, confirmed.BlockHeight)
 
 	usage := "SOLO REWARD FOR BLOCK %v"
 	usage = fmt.Sprintf(usage, confirmed.BlockHeight)
-	return confirmed.Created, persistence.Balances.AddAmount(poolID, confirmed.Chain, confirmed.Miner, usage, remainingReward)
+	credit := persistence.BlockCredit{Address: confirmed.Miner, Usage: usage, Amount: remainingReward}
+	return []persistence.BlockCredit{credit}, confirmed.Created, nil
 }
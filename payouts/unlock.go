@@ -1,68 +1,145 @@
 package payouts
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/config"
 	"designs.capital/dogepool/persistence"
 	"designs.capital/dogepool/rpc"
 )
 
-func unlockBlocks(poolID string, rpcManager map[string]*rpc.Manager) (persistence.FoundBlocks, error) {
-	pending, err := persistence.Blocks.PendingBlocksForPool(poolID)
+const defaultReorgWindow = 24 * time.Hour
+
+// Checks every unsettled block, and confirmed blocks young enough to be
+// reorged, against the chain
+func unlockBlocks(config *config.Config, rpcManager map[string]*rpc.Manager) ([]settledBlock, error) {
+	reorgWindow, err := config.Payouts.ReorgWindowDuration(defaultReorgWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	tracked, err := persistence.Blocks.BlocksToSettle(config.PoolName, time.Now().Add(-reorgWindow))
+	if err != nil {
+		return nil, err
+	}
+	previousStatuses := make([]string, len(tracked))
+	for i, block := range tracked {
+		previousStatuses[i] = block.Status
+	}
+
+	tracked, err = calculateBlockEffort(tracked, config.PoolName)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get chain based on block type
-	blocks, err := classifyBlocks(pending, rpcManager)
+	blocks, err := classifyBlocks(tracked, config, rpcManager)
 	if err != nil {
 		return nil, err
 	}
 
-	return calculateBlockEffort(blocks, poolID)
+	settled := make([]settledBlock, len(blocks))
+	for i, block := range blocks {
+		block.Status = nextStatus(previousStatuses[i], block.Status)
+		settled[i] = settledBlock{Found: block, previousStatus: previousStatuses[i]}
+	}
+	return settled, nil
 }
 
 // TODO - This is very bitcoin/chain specific
 // We eventually have to let the chain package consume the RPC package, and handle all chain related logic there.
 // ^ That will take care of a lot of TODOs related to seperation of concerns
-func classifyBlocks(blocks persistence.FoundBlocks, rpcManagers map[string]*rpc.Manager) (persistence.FoundBlocks, error) {
-	var rpcManager *rpc.Manager
-	var exists bool
+func classifyBlocks(blocks persistence.FoundBlocks, config *config.Config, rpcManagers map[string]*rpc.Manager) (persistence.FoundBlocks, error) {
+	var chainOrder []string
+	blocksByChain := make(map[string][]int) // chain => indexes into blocks
 	for i, localBlock := range blocks {
-		rpcManager, exists = rpcManagers[localBlock.Chain]
-		if !exists {
+		if _, exists := rpcManagers[localBlock.Chain]; !exists {
 			return nil, errors.New("unlocker failed to find node for: " + localBlock.Chain)
 		}
+		if _, seen := blocksByChain[localBlock.Chain]; !seen {
+			chainOrder = append(chainOrder, localBlock.Chain)
+		}
+		blocksByChain[localBlock.Chain] = append(blocksByChain[localBlock.Chain], i)
+	}
+
+	ctx := context.Background()
+	for _, chain := range chainOrder {
+		node := rpcManagers[chain].GetActiveClient()
+		check := walletCoinbases(node, chain, config)
+		if config.Payouts.Chains[chain].UnlockFromChain {
+			var err error
+			check, err = chainCoinbases(ctx, node, chain, config)
+			if err != nil {
+				return nil, err
+			}
+		}
 
-		remoteBlock, err := rpcManager.GetActiveClient().GetBlockByHash(localBlock.Hash)
+		err := classifyChainBlocks(ctx, blocks, blocksByChain[chain], node, check)
 		if err != nil {
-			m := "unlocker failed to find remote block for %v block %v, %v"
-			m = fmt.Sprintf(m, localBlock.Chain, localBlock.BlockHeight, localBlock.Hash)
-			context := errors.New(m)
-			err = errors.Join(context, err)
 			return nil, err
 		}
+	}
+
+	return blocks, nil
+}
+
+// Settles pool blocks from their coinbase transactions, IDs in display order
+type coinbaseChecker func(ctx context.Context, blocks persistence.FoundBlocks, indexes []int, coinbaseIDs []string) error
+
+// A few batched round trips per chain, for the blocks and then their
+// coinbase transactions, however many blocks are pending
+func classifyChainBlocks(ctx context.Context, blocks persistence.FoundBlocks, indexes []int, node *rpc.RPCClient, check coinbaseChecker) error {
+	hashes := make([]string, len(indexes))
+	for i, index := range indexes {
+		hashes[i] = blocks[index].Hash
+	}
+
+	remoteBlocks, blockErrors, err := node.GetBlocksByHash(ctx, hashes)
+	if err != nil {
+		return errors.Join(errors.New("unlocker failed to fetch remote blocks"), err)
+	}
+
+	var coinbaseIndexes []int
+	var coinbaseIDs []string
+	for i, index := range indexes {
+		localBlock := blocks[index]
+		remoteBlock := remoteBlocks[i]
+		// The node answered for this block and doesn't have it.  Orphaned,
+		// or reorged once nextStatus sees it was confirmed.
+		if blockErrors[i] != nil {
+			m := "⚠️  %v block %v, %v isn't known to the node, orphaning it: %v"
+			log.Printf(m, localBlock.Chain, localBlock.BlockHeight, localBlock.Hash, blockErrors[i])
+			blocks[index] = orphanBlock(localBlock)
+			continue
+		}
+
+		if localBlock.Source == persistence.SourceSolo {
+			blocks[index] = classifySoloBlock(localBlock, remoteBlock)
+			continue
+		}
 
 		if len(remoteBlock.Transactions) < 1 {
 			m := "unlocker failed to fetch transaction confirmation for %v block %v, %v"
 			m = fmt.Sprintf(m, localBlock.Chain, localBlock.BlockHeight, localBlock.Hash)
-			return nil, errors.New(m)
+			return errors.New(m)
 		}
 
-		remoteCoinbaseTransactionHash := remoteBlock.Transactions[0]
-		remoteCoinbaseTransactionHash, err = reverseHexBytes(remoteCoinbaseTransactionHash)
+		remoteCoinbaseTransactionHash, err := reverseHexBytes(remoteBlock.Transactions[0])
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if localBlock.TransactionConfirmationData != "" {
 			if localBlock.TransactionConfirmationData != remoteCoinbaseTransactionHash {
-				// Likely an orphan
-				m := "⚠️  Our confirmation data for %v height %v does not match the blockchains: (local) %v <> (remote) %v"
-				m = fmt.Sprintf(m, localBlock.Chain, localBlock.BlockHeight, localBlock.TransactionConfirmationData, remoteCoinbaseTransactionHash)
-				return nil, errors.New(m)
+				// Another coinbase under our hash means the block we found isn't the one on chain
+				m := "⚠️  Our confirmation data for %v height %v does not match the blockchains, orphaning it: (local) %v <> (remote) %v"
+				log.Printf(m, localBlock.Chain, localBlock.BlockHeight, localBlock.TransactionConfirmationData, remoteCoinbaseTransactionHash)
+				blocks[index] = orphanBlock(localBlock)
+				continue
 			}
 		} else { // Aux blocks do not return coinbase data
 			localBlock.TransactionConfirmationData = remoteCoinbaseTransactionHash
@@ -70,43 +147,180 @@ func classifyBlocks(blocks persistence.FoundBlocks, rpcManagers map[string]*rpc.
 
 		localConfirmationDataLittleEndian, err := reverseHexBytes(localBlock.TransactionConfirmationData)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		coinbaseIndexes = append(coinbaseIndexes, index)
+		coinbaseIDs = append(coinbaseIDs, localConfirmationDataLittleEndian)
+	}
+	if len(coinbaseIDs) < 1 {
+		return nil
+	}
+
+	return check(ctx, blocks, coinbaseIndexes, coinbaseIDs)
+}
+
+func orphanBlock(block persistence.Found) persistence.Found {
+	block.Status = persistence.StatusOrphaned
+	block.Reward = 0
+	return block
+}
+
+// The wallet's view, its category says whether the coinbase matured.
+// Coinbases the wallet has no details for are left to the chain's view.
+func walletCoinbases(node *rpc.RPCClient, chainName string, config *config.Config) coinbaseChecker {
+	return func(ctx context.Context, blocks persistence.FoundBlocks, coinbaseIndexes []int, coinbaseIDs []string) error {
+		coinbaseTransactions, transactionErrors, err := node.GetTransactions(ctx, coinbaseIDs)
+		if err != nil {
+			return errors.Join(errors.New("unlocker failed to fetch coinbase transactions"), err)
+		}
+
+		var chainIndexes []int
+		var chainIDs []string
+		for i, index := range coinbaseIndexes {
+			if transactionErrors[i] != nil {
+				m := "%v Block %v: (confirmation) %v"
+				m = fmt.Sprintf(m, blocks[index].Chain, blocks[index].BlockHeight, coinbaseIDs[i])
+				context := errors.New(m)
+				return errors.Join(context, transactionErrors[i])
+			}
+			coinbaseTransaction := coinbaseTransactions[i]
+			if len(coinbaseTransaction.Details) < 1 {
+				chainIndexes = append(chainIndexes, index)
+				chainIDs = append(chainIDs, coinbaseIDs[i])
+				continue
+			}
+
+			switch coinbaseTransaction.Details[0].Category {
+			case "immature":
+				min := bitcoin.GetChain(blocks[index].Chain).MinimumConfirmations()
+				blocks[index].ConfirmationProgress = float32(coinbaseTransaction.Confirmations) / float32(min)
+				blocks[index].ConfirmationProgress = roundToThreeDigits(blocks[index].ConfirmationProgress)
+				blocks[index].Reward = coinbaseTransaction.Amount
+			case "generate":
+				blocks[index].Status = persistence.StatusConfirmed
+				blocks[index].ConfirmationProgress = 1
+				blocks[index].Reward = coinbaseTransaction.Amount
+			default:
+				blocks[index] = orphanBlock(blocks[index])
+			}
+		}
+		if len(chainIndexes) < 1 {
+			return nil
 		}
-		coinbaseTransaction, err := rpcManager.GetActiveClient().GetTransaction(localConfirmationDataLittleEndian)
+
+		check, err := chainCoinbases(ctx, node, chainName, config)
 		if err != nil {
-			m := "%v Block %v: (confirmation) %v"
-			m = fmt.Sprintf(m, localBlock.Chain, localBlock.BlockHeight, localBlock.TransactionConfirmationData)
-			context := errors.New(m)
-			return nil, errors.Join(context, err)
+			return err
 		}
+		return check(ctx, blocks, chainIndexes, chainIDs)
+	}
+}
+
+// The chain's view for pools without the wallet.  Confirmations come from
+// getblockheader, the reward from the coinbase outputs paying a reward_to
+// address of one of the chain's nodes.
+func chainCoinbases(ctx context.Context, node *rpc.RPCClient, chainName string, config *config.Config) (coinbaseChecker, error) {
+	chainInfo, err := node.GetBlockChainInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		switch coinbaseTransaction.Details[0].Category {
-		case "immature":
-			min := bitcoin.GetChain(localBlock.Chain).MinimumConfirmations()
-			blocks[i].ConfirmationProgress = float32(coinbaseTransaction.Confirmations) / float32(min)
-			blocks[i].ConfirmationProgress = roundToThreeDigits(blocks[i].ConfirmationProgress)
-			blocks[i].Reward = coinbaseTransaction.Amount
-		case "generate":
-			blocks[i].Status = persistence.StatusConfirmed
-			blocks[i].ConfirmationProgress = 1
-			blocks[i].Reward = coinbaseTransaction.Amount
-		default:
-			blocks[i].Status = persistence.StatusOrphaned
-			blocks[i].Reward = 0
+	chain := bitcoin.GetChain(chainName)
+	rewardScripts := make(map[string]bool)
+	for _, nodeConfig := range config.BlockchainNodes[chainName] {
+		script, err := bitcoin.AddressToScriptPubKey(chain, chainInfo.Chain, nodeConfig.RewardTo)
+		if err != nil {
+			return nil, errors.Join(errors.New(chainName+" reward_to "+nodeConfig.RewardTo), err)
 		}
+		rewardScripts[script] = true
 	}
 
-	return blocks, nil
+	return func(ctx context.Context, blocks persistence.FoundBlocks, coinbaseIndexes []int, coinbaseIDs []string) error {
+		hashes := make([]string, len(coinbaseIndexes))
+		for i, index := range coinbaseIndexes {
+			hashes[i] = blocks[index].Hash
+		}
+
+		headers, headerErrors, err := node.GetBlockHeaders(ctx, hashes)
+		if err != nil {
+			return errors.Join(errors.New("unlocker failed to fetch block headers"), err)
+		}
+		coinbaseTransactions, transactionErrors, err := node.GetRawTransactionsInBlocks(ctx, coinbaseIDs, hashes)
+		if err != nil {
+			return errors.Join(errors.New("unlocker failed to fetch coinbase transactions"), err)
+		}
+
+		min := chain.MinimumConfirmations()
+		for i, index := range coinbaseIndexes {
+			block := &blocks[index]
+			if headerErrors[i] != nil || transactionErrors[i] != nil {
+				m := "%v Block %v: (confirmation) %v"
+				m = fmt.Sprintf(m, block.Chain, block.BlockHeight, coinbaseIDs[i])
+				return errors.Join(errors.New(m), headerErrors[i], transactionErrors[i])
+			}
+
+			if headers[i].Confirmations < 0 {
+				block.Status = persistence.StatusOrphaned
+				block.Reward = 0
+				continue
+			}
+
+			reward := 0.0
+			for _, output := range coinbaseTransactions[i].Outputs {
+				if rewardScripts[output.ScriptPubKey.Hex] {
+					reward += output.Value
+				}
+			}
+			if reward <= 0 {
+				m := "⚠️  %v block %v coinbase %v pays none of our reward_to addresses"
+				m = fmt.Sprintf(m, block.Chain, block.BlockHeight, coinbaseIDs[i])
+				return errors.New(m)
+			}
+			block.Reward = reward
+
+			if uint(headers[i].Confirmations) >= min {
+				block.Status = persistence.StatusConfirmed
+				block.ConfirmationProgress = 1
+				continue
+			}
+			block.ConfirmationProgress = float32(headers[i].Confirmations) / float32(min)
+			block.ConfirmationProgress = roundToThreeDigits(block.ConfirmationProgress)
+		}
+
+		return nil
+	}, nil
+}
+
+// Solo coinbases pay the finder, not our wallet, so only the chain can tell us where they stand
+func classifySoloBlock(localBlock persistence.Found, remoteBlock *rpc.GetBlockReply) persistence.Found {
+	min := bitcoin.GetChain(localBlock.Chain).MinimumConfirmations()
+	switch {
+	case remoteBlock.Confirmations < 0:
+		localBlock.Status = persistence.StatusOrphaned
+		localBlock.Reward = 0
+	case uint(remoteBlock.Confirmations) >= min:
+		localBlock.Status = persistence.StatusConfirmed
+		localBlock.ConfirmationProgress = 1
+	default:
+		localBlock.ConfirmationProgress = float32(remoteBlock.Confirmations) / float32(min)
+		localBlock.ConfirmationProgress = roundToThreeDigits(localBlock.ConfirmationProgress)
+	}
+	return localBlock
 }
 
 func calculateBlockEffort(blocks persistence.FoundBlocks, poolID string) (persistence.FoundBlocks, error) {
 	from, to := time.Time{}, time.Time{}
 	statuses := []string{
+		persistence.StatusSubmitted,
 		persistence.StatusConfirmed,
 		persistence.StatusOrphaned,
 		persistence.StatusPending,
+		persistence.StatusReorged,
 	}
 	for i, block := range blocks {
+		if block.Status == persistence.StatusConfirmed { // Worked out before it confirmed
+			continue
+		}
 		to = block.Created
 		lastBlock, err := persistence.Blocks.BlockBefore(poolID, statuses, block.Created)
 		if err != nil {
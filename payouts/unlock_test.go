@@ -0,0 +1,139 @@
+package payouts
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/config"
+	"designs.capital/dogepool/persistence"
+	"designs.capital/dogepool/rpc"
+)
+
+// Answers any params when params is nil
+func unlockFixture(t *testing.T, method string, params []any, result any) rpc.Fixture {
+	fixture := rpc.Fixture{Method: method}
+	var err error
+	if params != nil {
+		fixture.Params, err = json.Marshal(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	fixture.Result, err = json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fixture
+}
+
+func unlockConfig(t *testing.T, node *rpc.FakeNode) (*config.Config, map[string]*rpc.Manager) {
+	contents, err := json.Marshal(map[string]any{
+		"pool_name": "unlock",
+		"blockchains": map[string]any{"litecoin": []map[string]string{{
+			"name":      "regtest",
+			"rpc_url":   node.URL,
+			"timeout":   "5s",
+			"reward_to": flowWallet,
+		}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var configuration config.Config
+	err = json.Unmarshal(contents, &configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configuration.BlockChainOrder = []string{"litecoin"}
+	managers := map[string]*rpc.Manager{"litecoin": rpc.MakeRPCManager("litecoin", []rpc.Config{{Name: "regtest", URL: node.URL, Timeout: "5s"}}, "1m", "1m")}
+	return &configuration, managers
+}
+
+// A pool block with coinbase transaction ID coinbase, in display order
+func unlockBlock(t *testing.T, hashDigit, coinbase, status string) persistence.Found {
+	confirmation, err := reverseHexBytes(coinbase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return persistence.Found{
+		Chain:                       "litecoin",
+		Type:                        "primary",
+		BlockHeight:                 101,
+		Hash:                        strings.Repeat(hashDigit, 64),
+		TransactionConfirmationData: confirmation,
+		Status:                      status,
+	}
+}
+
+// One block's trouble mustn't hold up the others
+func TestClassifyBlocksSettlesEachBlock(t *testing.T) {
+	node, err := rpc.NewFakeNode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer node.Close()
+	configuration, managers := unlockConfig(t, node)
+
+	rewardScript, err := bitcoin.AddressToScriptPubKey(bitcoin.GetChain("litecoin"), "regtest", flowWallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coinbase := func(digit string) string { return strings.Repeat(digit, 64) }
+
+	blocks := persistence.FoundBlocks{
+		unlockBlock(t, "a", coinbase("1"), persistence.StatusConfirmed),
+		unlockBlock(t, "b", coinbase("2"), persistence.StatusPending),
+		unlockBlock(t, "c", coinbase("3"), persistence.StatusPending),
+		unlockBlock(t, "d", coinbase("4"), persistence.StatusPending),
+	}
+	fixtures := []rpc.Fixture{
+		// Our coinbase under another block
+		unlockFixture(t, "getblock", []any{blocks[1].Hash}, map[string]any{"hash": blocks[1].Hash, "confirmations": 3, "tx": []string{coinbase("9")}}),
+		// The wallet has no details, the chain has the reward
+		unlockFixture(t, "getblock", []any{blocks[2].Hash}, map[string]any{"hash": blocks[2].Hash, "confirmations": 200, "tx": []string{coinbase("3")}}),
+		unlockFixture(t, "gettransaction", []any{coinbase("3")}, map[string]any{"txid": coinbase("3"), "details": []any{}}),
+		unlockFixture(t, "getblockchaininfo", nil, map[string]any{"chain": "regtest"}),
+		unlockFixture(t, "getblockheader", []any{blocks[2].Hash}, map[string]any{"hash": blocks[2].Hash, "confirmations": 200}),
+		unlockFixture(t, "getrawtransaction", []any{coinbase("3"), true, blocks[2].Hash}, map[string]any{
+			"txid": coinbase("3"),
+			"vout": []any{map[string]any{"value": 50, "n": 0, "scriptPubKey": map[string]string{"hex": rewardScript}}},
+		}),
+		// Matured in the wallet
+		unlockFixture(t, "getblock", []any{blocks[3].Hash}, map[string]any{"hash": blocks[3].Hash, "confirmations": 200, "tx": []string{coinbase("4")}}),
+		unlockFixture(t, "gettransaction", []any{coinbase("4")}, map[string]any{"txid": coinbase("4"), "amount": 25, "confirmations": 200, "details": []any{map[string]any{"category": "generate"}}}),
+	}
+	// The node hasn't heard of the first block
+	fixtures = append(fixtures, rpc.Fixture{
+		Method: "getblock",
+		Params: json.RawMessage(`["` + blocks[0].Hash + `"]`),
+		Error:  &rpc.FixtureError{Code: -5, Message: "Block not found"},
+	})
+	node.AddFixtures(fixtures...)
+
+	stored := make([]string, len(blocks))
+	for i, block := range blocks {
+		stored[i] = block.Status
+	}
+	classified, err := classifyBlocks(blocks, configuration, managers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		status string
+		reward float64
+	}{
+		{persistence.StatusReorged, 0},
+		{persistence.StatusOrphaned, 0},
+		{persistence.StatusConfirmed, 50},
+		{persistence.StatusConfirmed, 25},
+	}
+	for i, block := range classified {
+		status := nextStatus(stored[i], block.Status)
+		if status != want[i].status || block.Reward != want[i].reward {
+			t.Errorf("block %v %v with reward %v, want %v with %v", i, status, block.Reward, want[i].status, want[i].reward)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package payouts
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"designs.capital/dogepool/config"
+)
+
+const defaultWalletUnlockTimeout = time.Minute
+
+// Reads the chain's wallet passphrase when it's needed, so rotating the file
+// doesn't take a restart.  nil when none is configured.
+func walletPassphrase(chain string, payoutConfig config.Chain) (func() (string, error), error) {
+	file, variable := payoutConfig.WalletPassphraseFile, payoutConfig.WalletPassphraseEnv
+	switch {
+	case file != "" && variable != "":
+		return nil, errors.New(chain + ": configure only one of wallet_passphrase_file or wallet_passphrase_env")
+	case file != "":
+		return func() (string, error) {
+			contents, err := os.ReadFile(file)
+			if err != nil {
+				return "", errors.New("can't read the " + chain + " wallet passphrase: " + err.Error())
+			}
+			return strings.TrimRight(string(contents), "\r\n"), nil
+		}, nil
+	case variable != "":
+		return func() (string, error) {
+			passphrase, exists := os.LookupEnv(variable)
+			if !exists {
+				return "", errors.New(chain + " wallet passphrase variable " + variable + " is not set")
+			}
+			return passphrase, nil
+		}, nil
+	}
+	return nil, nil
+}
+
+func walletUnlockTimeout(payoutConfig config.Chain) (time.Duration, error) {
+	if payoutConfig.WalletUnlockTimeout == "" {
+		return defaultWalletUnlockTimeout, nil
+	}
+	return time.ParseDuration(payoutConfig.WalletUnlockTimeout)
+}
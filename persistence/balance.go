@@ -3,6 +3,8 @@ package persistence
 import (
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type Balance struct {
@@ -28,43 +30,39 @@ type BalanceRepository struct {
 	*sql.DB
 }
 
+// Records the change and moves the balance in one transaction, so the
+// balance is always the sum of its changes
 func (r *BalanceRepository) AddAmount(poolID, chain, address, usage string, amount float64) error {
-	now := time.Now()
-
-	// query := "INSERT INTO balance_changes(poolid, chain, address, amount, usage, tags, created) "
-	query := `INSERT INTO balance_changes(poolid, chain, address, amount, usage, created)
-				VALUES($1, $2, $3, $4, $5, $6)`
-
-	stmt, err := r.DB.Prepare(query)
+	txn, err := r.DB.Begin()
 	if err != nil {
 		return err
 	}
+	defer txn.Rollback()
 
-	// _, err = stmt.Exec(poolID, chain, address, amount, usage, "", now)
-	_, err = stmt.Exec(poolID, chain, address, amount, usage, now)
+	err = addAmount(txn, poolID, chain, address, usage, amount, nil)
 	if err != nil {
 		return err
 	}
 
-	balance, err := r.GetBalance(poolID, chain, address)
-	if err != nil {
-		return err
-	}
+	return txn.Commit()
+}
 
-	balanceRecord := Balance{
-		PoolID:  poolID,
-		Chain:   chain,
-		Address: address,
-		Amount:  amount,
-		Updated: now,
-	}
+func addAmount(txn *sql.Tx, poolID, chain, address, usage string, amount float64, tags []string) error {
+	now := time.Now()
 
-	if balance == nil {
-		balanceRecord.Created = now
-		return r.Insert(balanceRecord)
+	query := `INSERT INTO balance_changes(poolid, chain, address, amount, usage, tags, created)
+				VALUES($1, $2, $3, $4, $5, $6, $7)`
+	_, err := txn.Exec(query, poolID, chain, address, amount, usage, pq.Array(tags), now)
+	if err != nil {
+		return err
 	}
 
-	return r.Update(balanceRecord)
+	query = `INSERT INTO balances(poolid, chain, address, amount, created, updated)
+				VALUES($1, $2, $3, $4, $5, $5)
+				ON CONFLICT (poolid, chain, address)
+				DO UPDATE SET amount = balances.amount + EXCLUDED.amount, updated = EXCLUDED.updated`
+	_, err = txn.Exec(query, poolID, chain, address, amount, now)
+	return err
 }
 
 func (r *BalanceRepository) Insert(balance Balance) error {
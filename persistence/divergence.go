@@ -0,0 +1,36 @@
+package persistence
+
+import (
+	"database/sql"
+	"time"
+)
+
+// A node leaving or rejoining the tip its peers agree on, kept for post-mortems
+type Divergence struct {
+	PoolID         string
+	Chain          string
+	Node           string
+	Active         bool
+	Kind           string
+	NodeHeight     uint64
+	NodeTip        string
+	MajorityHeight uint64
+	MajorityTip    string
+	Since          time.Time
+	Created        time.Time
+}
+
+type DivergenceRepository struct {
+	*sql.DB
+}
+
+func (r *DivergenceRepository) Insert(divergence Divergence) error {
+	query := `INSERT INTO node_divergences(poolid, chain, node, active, kind, nodeheight, nodetip, majorityheight, majoritytip, since, created)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := r.DB.Exec(query, divergence.PoolID, divergence.Chain, divergence.Node, divergence.Active,
+		divergence.Kind, divergence.NodeHeight, divergence.NodeTip, divergence.MajorityHeight,
+		divergence.MajorityTip, divergence.Since, divergence.Created)
+
+	return err
+}
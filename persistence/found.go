@@ -6,14 +6,27 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+// A block's life as the chain reports it:
+//
+//	submitted -> pending -> confirmed -> reorged
+//	submitted, pending -> orphaned
+//
+// Confirming credits the block's reward, a reorg reverses those credits.
 const (
+	StatusSubmitted = "submitted" // Accepted by our node, not yet seen on its chain
 	StatusPending   = "pending"
 	StatusOrphaned  = "orphaned"
 	StatusConfirmed = "confirmed"
+	StatusReorged   = "reorged" // Left the chain after it was confirmed and credited
 )
 
+// Source of a block whose coinbase paid the finder directly
+const SourceSolo = "solo"
+
 type Found struct {
 	ID                          uint
 	PoolID                      string
@@ -62,17 +75,21 @@ func (r *FoundRepository) Insert(block Found) error {
 }
 
 func (r *FoundRepository) Update(block Found) error {
+	return updateBlock(r.DB, block)
+}
+
+// Either the database or a transaction the update is part of
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func updateBlock(db execer, block Found) error {
 	query := "UPDATE blocks SET blockheight = $1, status = $2, type = $3, "
 	query = query + "reward = $4, effort = $5, "
 	query = query + "confirmationprogress = $6, hash = $7 "
 	query = query + "WHERE id = $8"
 
-	stmt, err := r.DB.Prepare(query)
-	if err != nil {
-		return err
-	}
-
-	result, err := stmt.Exec(block.BlockHeight, block.Status, block.Type, block.Reward, block.Effort,
+	result, err := db.Exec(query, block.BlockHeight, block.Status, block.Type, block.Reward, block.Effort,
 		block.ConfirmationProgress, block.Hash, block.ID)
 	if err != nil {
 		return err
@@ -174,18 +191,23 @@ func (r *FoundRepository) PageBlocksAcrossAllPools(blockStatus uint, page, pageS
 	return blockPage, nil
 }
 
-func (r *FoundRepository) PendingBlocksForPool(poolID string) (FoundBlocks, error) {
+// Blocks still waiting on the chain, plus confirmed blocks found after
+// reorgSince that a reorg could still take back
+func (r *FoundRepository) BlocksToSettle(poolID string, reorgSince time.Time) (FoundBlocks, error) {
 	query := `SELECT id, poolid, type, chain, blockheight, networkdifficulty, status,
 					confirmationprogress, effort, transactionconfirmationdata,
 					miner, reward, source, hash, created
-		 		FROM blocks WHERE poolid = $1 AND status = $2`
+		 		FROM blocks WHERE poolid = $1
+				AND (status = ANY($2) OR (status = $3 AND created >= $4))
+				ORDER BY created`
 
 	stmt, err := r.DB.Prepare(query)
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := stmt.Query(poolID, StatusPending)
+	waiting := pq.Array([]string{StatusSubmitted, StatusPending})
+	rows, err := stmt.Query(poolID, waiting, StatusConfirmed, reorgSince)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,172 @@
+package persistence
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+)
+
+// One balance change a confirmed block pays for
+type BlockCredit struct {
+	Address string
+	Usage   string
+	Amount  float64
+}
+
+const reversalTag = "reversal"
+
+// Every balance change made for a block carries its tag, so a reorg can
+// find and reverse them
+func blockTag(blockID uint) string {
+	return "block:" + strconv.FormatUint(uint64(blockID), 10)
+}
+
+// Posts the block's credits and saves it confirmed in one transaction.
+// Either the block is confirmed and credited, or neither happened.
+func (r *BalanceRepository) CreditBlock(block Found, credits []BlockCredit) error {
+	txn, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	tags := []string{blockTag(block.ID)}
+	for _, credit := range credits {
+		err = addAmount(txn, block.PoolID, block.Chain, credit.Address, credit.Usage, credit.Amount, tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = updateBlock(txn, block)
+	if err != nil {
+		return err
+	}
+
+	return txn.Commit()
+}
+
+// Posts a compensating change for whatever each address still holds from
+// the block and saves its new status in one transaction.  Running it again
+// finds nothing left to reverse.
+func (r *BalanceRepository) RevertBlock(block Found) error {
+	txn, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	tag := blockTag(block.ID)
+	credits, err := blockCredits(txn, block.PoolID, block.Chain, tag)
+	if err != nil {
+		return err
+	}
+
+	usage := "Reversed reward for block %v, it left the chain"
+	usage = fmt.Sprintf(usage, block.BlockHeight)
+	for _, credit := range credits {
+		err = addAmount(txn, block.PoolID, block.Chain, credit.Address, usage, -credit.Amount, []string{tag, reversalTag})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = updateBlock(txn, block)
+	if err != nil {
+		return err
+	}
+
+	return txn.Commit()
+}
+
+// What each address holds from a block after any earlier reversals
+func blockCredits(txn *sql.Tx, poolID, chain, tag string) ([]BlockCredit, error) {
+	query := `SELECT address, SUM(amount) FROM balance_changes
+				WHERE poolid = $1 AND chain = $2 AND tags @> ARRAY[$3::text]
+				GROUP BY address
+				HAVING SUM(amount) <> 0`
+
+	rows, err := txn.Query(query, poolID, chain, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var credits []BlockCredit
+	for rows.Next() {
+		var credit BlockCredit
+		err = rows.Scan(&credit.Address, &credit.Amount)
+		if err != nil {
+			return nil, err
+		}
+		credits = append(credits, credit)
+	}
+
+	return credits, rows.Err()
+}
+
+// Anything that makes balances untrustworthy to pay from: balances that
+// aren't the sum of their changes, and blocks off the chain still holding
+// credits.  Empty when the ledger is consistent.
+func (r *BalanceRepository) LedgerDiscrepancies(poolID string) ([]string, error) {
+	query := `SELECT b.chain, b.address, b.amount, COALESCE(c.total, 0)
+				FROM balances b
+				LEFT JOIN (
+					SELECT chain, address, SUM(amount) AS total
+					FROM balance_changes WHERE poolid = $1
+					GROUP BY chain, address
+				) c ON c.chain = b.chain AND c.address = b.address
+				WHERE b.poolid = $1 AND b.amount <> COALESCE(c.total, 0)`
+
+	rows, err := r.DB.Query(query, poolID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var discrepancies []string
+	for rows.Next() {
+		var chain, address string
+		var balance, changes float64
+		err = rows.Scan(&chain, &address, &balance, &changes)
+		if err != nil {
+			return nil, err
+		}
+		m := "%v balance of %v is %v, its changes add up to %v"
+		discrepancies = append(discrepancies, fmt.Sprintf(m, chain, address, balance, changes))
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT b.chain, b.blockheight, b.status, SUM(c.amount)
+				FROM blocks b
+				JOIN balance_changes c ON c.poolid = b.poolid AND c.chain = b.chain
+					AND c.tags @> ARRAY['block:' || b.id::text]
+				WHERE b.poolid = $1 AND b.status = ANY($2)
+				GROUP BY b.id, b.chain, b.blockheight, b.status
+				HAVING SUM(c.amount) <> 0`
+
+	rows, err = r.DB.Query(query, poolID, pq.Array([]string{StatusOrphaned, StatusReorged}))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var chain, status string
+		var height uint
+		var credited float64
+		err = rows.Scan(&chain, &height, &status, &credited)
+		if err != nil {
+			return nil, err
+		}
+		m := "%v block %v is %v but still credits %v"
+		discrepancies = append(discrepancies, fmt.Sprintf(m, chain, height, status, credited))
+	}
+
+	return discrepancies, rows.Err()
+}
@@ -0,0 +1,242 @@
+package persistence
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A DSN for a Postgres database the tests may create and drop schemas in.
+// Tests that need the database skip without it.
+const testDatabaseVariable = "DOGEPOOL_TEST_DATABASE"
+
+// A fresh schema with the pool's tables, dropped when the test ends
+func testDatabase(t *testing.T) *sql.DB {
+	dsn := os.Getenv(testDatabaseVariable)
+	if dsn == "" {
+		t.Skip(testDatabaseVariable + " isn't set")
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("dogepool_test_%d", time.Now().UnixNano())
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	if err != nil {
+		admin.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+
+	separator := " "
+	if strings.Contains(dsn, "://") {
+		separator = "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+	}
+	db, err := sql.Open("postgres", dsn+separator+"search_path="+schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, script := range []string{"2-schema.sql", "4-node-divergences.sql", "5-payment-fees.sql"} {
+		contents, err := os.ReadFile(filepath.Join("schema", script))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The test's user owns its schema, it needn't be the pool's role
+		statements := strings.ReplaceAll(string(contents), "SET ROLE mergedmining;", "")
+		_, err = db.Exec(statements)
+		if err != nil {
+			t.Fatalf("%v: %v", script, err)
+		}
+	}
+
+	return db
+}
+
+func insertBlock(t *testing.T, db *sql.DB, height uint) Found {
+	block := Found{
+		PoolID:                      "test",
+		Chain:                       "dogecoin",
+		BlockHeight:                 height,
+		Status:                      StatusSubmitted,
+		Type:                        "aux1",
+		TransactionConfirmationData: fmt.Sprintf("%064x", height),
+		Miner:                       "miner",
+		Hash:                        fmt.Sprintf("%064x", height),
+		Created:                     time.Now(),
+	}
+	blocks := FoundRepository{db}
+	err := blocks.Insert(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.QueryRow("SELECT id FROM blocks WHERE poolid = $1 AND chain = $2 AND blockheight = $3", block.PoolID, block.Chain, height).Scan(&block.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return block
+}
+
+func expectBalances(t *testing.T, balances BalanceRepository, want map[string]float64) {
+	t.Helper()
+	for address, amount := range want {
+		balance, err := balances.GetBalance("test", "dogecoin", address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balance == nil || *balance != amount {
+			t.Errorf("%v holds %v, want %v", address, balance, amount)
+		}
+	}
+}
+
+func expectDiscrepancies(t *testing.T, balances BalanceRepository, want int) []string {
+	t.Helper()
+	discrepancies, err := balances.LedgerDiscrepancies("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(discrepancies) != want {
+		t.Errorf("got discrepancies %q, want %v", discrepancies, want)
+	}
+	return discrepancies
+}
+
+var testCredits = []BlockCredit{
+	{Address: "miner", Usage: "Reward for block 100", Amount: 69.3},
+	{Address: "operator", Usage: "Reward for block 100", Amount: 0.7},
+}
+
+func TestReorgReversesConfirmedCredits(t *testing.T) {
+	db := testDatabase(t)
+	balances := BalanceRepository{db}
+	blocks := FoundRepository{db}
+	err := balances.AddAmount("test", "dogecoin", "miner", "Reward for block 99", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := insertBlock(t, db, 100)
+	block.Status = StatusConfirmed
+	block.Reward = 70
+	err = balances.CreditBlock(block, testCredits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBalances(t, balances, map[string]float64{"miner": 74.3, "operator": 0.7})
+	expectDiscrepancies(t, balances, 0)
+
+	// Off the chain but not yet reversed, payouts must not trust the balances
+	block.Status = StatusReorged
+	err = blocks.Update(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	discrepancies := expectDiscrepancies(t, balances, 1)
+	if len(discrepancies) == 1 && !strings.Contains(discrepancies[0], "block 100 is reorged") {
+		t.Errorf("got %q, want the reorged block named", discrepancies[0])
+	}
+
+	err = balances.RevertBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBalances(t, balances, map[string]float64{"miner": 5, "operator": 0})
+	expectDiscrepancies(t, balances, 0)
+
+	var status string
+	err = db.QueryRow("SELECT status FROM blocks WHERE id = $1", block.ID).Scan(&status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != StatusReorged {
+		t.Errorf("block saved %v, want %v", status, StatusReorged)
+	}
+}
+
+func TestRevertingTwiceReversesOnce(t *testing.T) {
+	db := testDatabase(t)
+	balances := BalanceRepository{db}
+
+	block := insertBlock(t, db, 100)
+	block.Status = StatusConfirmed
+	err := balances.CreditBlock(block, testCredits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block.Status = StatusReorged
+	for i := 0; i < 2; i++ {
+		err = balances.RevertBlock(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectBalances(t, balances, map[string]float64{"miner": 0, "operator": 0})
+	expectDiscrepancies(t, balances, 0)
+
+	var reversals int
+	err = db.QueryRow("SELECT COUNT(*) FROM balance_changes WHERE tags @> ARRAY[$1::text]", reversalTag).Scan(&reversals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reversals != len(testCredits) {
+		t.Errorf("%v reversing changes, want one per credit", reversals)
+	}
+}
+
+func TestCreditBlockIsAllOrNothing(t *testing.T) {
+	db := testDatabase(t)
+	balances := BalanceRepository{db}
+
+	// No such row to confirm, so none of the credits may land
+	block := Found{ID: 404, PoolID: "test", Chain: "dogecoin", BlockHeight: 100, Status: StatusConfirmed}
+	err := balances.CreditBlock(block, testCredits)
+	if err == nil {
+		t.Fatal("credited a block that was never recorded")
+	}
+
+	balance, err := balances.GetBalance("test", "dogecoin", "miner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != nil {
+		t.Errorf("miner holds %v from a failed credit", *balance)
+	}
+}
+
+func TestBalanceIsTheSumOfItsChanges(t *testing.T) {
+	db := testDatabase(t)
+	balances := BalanceRepository{db}
+
+	for _, amount := range []float64{1.5, 2.25, -0.75} {
+		err := balances.AddAmount("test", "dogecoin", "miner", "change", amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectBalances(t, balances, map[string]float64{"miner": 3})
+	expectDiscrepancies(t, balances, 0)
+
+	// A balance moved without its change
+	_, err := db.Exec("UPDATE balances SET amount = amount + 1 WHERE address = 'miner'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	discrepancies := expectDiscrepancies(t, balances, 1)
+	if len(discrepancies) == 1 && !strings.Contains(discrepancies[0], "balance of miner is 4") {
+		t.Errorf("got %q, want the miner's balance named", discrepancies[0])
+	}
+}
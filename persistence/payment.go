@@ -13,6 +13,7 @@ type Payment struct {
 	Chain                       string
 	Address                     string
 	Amount                      float64
+	Fee                         float64 // Taken from the recipient's balance towards the transaction fee
 	TransactionConfirmationData string
 	Created                     time.Time
 }
@@ -22,15 +23,15 @@ type PaymentRepository struct {
 }
 
 func (r *PaymentRepository) Insert(payment Payment) error {
-	query := "INSERT INTO payments(poolid, chain, address, amount, transactionconfirmationdata, created) "
-	query = query + "VALUES($1, $2, $3, $4, $5, $6)"
+	query := "INSERT INTO payments(poolid, chain, address, amount, fee, transactionconfirmationdata, created) "
+	query = query + "VALUES($1, $2, $3, $4, $5, $6, $7)"
 
 	stmt, err := r.DB.Prepare(query)
 	if err != nil {
 		return err
 	}
 
-	_, err = stmt.Exec(&payment.PoolID, &payment.Chain, &payment.Address, &payment.Amount,
+	_, err = stmt.Exec(&payment.PoolID, &payment.Chain, &payment.Address, &payment.Amount, &payment.Fee,
 		&payment.TransactionConfirmationData, &payment.Created)
 	return err
 }
@@ -41,14 +42,14 @@ func (r *PaymentRepository) InsertBatch(payments []Payment) error {
 		return err
 	}
 
-	fields := pq.CopyIn("poolid", "chain", "address", "amount", "transactionconfirmationdata", "created")
+	fields := pq.CopyIn("payments", "poolid", "chain", "address", "amount", "fee", "transactionconfirmationdata", "created")
 	stmt, err := txn.Prepare(fields)
 	if err != nil {
 		return err
 	}
 
 	for _, payment := range payments {
-		_, err = stmt.Exec(payment.PoolID, payment.Chain, payment.Address, payment.Amount,
+		_, err = stmt.Exec(payment.PoolID, payment.Chain, payment.Address, payment.Amount, payment.Fee,
 			payment.TransactionConfirmationData, payment.Created)
 		if err != nil {
 			return err
@@ -69,7 +70,7 @@ func (r *PaymentRepository) InsertBatch(payments []Payment) error {
 }
 
 func (r *PaymentRepository) PagePayments(poolID, miner string, page, pageSize int) ([]Payment, error) {
-	query := "SELECT poolid, chain, address, amount, transactionconfirmationdata, created FROM payments WHERE poolid = $1 "
+	query := "SELECT poolid, chain, address, amount, fee, transactionconfirmationdata, created FROM payments WHERE poolid = $1 "
 	if miner != "" {
 		query = query + " AND address = $4 "
 	}
@@ -94,7 +95,7 @@ func (r *PaymentRepository) PagePayments(poolID, miner string, page, pageSize in
 	for rows.Next() {
 		var payment Payment
 
-		err = rows.Scan(&payment.PoolID, &payment.Chain, &payment.Address, &payment.Amount,
+		err = rows.Scan(&payment.PoolID, &payment.Chain, &payment.Address, &payment.Amount, &payment.Fee,
 			&payment.TransactionConfirmationData, &payment.Created)
 		if err != nil {
 			return payments, err
@@ -205,7 +206,7 @@ func (r *PaymentRepository) MinerPaymentsByDayCount(poolID, miner string) (Payme
 }
 
 func (r *PaymentRepository) MinerLastPayments(poolID, miner string) (map[string]Payment, error) {
-	query := `SELECT poolid, chain, address, amount, transactionconfirmationdata, created
+	query := `SELECT poolid, chain, address, amount, fee, transactionconfirmationdata, created
 
 			FROM payments
 
@@ -229,7 +230,7 @@ func (r *PaymentRepository) MinerLastPayments(poolID, miner string) (map[string]
 	for rows.Next() {
 		var payment Payment
 		err = rows.Scan(&payment.PoolID, &payment.Chain, &payment.Address,
-			&payment.Amount, &payment.TransactionConfirmationData, &payment.Created)
+			&payment.Amount, &payment.Fee, &payment.TransactionConfirmationData, &payment.Created)
 		if err != nil {
 			return nil, err
 		}
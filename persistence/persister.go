@@ -8,12 +8,13 @@ import (
 )
 
 var (
-	Balances BalanceRepository
-	Blocks   FoundRepository
-	Miners   MinerRepository
-	Payments PaymentRepository
-	Pool     PoolRepository
-	Shares   ShareRepository
+	Balances    BalanceRepository
+	Blocks      FoundRepository
+	Divergences DivergenceRepository
+	Miners      MinerRepository
+	Payments    PaymentRepository
+	Pool        PoolRepository
+	Shares      ShareRepository
 )
 
 func MakePersister(configuration *config.Config) error {
@@ -29,6 +30,7 @@ func MakePersister(configuration *config.Config) error {
 
 	Balances = BalanceRepository{db}
 	Blocks = FoundRepository{db}
+	Divergences = DivergenceRepository{db}
 	Miners = MinerRepository{db}
 	Payments = PaymentRepository{db}
 	Pool = PoolRepository{db}
@@ -0,0 +1,20 @@
+SET ROLE mergedmining;
+
+/* A node leaving or rejoining the tip its peers agree on */
+CREATE TABLE node_divergences
+(
+	id BIGSERIAL NOT NULL PRIMARY KEY,
+	poolid TEXT NOT NULL,
+	chain TEXT NOT NULL,
+	node TEXT NOT NULL,
+	active BOOLEAN NOT NULL,
+	kind TEXT NOT NULL,
+	nodeheight BIGINT NOT NULL,
+	nodetip TEXT NOT NULL,
+	majorityheight BIGINT NOT NULL,
+	majoritytip TEXT NOT NULL,
+	since TIMESTAMPTZ NOT NULL,
+	created TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IDX_NODE_DIVERGENCES_POOL_CHAIN_CREATED on node_divergences(poolid, chain, created desc);
@@ -0,0 +1,4 @@
+SET ROLE mergedmining;
+
+/* What each payment's recipient gave up towards the payout transaction's fee */
+ALTER TABLE payments ADD COLUMN fee decimal(28,8) NOT NULL DEFAULT 0;
@@ -8,6 +8,7 @@ DROP TABLE balance_changes;
 DROP TABLE miner_settings;
 DROP TABLE poolstats;
 DROP TABLE minerstats;
+DROP TABLE node_divergences;
 
 CREATE TABLE shares
 (
@@ -86,6 +87,7 @@ CREATE TABLE payments
 	chain TEXT NOT NULL,
 	address TEXT NOT NULL,
 	amount decimal(28,8) NOT NULL,
+	fee decimal(28,8) NOT NULL DEFAULT 0,
 	transactionconfirmationdata TEXT NOT NULL,
 	created TIMESTAMPTZ NOT NULL
 );
@@ -116,3 +118,21 @@ CREATE TABLE minerstats
 	sharespersecond DOUBLE PRECISION NOT NULL DEFAULT 0,
 	created TIMESTAMPTZ NOT NULL
 );
+
+CREATE TABLE node_divergences
+(
+	id BIGSERIAL NOT NULL PRIMARY KEY,
+	poolid TEXT NOT NULL,
+	chain TEXT NOT NULL,
+	node TEXT NOT NULL,
+	active BOOLEAN NOT NULL,
+	kind TEXT NOT NULL,
+	nodeheight BIGINT NOT NULL,
+	nodetip TEXT NOT NULL,
+	majorityheight BIGINT NOT NULL,
+	majoritytip TEXT NOT NULL,
+	since TIMESTAMPTZ NOT NULL,
+	created TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IDX_NODE_DIVERGENCES_POOL_CHAIN_CREATED on node_divergences(poolid, chain, created desc);
@@ -6,12 +6,12 @@ import (
 	"time"
 )
 
-func UpdateStatsOnInterval(poolID string, hashRateCalculationWindow, interval time.Duration) {
+func UpdateStatsOnInterval(poolID string, hashrateConstant float64, hashRateCalculationWindow, interval time.Duration) {
 	var err error
 	for {
 		time.Sleep(interval)
 
-		err = insertManyNewMinerStatsAndOnePoolStat(poolID, hashRateCalculationWindow)
+		err = insertManyNewMinerStatsAndOnePoolStat(poolID, hashrateConstant, hashRateCalculationWindow)
 		if err != nil {
 			log.Println(err)
 		} else {
@@ -20,7 +20,7 @@ func UpdateStatsOnInterval(poolID string, hashRateCalculationWindow, interval ti
 	}
 }
 
-func insertManyNewMinerStatsAndOnePoolStat(poolID string, hashRateCalculationWindow time.Duration) error {
+func insertManyNewMinerStatsAndOnePoolStat(poolID string, hashrateConstant float64, hashRateCalculationWindow time.Duration) error {
 	now := time.Now()
 	timeFrom := time.Now().Add(-hashRateCalculationWindow)
 
@@ -30,17 +30,17 @@ func insertManyNewMinerStatsAndOnePoolStat(poolID string, hashRateCalculationWin
 	}
 	miners := workers.GroupByMiner()
 
-	err = makeNewPoolStat(poolID, hashRateCalculationWindow, workers, uint(len(miners)), now)
+	err = makeNewPoolStat(poolID, hashrateConstant, hashRateCalculationWindow, workers, uint(len(miners)), now)
 	if err != nil {
 		log.Println(err)
 	}
 
-	makeMinerStats(poolID, miners, now, timeFrom, hashRateCalculationWindow)
+	makeMinerStats(poolID, hashrateConstant, miners, now, timeFrom, hashRateCalculationWindow)
 
 	return nil
 }
 
-func makeNewPoolStat(poolID string, hashRateCalculationWindow time.Duration, workers MinerWorkerHashAccumulationResultSet, minerCount uint, now time.Time) error {
+func makeNewPoolStat(poolID string, hashrateConstant float64, hashRateCalculationWindow time.Duration, workers MinerWorkerHashAccumulationResultSet, minerCount uint, now time.Time) error {
 	poolStat := PoolStat{
 		PoolID:  poolID,
 		Created: now,
@@ -49,7 +49,7 @@ func makeNewPoolStat(poolID string, hashRateCalculationWindow time.Duration, wor
 	if workers != nil {
 		poolStat.ConnectedMiners = minerCount
 		poolStat.ConnectedWorkers = uint(len(workers))
-		poolStat.PoolHashrate, poolStat.SharesPerSecond = getHashrateAndSharesPerSecond(workers, hashRateCalculationWindow)
+		poolStat.PoolHashrate, poolStat.SharesPerSecond = getHashrateAndSharesPerSecond(workers, hashrateConstant, hashRateCalculationWindow)
 		poolStat.PoolHashrate, poolStat.SharesPerSecond = math.Floor(poolStat.PoolHashrate), roundToThreeDigits(poolStat.SharesPerSecond)
 	} else {
 		poolStat.ConnectedMiners, poolStat.ConnectedWorkers, poolStat.PoolHashrate, poolStat.SharesPerSecond = 0, 0, 0, 0
@@ -58,7 +58,7 @@ func makeNewPoolStat(poolID string, hashRateCalculationWindow time.Duration, wor
 	return Pool.InsertPoolStat(poolStat)
 }
 
-func makeMinerStats(poolID string, miners map[string][]MinerWorkerHashAccumulation, now, timeFrom time.Time, hashRateCalculationWindow time.Duration) int {
+func makeMinerStats(poolID string, hashrateConstant float64, miners map[string][]MinerWorkerHashAccumulation, now, timeFrom time.Time, hashRateCalculationWindow time.Duration) int {
 	minerStat := MinerStat{
 		PoolID:  poolID,
 		Created: now,
@@ -74,7 +74,7 @@ func makeMinerStats(poolID string, miners map[string][]MinerWorkerHashAccumulati
 		for _, worker := range workers {
 			minerStat.Miner = miner
 			minerStat.Worker = worker.Worker
-			minerStat.Hashrate = math.Floor(hashrateFromShares(worker.SumDifficulty, adjustedWindow))
+			minerStat.Hashrate = math.Floor(hashrateFromShares(worker.SumDifficulty, adjustedWindow, hashrateConstant))
 
 			sharesPerSecond := float64(worker.ShareCount) / adjustedWindow
 			minerStat.SharesPerSecond = roundToThreeDigits(sharesPerSecond)
@@ -113,13 +113,13 @@ func adjustHashWindow(window StatsCalcWindow, hashRateCalculationWindow time.Dur
 	return minerHashTimeFrame
 }
 
-func getHashrateAndSharesPerSecond(hashSummaries MinerWorkerHashAccumulationResultSet, hashRateCalculationWindow time.Duration) (float64, float64) {
+func getHashrateAndSharesPerSecond(hashSummaries MinerWorkerHashAccumulationResultSet, hashrateConstant float64, hashRateCalculationWindow time.Duration) (float64, float64) {
 	sumShares, sharesPerSecond := float64(0), float64(0)
 	for _, summary := range hashSummaries {
 		sumShares += summary.SumDifficulty
 		sharesPerSecond += float64(summary.ShareCount)
 	}
-	hashRate := hashrateFromShares(sumShares, hashRateCalculationWindow.Seconds())
+	hashRate := hashrateFromShares(sumShares, hashRateCalculationWindow.Seconds(), hashrateConstant)
 
 	return math.Floor(hashRate), sharesPerSecond / float64(hashRateCalculationWindow)
 }
@@ -150,10 +150,9 @@ func getWindowDifferences(now, timeFrom time.Time, workers []MinerWorkerHashAccu
 	return window
 }
 
-func hashrateFromShares(shareSum, interval float64) float64 {
-	// TODO move to coin package
-	bitcoinConstantMultiplier := math.Pow(2, 32)
-	hashrate := shareSum * bitcoinConstantMultiplier / interval
+// The hashrate constant comes from the primary chain's algorithm
+func hashrateFromShares(shareSum, interval, hashrateConstant float64) float64 {
+	hashrate := shareSum * hashrateConstant / interval
 
 	return hashrate
 }
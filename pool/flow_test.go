@@ -0,0 +1,221 @@
+package pool
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"designs.capital/dogepool/config"
+	"designs.capital/dogepool/persistence"
+	"designs.capital/dogepool/rpc"
+)
+
+// A regtest litecoin node's side of finding a block, from the template to
+// the wallet paying it out.  payouts/flow_test.go settles and pays the
+// block these tests mine.
+const flowFixtures = "testdata/block-flow.json"
+
+const flowMiner = "myVAEip8wkzC956Goo5bMN5RipDVVyt7sx"
+
+// Keeps every row the pool writes instead of needing Postgres
+type insertRecorder struct {
+	sync.Mutex
+	rows [][]driver.Value
+}
+
+var recordedInserts = &insertRecorder{}
+
+func init() {
+	sql.Register("recorded-inserts", recordedInserts)
+}
+
+func (r *insertRecorder) Open(string) (driver.Conn, error)    { return r, nil }
+func (r *insertRecorder) Prepare(string) (driver.Stmt, error) { return r, nil }
+func (r *insertRecorder) Begin() (driver.Tx, error) {
+	return nil, errors.New("no transactions")
+}
+func (r *insertRecorder) Close() error  { return nil }
+func (r *insertRecorder) NumInput() int { return -1 }
+func (r *insertRecorder) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("no queries")
+}
+
+func (r *insertRecorder) Exec(args []driver.Value) (driver.Result, error) {
+	r.Lock()
+	defer r.Unlock()
+	r.rows = append(r.rows, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (r *insertRecorder) take() [][]driver.Value {
+	r.Lock()
+	defer r.Unlock()
+	rows := r.rows
+	r.rows = nil
+	return rows
+}
+
+// A litecoin pool on one fake node serving the flow's fixtures
+func flowPool(t *testing.T) (*PoolServer, *rpc.FakeNode) {
+	node, err := rpc.NewFakeNode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { node.Close() })
+	fixtures, err := rpc.LoadFixtures(flowFixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.AddFixtures(fixtures...)
+
+	contents, err := json.Marshal(map[string]any{
+		"pool_name":       "flow",
+		"block_signature": "/dogepool/",
+		"pool_difficulty": 0.001,
+		"blockchains": map[string]any{"litecoin": []map[string]string{{
+			"name":      "regtest",
+			"rpc_url":   node.URL,
+			"timeout":   "5s",
+			"reward_to": flowMiner,
+		}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var configuration config.Config
+	err = json.Unmarshal(contents, &configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configuration.BlockChainOrder = []string{"litecoin"}
+
+	db, err := sql.Open("recorded-inserts", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := persistence.Blocks
+	persistence.Blocks = persistence.FoundRepository{DB: db}
+	t.Cleanup(func() {
+		persistence.Blocks = blocks
+		recordedInserts.take()
+	})
+
+	nodes := make([]rpc.Config, 0)
+	for _, nodeConfig := range configuration.BlockchainNodes["litecoin"] {
+		nodes = append(nodes, rpc.Config{Name: nodeConfig.Name, URL: nodeConfig.RPC_URL, Timeout: nodeConfig.Timeout})
+	}
+	managers := map[string]*rpc.Manager{"litecoin": rpc.MakeRPCManager("litecoin", nodes, "1m", "1m")}
+
+	pool := NewServer(&configuration, managers)
+	pool.loadBlockchainNodes()
+	pool.validation = &validationPool{candidates: make(chan *blockCandidate, 1)}
+	return pool, node
+}
+
+// Submits nonces from a miner's session until one hashes to a block
+func mineCandidate(t *testing.T, pool *PoolServer) *blockCandidate {
+	client := &stratumClient{ip: "127.0.0.1", login: flowMiner + ".rig", extranonce1: "00000001", userAgent: "flow"}
+	templates, _, err := pool.clientJob(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonceTime := fmt.Sprintf("%08x", templates.GetPrimary().Template.CurrentTime)
+
+	for nonce := 0; nonce < 64; nonce++ {
+		err = pool.processShare(&shareSubmission{
+			client:     client,
+			templates:  templates,
+			extranonce: client.extranonce1 + "00000000",
+			nonce:      fmt.Sprintf("%08x", nonce),
+			nonceTime:  nonceTime,
+		})
+		if err != nil {
+			continue
+		}
+		select {
+		case candidate := <-pool.validation.candidates:
+			return candidate
+		default:
+		}
+	}
+	t.Fatal("no block in 64 nonces against a regtest target")
+	return nil
+}
+
+func flowFixture(t *testing.T, method string) rpc.Fixture {
+	fixtures, err := rpc.LoadFixtures(flowFixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fixture := range fixtures {
+		if fixture.Method == method {
+			return fixture
+		}
+	}
+	t.Fatalf("%v has no %v fixture", flowFixtures, method)
+	return rpc.Fixture{}
+}
+
+func TestFoundBlockIsSubmittedAndRecorded(t *testing.T) {
+	pool, node := flowPool(t)
+	err := pool.fetchRpcBlockTemplatesAndCacheWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidate := mineCandidate(t, pool)
+	pool.submitCandidate(candidate)
+
+	submitted := node.Calls("submitblock")
+	if len(submitted) != 1 {
+		t.Fatalf("%v submitblock calls, want 1", len(submitted))
+	}
+	block, err := candidate.primary.Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(submitted[0].Params) != `["`+block+`"]` {
+		t.Errorf("submitted %.80s..., want the candidate's block", submitted[0].Params)
+	}
+
+	rows := recordedInserts.take()
+	if len(rows) != 1 {
+		t.Fatalf("%v blocks recorded, want 1", len(rows))
+	}
+	// Columns in FoundRepository.Insert's order
+	found := rows[0]
+	if found[1] != "litecoin" || found[2] != int64(101) || found[4] != persistence.StatusSubmitted || found[7] != flowMiner {
+		t.Errorf("recorded chain %v height %v status %v miner %v", found[1], found[2], found[4], found[7])
+	}
+
+	// The node the payouts ask later knows the block by this hash and coinbase
+	var hash []string
+	getBlock := flowFixture(t, "getblock")
+	err = json.Unmarshal(getBlock.Params, &hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found[12] != hash[0] {
+		t.Errorf("recorded hash %v, the node's block is %v", found[12], hash[0])
+	}
+	var remote rpc.GetBlockReply
+	err = json.Unmarshal(getBlock.Result, &remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coinbase, err := hex.DecodeString(remote.Transactions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, j := 0, len(coinbase)-1; i < j; i, j = i+1, j-1 {
+		coinbase[i], coinbase[j] = coinbase[j], coinbase[i]
+	}
+	if found[6] != hex.EncodeToString(coinbase) {
+		t.Errorf("recorded coinbase %v, the node's is %v reversed", found[6], remote.Transactions[0])
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"log"
 	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,6 +25,9 @@ type stratumClient struct {
 	sessionID     string
 	connection    net.Conn
 	streamEncoder *json.Encoder
+	writeLock     sync.Mutex // Validation workers answer submits off the connection goroutine
+
+	inflightSubmits atomic.Int32
 }
 
 func (pool *PoolServer) listenForConnections() {
@@ -122,6 +127,8 @@ func (pool *PoolServer) handleStratumConnection(client *stratumClient) error {
 }
 
 func sendPacket(packet any, client *stratumClient) error {
+	client.writeLock.Lock()
+	defer client.writeLock.Unlock()
 	return client.streamEncoder.Encode(packet)
 }
 
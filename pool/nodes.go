@@ -6,10 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"designs.capital/dogepool/alerts"
 	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/chainevents"
 	"designs.capital/dogepool/rpc"
-	"github.com/go-zeromq/zmq4"
 )
 
 type BlockChainNodesMap map[string]blockChainNode // "blockChainName" => activeNode
@@ -17,89 +19,218 @@ type BlockChainNodesMap map[string]blockChainNode // "blockChainName" => activeN
 type blockChainNode struct {
 	NotifyURL          string
 	RPC                *rpc.RPCClient
+	Index              int // Position in the chain's node config
 	ChainName          string
 	Network            string
-	RewardPubScriptKey string // TODO - this is very bitcoin specific.  Abstract to interface.
+	RewardPubScriptKey string
+	CoinbaseRecipients []bitcoin.CoinbaseRecipient
 	RewardTo           string
 	NetworkDifficulty  float64
 }
 
+func (p *PoolServer) activeNode(blockChainName string) blockChainNode {
+	p.nodesLock.RLock()
+	defer p.nodesLock.RUnlock()
+	return p.activeNodes[blockChainName]
+}
+
 func (p *PoolServer) GetPrimaryNode() blockChainNode {
-	return p.activeNodes[p.config.GetPrimary()]
+	return p.activeNode(p.config.GetPrimary())
 }
 
 func (p *PoolServer) GetAux1Node() blockChainNode {
-	return p.activeNodes[p.config.GetAux1()]
+	return p.activeNode(p.config.GetAux1())
 }
 
-type hashblockCounterMap map[string]uint32 // "blockChainName" => hashblock msg counter
-
 func (pool *PoolServer) loadBlockchainNodes() {
 	pool.activeNodes = make(BlockChainNodesMap)
 	for _, blockChainName := range pool.config.BlockChainOrder {
-		rpcManager, exists := pool.rpcManagers[blockChainName]
-		if !exists {
-			panic("Blockchain not found for: " + blockChainName)
-		}
-		rpcClient := rpcManager.GetActiveClient()
-		nodeConfig := pool.config.BlockchainNodes[blockChainName][rpcManager.GetIndex()]
-
-		chainInfo, err := rpcClient.GetBlockChainInfo()
+		node, err := pool.loadBlockchainNode(blockChainName)
 		logFatalOnError(err)
+		pool.activeNodes[blockChainName] = node
+	}
+}
 
-		address, err := rpcClient.ValidateAddress(nodeConfig.RewardTo)
-		logFatalOnError(err)
+func (pool *PoolServer) loadBlockchainNode(blockChainName string) (blockChainNode, error) {
+	rpcManager, exists := pool.rpcManagers[blockChainName]
+	if !exists {
+		panic("Blockchain not found for: " + blockChainName)
+	}
+	rpcClient, index := rpcManager.Active()
+	nodeConfig := pool.config.BlockchainNodes[blockChainName][index]
+
+	chainInfo, err := rpcClient.GetBlockChainInfo(context.Background())
+	if err != nil {
+		return blockChainNode{}, err
+	}
+
+	chain := bitcoin.GetChain(blockChainName)
+	rewardPubScriptKey, err := bitcoin.AddressToScriptPubKey(chain, chainInfo.Chain, nodeConfig.RewardTo)
+	if err != nil {
+		return blockChainNode{}, err
+	}
+
+	coinbaseRecipients, err := pool.coinbaseRecipients(blockChainName, chainInfo.Chain, nodeConfig.RewardTo)
+	if err != nil {
+		return blockChainNode{}, err
+	}
+
+	node := blockChainNode{
+		NotifyURL:          nodeConfig.NotifyURL,
+		RPC:                rpcClient,
+		Index:              index,
+		Network:            chainInfo.Chain,
+		RewardPubScriptKey: rewardPubScriptKey,
+		CoinbaseRecipients: coinbaseRecipients,
+		RewardTo:           nodeConfig.RewardTo,
+		NetworkDifficulty:  chainInfo.NetworkDifficulty,
+		ChainName:          blockChainName,
+	}
+	return node, nil
+}
 
-		// TODO this is wayy to bitcoin specific.  Move this to the coin package.
-		rewardPubScriptKey := address.ScriptPubKey
-
-		newNode := blockChainNode{
-			NotifyURL:          nodeConfig.NotifyURL,
-			RPC:                rpcClient,
-			Network:            chainInfo.Chain,
-			RewardPubScriptKey: rewardPubScriptKey,
-			RewardTo:           nodeConfig.RewardTo,
-			NetworkDifficulty:  chainInfo.NetworkDifficulty,
-			ChainName:          blockChainName,
+// The RPC managers move between nodes on their own, pick up their choice
+// before asking for work
+func (pool *PoolServer) followActiveRPCs() {
+	for _, blockChainName := range pool.config.BlockChainOrder {
+		current := pool.activeNode(blockChainName)
+		if pool.rpcManagers[blockChainName].GetIndex() == current.Index {
+			continue
 		}
-		pool.activeNodes[blockChainName] = newNode
+
+		node, err := pool.loadBlockchainNode(blockChainName)
+		if err != nil {
+			log.Printf("Staying on %v node %v: %v", blockChainName, current.RPC.Name, err)
+			continue
+		}
+
+		pool.nodesLock.Lock()
+		pool.activeNodes[blockChainName] = node
+		pool.nodesLock.Unlock()
+		log.Printf("🔀 Pool now using %v node %v", blockChainName, node.RPC.Name)
 	}
 }
 
 func (pool *PoolServer) listenForBlockNotifications() error {
-	notifyChannel := make(chan hashBlockResponse)
-	hashblockCounterMap := make(hashblockCounterMap)
+	notifyChannel := make(chan zmqNotification)
+	sequences := make(sequenceTracker)
 
-	for blockChainName := range pool.activeNodes {
-		subscription, err := pool.createZMQSubscriptionToHashBlock(blockChainName, notifyChannel)
+	subscriptions := make(map[string]*zmqSubscription)
+	for _, blockChainName := range pool.config.BlockChainOrder {
+		subscription, err := pool.createZMQSubscription(blockChainName, notifyChannel)
 		if err != nil {
 			return err
 		}
-		defer subscription.Close()
+		subscriptions[blockChainName] = subscription
 	}
+	defer func() {
+		for _, subscription := range subscriptions {
+			subscription.Close()
+		}
+	}()
 
-	for {
-		msg := <-notifyChannel
-		chainName := msg.blockChainName
-		prevCount := hashblockCounterMap[chainName]
-		newCount := msg.blockHashCounter
-		prevBlockHash := msg.previousBlockHash
-
-		m := "**New %v block: %v - %v**"
-		log.Printf(m, chainName, newCount, prevBlockHash)
+	// Notifications come from whichever node the pool is using, a failed
+	// dial is retried on the next poll
+	resubscribe := func(blockChainName string, force bool) {
+		current := subscriptions[blockChainName]
+		url := pool.activeNode(blockChainName).NotifyURL
+		if current != nil && current.url == url && !force {
+			return
+		}
+		if current != nil {
+			current.Close()
+			delete(subscriptions, blockChainName)
+		}
+		sequences.forget(blockChainName)
 
-		if prevCount != 0 && (prevCount+1) != newCount {
-			m = "We missed a %v block notification, previous count: %v current count: %v"
-			log.Printf(m, chainName, prevCount, newCount)
+		subscription, err := pool.createZMQSubscription(blockChainName, notifyChannel)
+		if err != nil {
+			m := "Can't subscribe to %v notifications at %v, polling for new blocks: %v"
+			alerts.Raise(alerts.Warning, blockChainName, fmt.Sprintf(m, blockChainName, url, err))
+			return
 		}
+		subscriptions[blockChainName] = subscription
+		log.Printf("📡 Subscribed to %v notifications at %v", blockChainName, url)
+	}
 
-		hashblockCounterMap[chainName] = newCount
+	// A node switch means the old node's work may be stale or on a minority fork
+	switches := make(chan string)
+	for _, blockChainName := range pool.config.BlockChainOrder {
+		go func(chainName string, switched <-chan int) {
+			for range switched {
+				switches <- chainName
+			}
+		}(blockChainName, pool.rpcManagers[blockChainName].Subscribe())
+	}
 
+	// Only the current job takes shares, so any new work is sent clean.
+	// Fetching work follows the RPC managers, ZMQ follows along.
+	lastTemplate := time.Now()
+	refreshWork := func() {
 		err := pool.fetchRpcBlockTemplatesAndCacheWork()
 		logOnError(err)
-		work, err := pool.generateWorkFromCache(true)
-		logOnError(err)
-		pool.broadcastWork(work)
+		pool.broadcastWork(true)
+		lastTemplate = time.Now()
+		for _, blockChainName := range pool.config.BlockChainOrder {
+			resubscribe(blockChainName, false)
+		}
+	}
+
+	// Catches blocks a silent or dead notifier never announced
+	poll := time.NewTicker(pool.templatePollInterval())
+	defer poll.Stop()
+
+	for {
+		var msg zmqNotification
+		select {
+		case msg = <-notifyChannel:
+		case chainName := <-switches:
+			log.Printf("Refreshing work after the %v node switch", chainName)
+			refreshWork()
+			continue
+		case <-poll.C:
+			missed := pool.missedTips()
+			for _, chainName := range missed {
+				m := "%v node %v moved to a new block without notifying us, resubscribing"
+				alerts.Raise(alerts.Warning, chainName, fmt.Sprintf(m, chainName, pool.activeNode(chainName).RPC.Name))
+				resubscribe(chainName, true)
+			}
+			if len(missed) > 0 {
+				refreshWork()
+			} else {
+				for _, blockChainName := range pool.config.BlockChainOrder {
+					resubscribe(blockChainName, false)
+				}
+			}
+			continue
+		}
+		chainName := msg.blockChainName
+
+		missed := sequences.missed(msg)
+		if missed > 0 {
+			m := "We missed %v %v %v notification(s) before %v"
+			m = fmt.Sprintf(m, missed, chainName, msg.topic, msg.sequence)
+			if msg.topic == chainevents.TopicHashBlock || msg.topic == chainevents.TopicRawBlock {
+				alerts.Raise(alerts.Warning, chainName, m)
+			} else {
+				log.Println(m)
+			}
+		}
+		pending := chainevents.Received(chainName, msg.topic, msg.sequence, missed)
+
+		switch msg.topic {
+		case chainevents.TopicHashBlock:
+			m := "**New %v block: %v - %v**"
+			log.Printf(m, chainName, msg.sequence, hex.EncodeToString(msg.body))
+			refreshWork()
+		case chainevents.TopicRawBlock:
+			pool.handleRawBlock(msg)
+		case chainevents.TopicHashTx, chainevents.TopicRawTx:
+			if pool.mempoolRefreshDue(pending, lastTemplate) {
+				log.Printf("Refreshing work after %v new %v transactions", pending, chainName)
+				refreshWork()
+			}
+		}
 	}
 }
 
@@ -110,83 +241,94 @@ func (p *PoolServer) submitBlockToChain(block bitcoin.BitcoinBlock) error {
 		return err
 	}
 
-	submit := []any{
-		any(submission),
+	node := p.GetPrimaryNode()
+	submit := func(submission string) error {
+		return node.RPC.SubmitBlock(context.Background(), submission)
 	}
-	success, err := p.GetPrimaryNode().RPC.SubmitBlock(submit)
-
-	if !success || err != nil {
-		nodeName := p.GetPrimaryNode().ChainName
-		m := "⚠️  %v primary node rejection: %v"
-		m = fmt.Sprintf(m, nodeName, err.Error())
-		return errors.New(m)
+	source, isSource := bitcoin.GetChain(node.ChainName).(bitcoin.BlockSource)
+	if isSource && source.HandlesBlocks() {
+		submit = source.SubmitBlock
 	}
+	err = submitWithRetry(func() error {
+		return submit(submission)
+	})
 
-	return nil
+	return p.handleSubmitResult(node.ChainName, "primary node rejection", err)
 }
 
 func (p *PoolServer) submitAuxBlock(primaryBlock bitcoin.BitcoinBlock, aux1Block bitcoin.AuxBlock) error {
 	auxpow := bitcoin.MakeAuxPow(primaryBlock)
-	success, err := p.GetAux1Node().RPC.SubmitAuxBlock(aux1Block.Hash, auxpow.Serialize())
-	if !success {
-		nodeName := p.GetAux1Node().ChainName
-		m := "⚠️  %v node failed to submit aux block: %v"
-		m = fmt.Sprintf(m, nodeName, err.Error())
-		return errors.New(m)
+	serialized := auxpow.Serialize()
+
+	node := p.GetAux1Node()
+	err := verifyAuxPow(serialized, aux1Block, p.GetPrimaryNode().ChainName)
+	if err != nil {
+		alerts.Raise(alerts.Critical, node.ChainName, err.Error())
+		return err
 	}
-	return err
-}
 
-type hashBlockResponse struct {
-	blockChainName    string
-	previousBlockHash string
-	blockHashCounter  uint32
-}
+	submit := node.RPC.SubmitAuxBlock
+	if bitcoin.GetChain(node.ChainName).AuxRPC().Method == bitcoin.AuxMethodGet {
+		submit = node.RPC.SubmitGetAuxBlock
+	}
+	err = submitWithRetry(func() error {
+		return submit(context.Background(), aux1Block.Hash, serialized)
+	})
 
-func (p *PoolServer) createZMQSubscriptionToHashBlock(blockChainName string, hashBlockChannel chan hashBlockResponse) (zmq4.Socket, error) {
-	sub := zmq4.NewSub(context.Background())
+	return p.handleSubmitResult(node.ChainName, "node failed to submit aux block", err)
+}
 
-	url := p.activeNodes[blockChainName].NotifyURL
-	err := sub.Dial(url)
-	if err != nil {
-		return sub, err
+// The node only answers false for a bad proof, checking it here says why
+func verifyAuxPow(serialized string, auxBlock bitcoin.AuxBlock, parentChainName string) error {
+	auxTarget := bitcoin.Target(auxBlock.Target)
+	target, ok := auxTarget.ToBig()
+	if !ok {
+		target = nil
 	}
 
-	err = sub.SetOption(zmq4.OptionSubscribe, "hashblock")
-	if err != nil {
-		return sub, err
+	report := bitcoin.VerifyAuxPow(serialized, auxBlock.Hash, uint32(auxBlock.ChainID), target, bitcoin.GetChain(parentChainName))
+	if report.Valid() {
+		return nil
 	}
 
-	logErr := func(msg zmq4.Msg, err error) zmq4.Msg {
-		if err != nil {
-			log.Println(err)
-		}
+	log.Printf("AuxPoW for aux block %v failed verification:\n%v", auxBlock.Hash, report)
+	return report.Error()
+}
 
-		return msg
-	}
+const (
+	inconclusiveSubmitRetries = 3
+	inconclusiveSubmitBackoff = 2 * time.Second
+)
 
-	go func() {
-		for {
-			msg := logErr(sub.Recv())
+// An inconclusive result means the node accepted the block but hasn't
+// connected it yet - asking again tells us where it ended up.
+func submitWithRetry(submit func() error) error {
+	err := submit()
+	for attempt := 0; attempt < inconclusiveSubmitRetries && errors.Is(err, rpc.ErrBlockInconclusive); attempt++ {
+		time.Sleep(inconclusiveSubmitBackoff)
+		err = submit()
+	}
+	return err
+}
 
-			if len(msg.Frames) > 2 {
-				var blockHashCounter uint32
-				blockHashCounter |= uint32(msg.Frames[2][0])
-				blockHashCounter |= uint32(msg.Frames[2][1]) << 8
-				blockHashCounter |= uint32(msg.Frames[2][2]) << 16
-				blockHashCounter |= uint32(msg.Frames[2][3]) << 24
+func (p *PoolServer) handleSubmitResult(chainName, context string, err error) error {
+	if err == nil {
+		return nil
+	}
 
-				hashBlockChannel <- hashBlockResponse{
-					blockChainName:    blockChainName,
-					previousBlockHash: hex.EncodeToString(msg.Frames[1]),
-					blockHashCounter:  blockHashCounter,
-				}
-			}
+	if errors.Is(err, rpc.ErrBlockDuplicate) {
+		log.Printf("%v node already has our block, counting it as found", chainName)
+		return nil
+	}
 
-		}
-	}()
+	var submitErr *rpc.SubmitBlockError
+	if errors.As(err, &submitErr) && submitErr.Structural() {
+		m := "%v rejected our block as %v - block construction is broken"
+		alerts.Raise(alerts.Critical, chainName, fmt.Sprintf(m, submitErr.Method, submitErr.Reason))
+	}
 
-	return sub, nil
+	// Wrapped so callers can still tell the rejection reason apart
+	return fmt.Errorf("⚠️  %v %v: %w", chainName, context, err)
 }
 
 func (p *PoolServer) CheckAndRecoverRPCs() error {
@@ -0,0 +1,24 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+
+	"designs.capital/dogepool/rpc"
+)
+
+func TestHandleSubmitResultKeepsTheRejection(t *testing.T) {
+	pool := &PoolServer{}
+	rejection := &rpc.SubmitBlockError{Method: "submitblock", Reason: "bad-txnmrklroot", Kind: rpc.ErrBlockStructural}
+
+	err := pool.handleSubmitResult("litecoin", "primary node rejection", rejection)
+	var submitErr *rpc.SubmitBlockError
+	if !errors.As(err, &submitErr) || !errors.Is(err, rpc.ErrBlockStructural) {
+		t.Errorf("got %v, want the node's rejection wrapped", err)
+	}
+
+	err = pool.handleSubmitResult("litecoin", "primary node rejection", &rpc.SubmitBlockError{Method: "submitblock", Reason: "duplicate", Kind: rpc.ErrBlockDuplicate})
+	if err != nil {
+		t.Errorf("got %v, want a duplicate counted as found", err)
+	}
+}
@@ -0,0 +1,303 @@
+package pool
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"designs.capital/dogepool/alerts"
+	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/chainevents"
+	"github.com/go-zeromq/zmq4"
+)
+
+type zmqNotification struct {
+	blockChainName string
+	topic          string
+	body           []byte
+	sequence       uint32
+}
+
+// Every topic but hashblock is opt in per node, hashblock alone drives new work
+func notificationTopics(configured []string) ([]string, error) {
+	topics := []string{chainevents.TopicHashBlock}
+	for _, topic := range configured {
+		switch topic {
+		case chainevents.TopicHashBlock:
+			continue
+		case chainevents.TopicRawBlock, chainevents.TopicHashTx, chainevents.TopicRawTx:
+			topics = append(topics, topic)
+		default:
+			m := "unknown zmq topic %q, expected %v, %v or %v"
+			return nil, fmt.Errorf(m, topic, chainevents.TopicRawBlock, chainevents.TopicHashTx, chainevents.TopicRawTx)
+		}
+	}
+	return topics, nil
+}
+
+// One node's ZMQ socket, replaced when the pool moves to another node
+type zmqSubscription struct {
+	url    string
+	socket zmq4.Socket
+	cancel context.CancelFunc
+}
+
+func (s *zmqSubscription) Close() error {
+	s.cancel()
+	return s.socket.Close()
+}
+
+// Subscribes to the chain's active node, its messages arrive on notifications
+// until the subscription is closed
+func (p *PoolServer) createZMQSubscription(blockChainName string, notifications chan zmqNotification) (*zmqSubscription, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	node := p.activeNode(blockChainName)
+	subscription := &zmqSubscription{
+		url:    node.NotifyURL,
+		socket: zmq4.NewSub(ctx),
+		cancel: cancel,
+	}
+	sub := subscription.socket
+
+	topics, err := notificationTopics(p.config.BlockchainNodes[blockChainName][node.Index].ZMQTopics)
+	if err != nil {
+		subscription.Close()
+		return nil, err
+	}
+
+	err = sub.Dial(node.NotifyURL)
+	if err != nil {
+		subscription.Close()
+		return nil, err
+	}
+
+	for _, topic := range topics {
+		err = sub.SetOption(zmq4.OptionSubscribe, topic)
+		if err != nil {
+			subscription.Close()
+			return nil, err
+		}
+	}
+
+	go func() {
+		for {
+			msg, err := sub.Recv()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Println(err)
+				time.Sleep(time.Second) // A dead node fails every Recv, the template poll notices
+				continue
+			}
+			// topic, body, little endian sequence number
+			if len(msg.Frames) < 3 || len(msg.Frames[2]) < 4 {
+				continue
+			}
+			notification := zmqNotification{
+				blockChainName: blockChainName,
+				topic:          string(msg.Frames[0]),
+				body:           msg.Frames[1],
+				sequence:       binary.LittleEndian.Uint32(msg.Frames[2]),
+			}
+			select {
+			case notifications <- notification:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return subscription, nil
+}
+
+// The node numbers each topic's messages separately from 0
+type sequenceTracker map[string]uint32 // "blockChainName/topic" => next expected sequence
+
+// How many messages were lost before this one.  A sequence behind the
+// expected one is a restarted node, not a gap.
+func (s sequenceTracker) missed(msg zmqNotification) uint32 {
+	key := msg.blockChainName + "/" + msg.topic
+	expected, seen := s[key]
+	s[key] = msg.sequence + 1
+	if !seen || msg.sequence <= expected {
+		return 0
+	}
+	return msg.sequence - expected
+}
+
+// Another node counts from its own sequence numbers
+func (s sequenceTracker) forget(blockChainName string) {
+	for key := range s {
+		if strings.HasPrefix(key, blockChainName+"/") {
+			delete(s, key)
+		}
+	}
+}
+
+// Lost races are only looked for this far below the tip
+const raceWindow = 10
+
+// Our submitted blocks and the blocks the nodes connected, by height.  A
+// different hash at a height we submitted means ours lost.
+type raceTracker struct {
+	sync.Mutex
+	ours      map[string]map[uint]string // "blockChainName" => height => hash
+	connected map[string]map[uint]string
+}
+
+func newRaceTracker() *raceTracker {
+	return &raceTracker{
+		ours:      make(map[string]map[uint]string),
+		connected: make(map[string]map[uint]string),
+	}
+}
+
+func (t *raceTracker) remember(heights map[string]map[uint]string, blockChainName string, height uint, hash string) {
+	byHeight, exists := heights[blockChainName]
+	if !exists {
+		byHeight = make(map[uint]string)
+		heights[blockChainName] = byHeight
+	}
+	byHeight[height] = hash
+	for known := range byHeight {
+		if known+raceWindow < height {
+			delete(byHeight, known)
+		}
+	}
+}
+
+// Returns the winning hash when the node already connected another block here
+func (t *raceTracker) submitted(blockChainName string, height uint, hash string) string {
+	t.Lock()
+	defer t.Unlock()
+	t.remember(t.ours, blockChainName, height, hash)
+	winner := t.connected[blockChainName][height]
+	if winner == hash {
+		return ""
+	}
+	return winner
+}
+
+// Whether the connected block is ours, or our hash when it beat ours
+func (t *raceTracker) blockConnected(blockChainName string, height uint, hash string) (ours bool, lostHash string) {
+	t.Lock()
+	defer t.Unlock()
+	t.remember(t.connected, blockChainName, height, hash)
+	ourHash, submitted := t.ours[blockChainName][height]
+	if !submitted {
+		return false, ""
+	}
+	if ourHash == hash {
+		return true, ""
+	}
+	return false, ourHash
+}
+
+func (p *PoolServer) recordSubmission(blockChainName string, height uint, hash string) {
+	winner := p.races.submitted(blockChainName, height, hash)
+	if winner == "" {
+		return
+	}
+	m := "Our block %v at height %v was submitted after %v took the height"
+	alerts.Raise(alerts.Warning, blockChainName, fmt.Sprintf(m, hash, height, winner))
+}
+
+func (p *PoolServer) handleRawBlock(msg zmqNotification) {
+	summary, err := bitcoin.SummarizeBlock(msg.body, bitcoin.GetChain(msg.blockChainName))
+	if err != nil {
+		log.Printf("Can't decode %v rawblock %v: %v", msg.blockChainName, msg.sequence, err)
+		return
+	}
+
+	event := chainevents.Event{
+		Chain:    msg.blockChainName,
+		Topic:    msg.topic,
+		Sequence: msg.sequence,
+		Hash:     summary.Hash,
+		Height:   summary.Height,
+		Tag:      summary.Tag,
+		Payout:   summary.PayoutScript,
+	}
+	if summary.Height > 0 {
+		event.Ours, event.LostTo = p.races.blockConnected(msg.blockChainName, summary.Height, summary.Hash)
+	}
+	chainevents.Record(event)
+
+	switch {
+	case event.Ours:
+		log.Printf("🏁 Our %v block %v is the node's tip", msg.blockChainName, summary.Height)
+	case event.LostTo != "":
+		m := "Our block %v at height %v lost to %v mined by %q (%v)"
+		m = fmt.Sprintf(m, event.LostTo, summary.Height, summary.Hash, summary.Tag, summary.PayoutType)
+		alerts.Raise(alerts.Warning, msg.blockChainName, m)
+	default:
+		m := "%v block %v mined by %q (%v %v)"
+		log.Printf(m, msg.blockChainName, summary.Height, summary.Tag, summary.PayoutType, summary.PayoutScript)
+	}
+}
+
+// Mempool growth is worth new work once enough fees may have arrived
+func (p *PoolServer) mempoolRefreshDue(pending uint64, lastTemplate time.Time) bool {
+	threshold := p.config.MempoolRefreshTransactions
+	if threshold <= 0 || pending < uint64(threshold) {
+		return false
+	}
+	return time.Since(lastTemplate) >= p.mempoolRefreshInterval()
+}
+
+const defaultMempoolRefreshInterval = 10 * time.Second
+
+func (p *PoolServer) mempoolRefreshInterval() time.Duration {
+	interval, err := time.ParseDuration(p.config.MempoolRefreshInterval)
+	if err != nil {
+		return defaultMempoolRefreshInterval
+	}
+	return interval
+}
+
+const defaultTemplatePollInterval = 30 * time.Second
+
+func (p *PoolServer) templatePollInterval() time.Duration {
+	interval, err := time.ParseDuration(p.config.TemplatePollInterval)
+	if err != nil || interval <= 0 {
+		return defaultTemplatePollInterval
+	}
+	return interval
+}
+
+// The block our current job builds on, "blockChainName" => hash
+func (p *PoolServer) workTips() map[string]string {
+	p.Lock()
+	defer p.Unlock()
+	tips := make(map[string]string)
+	if p.templates.Template != nil {
+		tips[p.config.GetPrimary()] = p.templates.Template.PrevBlockHash
+	}
+	if aux1 := p.templates.GetAux1(); aux1 != nil {
+		tips[p.config.GetAux1()] = aux1.PreviousBlockHash
+	}
+	return tips
+}
+
+// Chains whose active node has moved past our work without a hashblock
+func (p *PoolServer) missedTips() []string {
+	var missed []string
+	for blockChainName, tip := range p.workTips() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		info, err := p.activeNode(blockChainName).RPC.GetBlockChainInfo(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("Can't poll the %v tip: %v", blockChainName, err)
+			continue
+		}
+		if info.BestBlockHash != "" && info.BestBlockHash != tip {
+			missed = append(missed, blockChainName)
+		}
+	}
+	return missed
+}
@@ -0,0 +1,140 @@
+package pool
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/config"
+	"designs.capital/dogepool/rpc"
+)
+
+func publishingNode(t *testing.T) *rpc.FakeNode {
+	node, err := rpc.NewFakeNode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = node.PublishBlocks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { node.Close() })
+	return node
+}
+
+// A pool on one litecoin node per config entry, the first active
+func notifiedPool(t *testing.T, nodes ...*rpc.FakeNode) *PoolServer {
+	var nodeConfigs []map[string]string
+	for _, node := range nodes {
+		nodeConfigs = append(nodeConfigs, map[string]string{"rpc_url": node.URL, "block_notify_url": node.NotifyURL})
+	}
+	contents, err := json.Marshal(map[string]any{"blockchains": map[string]any{"litecoin": nodeConfigs}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var configuration config.Config
+	err = json.Unmarshal(contents, &configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configuration.BlockChainOrder = []string{"litecoin"}
+
+	pool := NewServer(&configuration, nil)
+	pool.activeNodes = BlockChainNodesMap{"litecoin": nodeAt(nodes, 0)}
+	return pool
+}
+
+func nodeAt(nodes []*rpc.FakeNode, index int) blockChainNode {
+	return blockChainNode{
+		NotifyURL: nodes[index].NotifyURL,
+		RPC:       rpc.NewRPCClient("litecoin", nodes[index].URL, "", "", "5s"),
+		Index:     index,
+		ChainName: "litecoin",
+	}
+}
+
+// Subscriptions miss whatever is published before they connect, so keep
+// announcing until one arrives
+func awaitHashBlock(t *testing.T, node *rpc.FakeNode, notifications chan zmqNotification) zmqNotification {
+	deadline := time.After(5 * time.Second)
+	for {
+		err := node.PublishHashBlock("00")
+		if err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case msg := <-notifications:
+			return msg
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no hashblock arrived")
+		}
+	}
+}
+
+func TestSubscriptionFollowsActiveNode(t *testing.T) {
+	first, second := publishingNode(t), publishingNode(t)
+	pool := notifiedPool(t, first, second)
+	notifications := make(chan zmqNotification)
+
+	subscription, err := pool.createZMQSubscription("litecoin", notifications)
+	if err != nil {
+		t.Fatal(err)
+	}
+	awaitHashBlock(t, first, notifications)
+
+	pool.activeNodes["litecoin"] = nodeAt([]*rpc.FakeNode{first, second}, 1)
+	subscription.Close()
+	subscription, err = pool.createZMQSubscription("litecoin", notifications)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer subscription.Close()
+	if subscription.url != second.NotifyURL {
+		t.Fatalf("subscribed to %v, want the new node's %v", subscription.url, second.NotifyURL)
+	}
+	awaitHashBlock(t, second, notifications)
+
+	// The old node's socket is gone
+	err = first.PublishHashBlock("00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-notifications:
+		t.Fatalf("got %v from the node we left", msg.topic)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestMissedTips(t *testing.T) {
+	node := publishingNode(t)
+	pool := notifiedPool(t, node)
+	pool.templates.BitcoinBlock = bitcoin.BitcoinBlock{Template: &bitcoin.Template{PrevBlockHash: "aa"}}
+
+	err := node.Script("getblockchaininfo", map[string]any{"chain": "regtest", "bestblockhash": "aa"}, map[string]any{"chain": "regtest", "bestblockhash": "bb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missed := pool.missedTips(); len(missed) != 0 {
+		t.Errorf("work on the node's tip reported as missed: %v", missed)
+	}
+	if missed := pool.missedTips(); len(missed) != 1 || missed[0] != "litecoin" {
+		t.Errorf("got %v, want litecoin's new tip noticed", missed)
+	}
+}
+
+func TestSequencesForgetNode(t *testing.T) {
+	sequences := make(sequenceTracker)
+	sequences.missed(zmqNotification{blockChainName: "litecoin", topic: "hashblock", sequence: 40})
+	sequences.missed(zmqNotification{blockChainName: "dogecoin", topic: "hashblock", sequence: 7})
+
+	sequences.forget("litecoin")
+	if missed := sequences.missed(zmqNotification{blockChainName: "litecoin", topic: "hashblock", sequence: 900}); missed != 0 {
+		t.Errorf("a new node's first message counted %v missed", missed)
+	}
+	if missed := sequences.missed(zmqNotification{blockChainName: "dogecoin", topic: "hashblock", sequence: 10}); missed != 2 {
+		t.Errorf("dogecoin's gap: got %v, want 2", missed)
+	}
+}
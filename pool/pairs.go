@@ -12,6 +12,9 @@ func (p Pair) GetPrimary() bitcoin.BitcoinBlock {
 }
 
 func (p Pair) GetAuxN(n int) *bitcoin.AuxBlock {
+	if n >= len(p.AuxBlocks) {
+		return nil
+	}
 	return &p.AuxBlocks[n]
 }
 
@@ -0,0 +1,43 @@
+package pool
+
+import (
+	"designs.capital/dogepool/bitcoin"
+)
+
+// Pool fee recipients that get their own output in the primary coinbase
+func (pool *PoolServer) coinbaseRecipients(chainName, network, rewardTo string) ([]bitcoin.CoinbaseRecipient, error) {
+	// True solo has no wallet hop, so the pool fee can only be taken in the coinbase
+	trueSolo := pool.trueSolo()
+	payoutConfig, exists := pool.config.Payouts.Chains[chainName]
+	if !exists || chainName != pool.config.GetPrimary() || !(payoutConfig.PoolRewardsInCoinbase || trueSolo) {
+		return nil, nil
+	}
+
+	chain := bitcoin.GetChain(chainName)
+	var recipients []bitcoin.CoinbaseRecipient
+	for _, poolRecipient := range payoutConfig.PoolRewardRecipients {
+		if poolRecipient.Address == rewardTo && !trueSolo { // Already paid by the reward output
+			continue
+		}
+
+		script, err := bitcoin.AddressToScriptPubKey(chain, network, poolRecipient.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		recipients = append(recipients, bitcoin.CoinbaseRecipient{
+			Address:      poolRecipient.Address,
+			ScriptPubKey: script,
+			Percentage:   poolRecipient.Percentage,
+		})
+	}
+
+	return recipients, nil
+}
+
+func (node blockChainNode) coinbasePayees() bitcoin.CoinbasePayees {
+	return bitcoin.CoinbasePayees{
+		RewardScript: node.RewardPubScriptKey,
+		Recipients:   node.CoinbaseRecipients,
+	}
+}
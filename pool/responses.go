@@ -5,11 +5,11 @@ import (
     "errors"
     "fmt"
     "log"
-    "math/big"
     "strings"
     "time"
 
     "designs.capital/dogepool/bitcoin"
+    "designs.capital/dogepool/persistence"
     "github.com/google/uuid"
 )
 
@@ -41,10 +41,16 @@ func (pool *PoolServer) respondToStratumClient(client *stratumClient, requestPay
     if err != nil {
         return err
     }
+    if _, deferred := response.(deferredResponse); deferred {
+        return nil
+    }
 
     return sendPacket(response, client)
 }
 
+// Returned by handlers whose reply is sent later, i.e. once a worker hashed the share
+type deferredResponse struct{}
+
 func handleStratumRequest(request *stratumRequest, client *stratumClient, pool *PoolServer) (any, error) {
     switch request.Method {
     case "mining.subscribe":
@@ -144,9 +150,8 @@ func miningAuthorize(request *stratumRequest, client *stratumClient, pool *PoolS
         blockChain := bitcoin.GetChain(blockChainName)
         inputBlockChainAddress := minerAddresses[blockchainIndex]
 
-        network := pool.activeNodes[blockChainName].Network
-        if (network == "test" && !blockChain.ValidTestnetAddress(inputBlockChainAddress)) ||
-            (network == "main" && !blockChain.ValidMainnetAddress(inputBlockChainAddress)) {
+        network := pool.activeNode(blockChainName).Network
+        if !bitcoin.ValidNetworkAddress(blockChain, network, inputBlockChainAddress) {
             m := "invalid %v %vnet miner address from %v: %v"
             m = fmt.Sprintf(m, blockChainName, network, client.ip, inputBlockChainAddress)
             return authResponse, errors.New(m)
@@ -172,7 +177,7 @@ func miningAuthorize(request *stratumRequest, client *stratumClient, pool *PoolS
         return reply, err
     }
 
-    work, err := pool.generateWorkFromCache(false)
+    work, err := pool.workForClient(client, false)
     if err != nil {
         return reply, err
     }
@@ -191,108 +196,199 @@ func miningExtranonceSubscribe(request *stratumRequest, client *stratumClient) (
 
 
 
-func miningSubmit(request *stratumRequest, client *stratumClient, pool *PoolServer) (stratumResponse, error) {
+func miningSubmit(request *stratumRequest, client *stratumClient, pool *PoolServer) (any, error) {
     response := stratumResponse{
         Result: interface{}(false),
         Id:     request.Id,
     }
 
-    var work []string
+    var work bitcoin.Work
     err := json.Unmarshal(request.Params, &work)
     if err != nil {
         return response, fmt.Errorf("failed to parse submit params: %v", err)
     }
 
-    err = pool.receiveWorkFromClient(work, client)
+    submission, err := pool.prepareShare(work, client)
     if err != nil {
-        log.Printf("Work submission error from %v: %v", client.ip, err)
-        if strings.Contains(err.Error(), "invalid share") {
-            return response, nil
-        }
-        return response, err
+        log.Println(err)
+        return response, nil
     }
+    submission.id = request.Id
 
-    response.Result = interface{}(true)
-    return response, nil
+    err = pool.validation.enqueue(submission)
+    if err != nil {
+        log.Printf("Validation busy, rejected share from %v [%v]", client.ip, client.userAgent)
+        return busyResponse(request), nil
+    }
+
+    return deferredResponse{}, nil
 }
 
-func (pool *PoolServer) receiveWorkFromClient(submittedWork []string, client *stratumClient) error {
-    currentWork, err := pool.generateWorkFromCache(false)
+// Checks everything that doesn't need a hash so stale and malformed
+// submits never reach the validation workers
+func (pool *PoolServer) prepareShare(share bitcoin.Work, client *stratumClient) (*shareSubmission, error) {
+    templates, currentWork, err := pool.clientJob(client)
     if err != nil {
-        return fmt.Errorf("failed to fetch work template: %v", err)
+        return nil, err
+    }
+    primaryBlockTemplate := templates.GetPrimary()
+    currentJobID := ""
+    if len(currentWork) > 0 {
+        currentJobID, _ = currentWork[0].(string)
     }
-    log.Printf("Generated work from cache for %v: %v", client.ip, currentWork)
 
-    if len(submittedWork) < 5 {
-        return errors.New("invalid work submission: too few parameters")
+    if primaryBlockTemplate.Template == nil {
+        return nil, errors.New("primary block template not yet set")
     }
-    jobID := submittedWork[1]
-    extranonce2 := submittedWork[2]
-    ntime := submittedWork[3]
-    nonce := submittedWork[4]
 
-    log.Printf("Client %v submitted jobID: %v, current jobID: %v", client.ip, jobID, currentWork[0])
-    if jobID != currentWork[0] {
-        return errors.New("invalid share: stale job")
+    nonceSlot := primaryBlockTemplate.NonceSubmissionSlot()
+    if len(share) <= nonceSlot {
+        return nil, errors.New("invalid work submission: too few parameters from " + client.ip)
     }
 
-    template, auxBlocks, err := pool.fetchAllBlockTemplatesFromRPC()
-    if err != nil {
-        return fmt.Errorf("failed to get block template: %v", err)
+    jobID, _ := share[1].(string)
+    if jobID != currentJobID {
+        return nil, errors.New("stale share for job " + jobID + " from " + client.ip)
     }
 
-    block, _, err := bitcoin.GenerateWork(
-        template,
-        auxBlocks,
-        pool.config.BlockChainOrder[0],
-        extranonce2,
-        "",
-        0,
-    )
-    if err != nil {
-        return fmt.Errorf("failed to regenerate work: %v", err)
+    extranonce2Slot, _ := primaryBlockTemplate.Extranonce2SubmissionSlot()
+    extranonce2, ok2 := share[extranonce2Slot].(string)
+    nonceTime, okTime := share[primaryBlockTemplate.NonceTimeSubmissionSlot()].(string)
+    nonce, okNonce := share[nonceSlot].(string)
+    if !ok2 || !okTime || !okNonce {
+        return nil, errors.New("invalid work submission: malformed parameters from " + client.ip)
     }
 
-    header, err := block.MakeHeader(extranonce2, nonce, ntime)
-    if err != nil {
-        return fmt.Errorf("failed to make header: %v", err)
+    submission := &shareSubmission{
+        client:      client,
+        templates:   templates,
+        extranonce:  client.extranonce1 + extranonce2,
+        nonce:       nonce,
+        nonceTime:   nonceTime,
+        mayBeABlock: mightBeCandidate(&primaryBlockTemplate, templates.GetAux1(), pool.config.PoolDifficulty),
     }
 
-    hashInt, err := block.Sum()
+    return submission, nil
+}
+
+func (pool *PoolServer) processShare(submission *shareSubmission) error {
+    client := submission.client
+    primaryBlockTemplate := submission.templates.GetPrimary()
+    auxBlock := submission.templates.GetAux1()
+
+    err := primaryBlockTemplate.MakeHeader(submission.extranonce, submission.nonce, submission.nonceTime)
     if err != nil {
-        return fmt.Errorf("failed to hash header: %v", err)
+        return err
     }
 
-    targetInt := new(big.Int).Mul(
-        big.NewInt(int64(pool.config.PoolDifficulty)),
-        big.NewInt(0x100000000),
-    )
-    if hashInt.Cmp(targetInt) > 0 {
-        return errors.New("invalid share: below pool difficulty")
+    shareStatus, shareDifficulty := validateAndWeighShare(&primaryBlockTemplate, auxBlock, pool.config.PoolDifficulty)
+
+    heightMessage := fmt.Sprintf("%v", primaryBlockTemplate.Template.Height)
+    if shareStatus == dualCandidate {
+        heightMessage = fmt.Sprintf("%v,%v", primaryBlockTemplate.Template.Height, auxBlock.Height)
+    } else if shareStatus == aux1Candidate {
+        heightMessage = fmt.Sprintf("%v", auxBlock.Height)
     }
 
-    primaryChain := pool.activeNodes[pool.config.BlockChainOrder[0]]
-    _, err = primaryChain.RPC.SubmitBlock([]interface{}{header})
-    if err != nil {
-        log.Printf("Primary chain submission failed: %v", err)
-    }
-
-    for _, chainName := range pool.config.BlockChainOrder[1:] {
-        if len(currentWork) > 8 {
-            for _, auxEntry := range currentWork[8:] {
-                if auxStr, ok := auxEntry.(string); ok {
-                    parts := strings.SplitN(auxStr, ":", 2)
-                    if len(parts) == 2 && parts[0] == chainName {
-                        auxChain := pool.activeNodes[chainName]
-                        _, err = auxChain.RPC.SubmitAuxBlock(parts[1], header)
-                        if err != nil {
-                            log.Printf("Aux chain %v submission failed: %v", chainName, err)
-                        }
-                    }
-                }
+    if shareStatus == shareInvalid {
+        m := "❔ Invalid share for block %v from %v [%v]"
+        m = fmt.Sprintf(m, heightMessage, client.ip, client.userAgent)
+        return errors.New(m)
+    }
+
+    minerAddress, rigID := minerLogin(client.login)
+
+    blockTarget, _ := primaryBlockTemplate.Template.Target.ToBig()
+    blockDifficulty := bitcoin.TargetToDifficulty(blockTarget, primaryBlockTemplate.ShareMultiplier())
+
+    pool.Lock()
+    pool.shareBuffer = append(pool.shareBuffer, persistence.Share{
+        PoolID:            pool.config.PoolName,
+        BlockHeight:       primaryBlockTemplate.Template.Height,
+        Miner:             minerAddress,
+        Worker:            rigID,
+        UserAgent:         client.userAgent,
+        Difficulty:        shareDifficulty,
+        NetworkDifficulty: blockDifficulty,
+        IpAddress:         client.ip,
+        Created:           time.Now(),
+    })
+    pool.Unlock()
+
+    if shareStatus == shareValid {
+        return nil
+    }
+
+    m := "%v block candidate 🤞 %v"
+    m = fmt.Sprintf(m, statusMap[shareStatus], heightMessage)
+    log.Println(m)
+
+    // Blocks are never dropped, a full lane holds this worker until a submitter frees up
+    pool.validation.candidates <- &blockCandidate{
+        primary: primaryBlockTemplate,
+        aux1:    auxBlock,
+        status:  shareStatus,
+        miner:   minerAddress,
+    }
+
+    return nil
+}
+
+// Sends a candidate to the nodes it's a block for and records what they accepted
+func (pool *PoolServer) submitCandidate(candidate *blockCandidate) {
+    found := persistence.Found{
+        PoolID:               pool.config.PoolName,
+        Status:               persistence.StatusSubmitted,
+        Miner:                candidate.miner,
+        Source:               "",
+        ConfirmationProgress: 0,
+        Created:              time.Now(),
+    }
+    if pool.trueSolo() {
+        found.Source = persistence.SourceSolo
+    }
+
+    if candidate.status == dualCandidate || candidate.status == primaryCandidate {
+        err := pool.submitBlockToChain(candidate.primary)
+        if err != nil {
+            log.Println(err)
+        } else {
+            found.Chain = pool.config.GetPrimary()
+            found.Type = "primary"
+            found.BlockHeight = candidate.primary.Template.Height
+            found.NetworkDifficulty = pool.GetPrimaryNode().NetworkDifficulty
+            found.Hash, err = candidate.primary.HeaderHashed()
+            logOnError(err)
+            found.TransactionConfirmationData, err = candidate.primary.CoinbaseHashed()
+            logOnError(err)
+            if found.Source == persistence.SourceSolo { // The wallet never sees this coinbase
+                found.Reward = pool.soloPrimaryReward(candidate.primary.Template.CoinBaseValue)
             }
+
+            log.Printf("✅  Successful %v submission of block %v", found.Chain, found.BlockHeight)
+            logOnError(persistence.Blocks.Insert(found))
+            pool.recordSubmission(found.Chain, found.BlockHeight, found.Hash)
         }
     }
 
-    return nil
-}
\ No newline at end of file
+    if candidate.status == dualCandidate || candidate.status == aux1Candidate {
+        err := pool.submitAuxBlock(candidate.primary, *candidate.aux1)
+        if err != nil {
+            log.Println(err)
+        } else {
+            found.Chain = pool.config.GetAux1()
+            found.Type = "aux1"
+            found.BlockHeight = uint(candidate.aux1.Height)
+            found.NetworkDifficulty = pool.GetAux1Node().NetworkDifficulty
+            found.Hash = candidate.aux1.Hash
+            found.TransactionConfirmationData = ""
+            if found.Source == persistence.SourceSolo {
+                found.Reward = float64(candidate.aux1.CoinbaseValue) / satoshisPerCoin
+            }
+
+            log.Printf("✅  Successful %v submission of block %v", found.Chain, found.BlockHeight)
+            logOnError(persistence.Blocks.Insert(found))
+            pool.recordSubmission(found.Chain, found.BlockHeight, found.Hash)
+        }
+    }
+}
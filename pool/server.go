@@ -1,8 +1,10 @@
 package pool
 
 import (
+    "context"
     "encoding/json"
     "errors"
+    "fmt"
     "log"
     "sync"
     "time"
@@ -17,11 +19,16 @@ type PoolServer struct {
     sync.RWMutex
     config            *config.Config
     activeNodes       BlockChainNodesMap
+    nodesLock         sync.RWMutex
     rpcManagers       map[string]*rpc.Manager
     connectionTimeout time.Duration
     templates         Pair
     workCache         bitcoin.Work
+    soloJobs          soloJobMap
     shareBuffer       []persistence.Share
+    validation        *validationPool
+
+    races *raceTracker // Our blocks against the nodes' rawblock notifications
 }
 
 func NewServer(cfg *config.Config, rpcManagers map[string]*rpc.Manager) *PoolServer {
@@ -38,6 +45,8 @@ func NewServer(cfg *config.Config, rpcManagers map[string]*rpc.Manager) *PoolSer
     pool := &PoolServer{
         config:      cfg,
         rpcManagers: rpcManagers,
+        soloJobs:    make(soloJobMap),
+        races:       newRaceTracker(),
     }
 
     return pool
@@ -45,31 +54,59 @@ func NewServer(cfg *config.Config, rpcManagers map[string]*rpc.Manager) *PoolSer
 
 func (pool *PoolServer) Start() {
     initiateSessions()
+    panicOnError(bitcoin.CheckMergedFamily(pool.config.BlockChainOrder))
     pool.loadBlockchainNodes()
     pool.startBufferManager()
-
-    amountOfChains := len(pool.config.BlockChainOrder) - 1
-    pool.templates.AuxBlocks = make([]bitcoin.AuxBlock, amountOfChains)
+    pool.startValidationPool()
 
     panicOnError(pool.fetchRpcBlockTemplatesAndCacheWork())
-    work, err := pool.generateWorkFromCache(false)
-    panicOnError(err)
 
     go pool.listenForConnections()
-    pool.broadcastWork(work)
+    pool.broadcastWork(false)
 
     panicOnError(pool.listenForBlockNotifications())
 }
 
-func (pool *PoolServer) broadcastWork(work bitcoin.Work) {
+func (pool *PoolServer) broadcastWork(refresh bool) {
+    if pool.trueSolo() {
+        pool.notifySoloSessions(refresh)
+        return
+    }
+
+    work, err := pool.generateWorkFromCache(refresh)
+    if err != nil {
+        logOnError(err)
+        return
+    }
+
     request := miningNotify(work)
-    err := notifyAllSessions(request)
+    err = notifyAllSessions(request)
     logOnError(err)
 }
 
+func (pool *PoolServer) notifySoloSessions(refresh bool) {
+    for _, client := range sessions {
+        work, err := pool.workForClient(client, refresh)
+        if err != nil {
+            log.Printf("No solo work for %v: %v", client.ip, err)
+            continue
+        }
+        logOnError(sendPacket(miningNotify(work), client))
+    }
+    log.Printf("Sent solo work to %v client(s)", len(sessions))
+}
+
 func (p *PoolServer) fetchAllBlockTemplatesFromRPC() (*bitcoin.Template, map[string]*bitcoin.AuxBlock, error) {
     var template bitcoin.Template
-    response, err := p.GetPrimaryNode().RPC.GetBlockTemplate()
+    var response json.RawMessage
+    var err error
+    primary := p.GetPrimaryNode()
+    source, isSource := bitcoin.GetChain(primary.ChainName).(bitcoin.BlockSource)
+    if isSource && source.HandlesBlocks() {
+        response, err = source.GetBlockTemplate(primary.RPC.TemplateRules)
+    } else {
+        response, err = primary.RPC.GetBlockTemplate(context.Background())
+    }
     if err != nil {
         return nil, nil, errors.New("RPC error: " + err.Error())
     }
@@ -78,27 +115,55 @@ func (p *PoolServer) fetchAllBlockTemplatesFromRPC() (*bitcoin.Template, map[str
     if err != nil {
         return nil, nil, err
     }
+    template.Raw = response
 
     auxBlocks := make(map[string]*bitcoin.AuxBlock)
     for _, auxName := range p.config.BlockChainOrder[1:] {
-        auxNode := p.activeNodes[auxName]
-        response, err := auxNode.RPC.CreateAuxBlock(auxNode.RewardTo)
+        auxBlock, err := p.fetchAuxBlock(auxName, p.activeNode(auxName).RewardTo)
         if err != nil {
             log.Println("No aux block for", auxName, ":", err)
             continue
         }
-        var auxBlock bitcoin.AuxBlock
-        err = json.Unmarshal(response, &auxBlock)
-        if err != nil {
-            log.Println("Failed to parse aux block for", auxName, ":", err)
-            continue
-        }
-        auxBlocks[auxName] = &auxBlock
+        auxBlocks[auxName] = auxBlock
     }
 
     return &template, auxBlocks, nil
 }
 
+func (p *PoolServer) fetchAuxBlock(auxName, rewardAddress string) (*bitcoin.AuxBlock, error) {
+    auxNode := p.activeNode(auxName)
+    auxChain := bitcoin.GetChain(auxName)
+    flavour := auxChain.AuxRPC()
+
+    var response json.RawMessage
+    var err error
+    if flavour.Method == bitcoin.AuxMethodGet {
+        if rewardAddress != auxNode.RewardTo {
+            return nil, errors.New("getauxblock pays the " + auxName + " node's wallet, not " + rewardAddress)
+        }
+        response, err = auxNode.RPC.GetAuxBlock(context.Background())
+    } else {
+        response, err = auxNode.RPC.CreateAuxBlock(context.Background(), rewardAddress)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    auxBlock, err := bitcoin.ParseAuxBlock(response, flavour)
+    if err != nil {
+        return nil, err
+    }
+
+    chainID := auxChain.AuxChainID()
+    if chainID != 0 && uint32(auxBlock.ChainID) != chainID {
+        m := "%v node returned aux chain ID %v, expected %v"
+        m = fmt.Sprintf(m, auxName, auxBlock.ChainID, chainID)
+        return nil, errors.New(m)
+    }
+
+    return &auxBlock, nil
+}
+
 func notifyAllSessions(request stratumRequest) error {
     for _, client := range sessions {
         err := sendPacket(request, client)
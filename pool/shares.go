@@ -2,6 +2,8 @@ package pool
 
 import (
     "log"
+    "math/big"
+
     "designs.capital/dogepool/bitcoin"
 )
 
@@ -32,33 +34,37 @@ func validateAndWeighShare(primary *bitcoin.BitcoinBlock, aux1 *bitcoin.AuxBlock
         return shareInvalid, 0
     }
 
-    primaryTarget := bitcoin.Target(primary.Template.Target)
-    primaryTargetBig, ok := primaryTarget.ToBig()
+    primaryTargetBig, ok := primary.Template.Target.ToBig()
     if !ok || primaryTargetBig == nil {
         log.Printf("Invalid primary target: %s", primary.Template.Target)
         return shareInvalid, 0
     }
 
-    poolTarget, _ := bitcoin.TargetFromDifficulty(poolDifficulty / primary.ShareMultiplier())
-    shareDifficulty, _ := poolTarget.ToDifficulty()
+    poolTarget, err := bitcoin.DifficultyToTarget(poolDifficulty, primary.ShareMultiplier())
+    if err != nil {
+        log.Printf("Invalid pool target: %v", err)
+        return shareInvalid, 0
+    }
+    // Shares are persisted in Bitcoin difficulty
+    shareDifficulty := bitcoin.TargetToDifficulty(poolTarget, 1)
 
     status := shareInvalid
 
-    if primarySum.Cmp(primaryTargetBig) <= 0 {
+    if bitcoin.HashMeetsTarget(primarySum, primaryTargetBig) {
         log.Printf("Primary share is a block candidate")
         status = primaryCandidate
     }
 
     if aux1 != nil && aux1.Hash != "" {
         log.Printf("Processing aux chain: Hash=%s, Target=%s", aux1.Hash, aux1.Target)
-        auxTarget := bitcoin.Target(reverseHexBytes(aux1.Target))
+        auxTarget := bitcoin.Target(aux1.Target)
         auxTargetBig, ok := auxTarget.ToBig()
         if !ok || auxTargetBig == nil {
-            log.Printf("Invalid aux target: %s (reversed: %s)", aux1.Target, reverseHexBytes(aux1.Target))
+            log.Printf("Invalid aux target: %s", aux1.Target)
             return status, shareDifficulty
         }
 
-        if primarySum.Cmp(auxTargetBig) <= 0 {
+        if bitcoin.HashMeetsTarget(primarySum, auxTargetBig) {
             log.Printf("Aux share is a block candidate")
             if status == primaryCandidate {
                 status = dualCandidate
@@ -75,16 +81,44 @@ func validateAndWeighShare(primary *bitcoin.BitcoinBlock, aux1 *bitcoin.AuxBlock
         return status, shareDifficulty
     }
 
-    poolTargetBig, ok := poolTarget.ToBig()
-    if !ok || poolTargetBig == nil {
-        log.Printf("Invalid pool target")
-        return shareInvalid, shareDifficulty
-    }
-    if primarySum.Cmp(poolTargetBig) <= 0 {
+    if bitcoin.HashMeetsTarget(primarySum, poolTarget) {
         log.Printf("Share meets pool difficulty")
         return shareValid, shareDifficulty
     }
 
-    log.Printf("Share invalid: primarySum=%s, poolTarget=%s", primarySum.Text(16), poolTargetBig.Text(16))
+    log.Printf("Share invalid: primarySum=%s, poolTarget=%s", primarySum.Text(16), poolTarget.Text(16))
     return shareInvalid, shareDifficulty
-}
\ No newline at end of file
+}
+
+// A job's blocks are within reach when one in this many pool shares or
+// better finds one, i.e. Dogecoin against a modest pool difficulty
+const candidateReach = 1000
+
+// Miners only submit hashes under the pool target, so a share is a block
+// with odds of block target over pool target.  Whether this share is one is
+// only known once it's hashed, shares from jobs with good enough odds are
+// never turned away while they wait.
+func mightBeCandidate(primary *bitcoin.BitcoinBlock, aux1 *bitcoin.AuxBlock, poolDifficulty float64) bool {
+    poolTarget, err := bitcoin.DifficultyToTarget(poolDifficulty, primary.ShareMultiplier())
+    if err != nil {
+        return false
+    }
+
+    candidateTargets := []bitcoin.Target{primary.Template.Target}
+    if aux1 != nil && aux1.Hash != "" {
+        candidateTargets = append(candidateTargets, bitcoin.Target(aux1.Target))
+    }
+
+    for _, target := range candidateTargets {
+        targetBig, ok := target.ToBig()
+        if !ok || targetBig == nil {
+            continue
+        }
+        reach := new(big.Int).Mul(targetBig, big.NewInt(candidateReach))
+        if reach.Cmp(poolTarget) >= 0 {
+            return true
+        }
+    }
+
+    return false
+}
@@ -0,0 +1,62 @@
+package pool
+
+import (
+	"fmt"
+	"testing"
+
+	"designs.capital/dogepool/bitcoin"
+)
+
+func TestMightBeCandidate(t *testing.T) {
+	const poolDifficulty = 1000
+	multiplier := bitcoin.GetChain("litecoin").ShareMultiplier()
+	// Rounded to what bits can express so templates stay consistent
+	targetAt := func(difficulty float64) (string, string) {
+		target, err := bitcoin.DifficultyToTarget(difficulty, multiplier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bits := bitcoin.TargetToCompact(target)
+		target, err = bitcoin.CompactToTarget(bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprintf("%064x", target), fmt.Sprintf("%08x", bits)
+	}
+
+	tests := []struct {
+		name              string
+		primaryDifficulty float64
+		auxDifficulty     float64 // No aux job when zero
+		want              bool
+	}{
+		{"regtest primary", 0.001, 0, true},
+		{"primary one share in 500", poolDifficulty * 500, 0, true},
+		{"mainnet primary", poolDifficulty * 1e6, 0, false},
+		{"aux one share in 900", poolDifficulty * 1e6, poolDifficulty * 900, true},
+		{"aux one share in 2000", poolDifficulty * 1e6, poolDifficulty * 2000, false},
+	}
+	for _, test := range tests {
+		target, bits := targetAt(test.primaryDifficulty)
+		template := &bitcoin.Template{
+			PrevBlockHash: fmt.Sprintf("%064x", 0),
+			Target:        bitcoin.Target(target),
+			Bits:          bits,
+		}
+		payees := bitcoin.CoinbasePayees{RewardScript: "76a9140102030405060708090a0b0c0d0e0f101112131488ac"}
+		primary, _, err := bitcoin.GenerateWork(template, "litecoin", "", payees, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var aux1 *bitcoin.AuxBlock
+		if test.auxDifficulty > 0 {
+			auxTarget, _ := targetAt(test.auxDifficulty)
+			aux1 = &bitcoin.AuxBlock{Hash: "aa", Target: auxTarget}
+		}
+
+		got := mightBeCandidate(primary, aux1, poolDifficulty)
+		if got != test.want {
+			t.Errorf("%v: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
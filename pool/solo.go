@@ -0,0 +1,137 @@
+package pool
+
+import (
+	"errors"
+	"strings"
+
+	"designs.capital/dogepool/bitcoin"
+)
+
+// In true solo mode every miner address gets its own job: the primary
+// coinbase and every aux block pay the miner, never the pool wallet.
+type soloJob struct {
+	work      bitcoin.Work
+	templates Pair
+}
+
+const satoshisPerCoin = 100_000_000
+
+type soloJobMap map[string]*soloJob // "minerAddresses" => job for the current templates
+
+func (pool *PoolServer) trueSolo() bool {
+	return pool.config.Payouts.TrueSolo()
+}
+
+func minerLogin(login string) (string, string) {
+	loginParts := strings.Split(login, ".")
+	minerAddress, rigID := loginParts[0], ""
+	if len(loginParts) > 1 {
+		rigID = loginParts[1]
+	}
+	return minerAddress, rigID
+}
+
+// Returns the templates and work the client is mining on
+func (pool *PoolServer) clientJob(client *stratumClient) (Pair, bitcoin.Work, error) {
+	if !pool.trueSolo() {
+		pool.RLock()
+		defer pool.RUnlock()
+		return pool.templates, pool.workCache, nil
+	}
+
+	minerAddress, _ := minerLogin(client.login)
+
+	pool.RLock()
+	job, exists := pool.soloJobs[minerAddress]
+	pool.RUnlock()
+	if exists {
+		return job.templates, job.work, nil
+	}
+
+	job, err := pool.makeSoloJob(minerAddress)
+	if err != nil {
+		return Pair{}, nil, err
+	}
+
+	// Another notify or share may have built this miner's job while we were,
+	// the first one stored is what the miner gets and its shares are checked
+	// against.  A job for templates that were replaced meanwhile isn't kept.
+	pool.Lock()
+	defer pool.Unlock()
+	stored, exists := pool.soloJobs[minerAddress]
+	if exists {
+		return stored.templates, stored.work, nil
+	}
+	if pool.templates.GetPrimary().Template == job.templates.GetPrimary().Template {
+		pool.soloJobs[minerAddress] = job
+	}
+
+	return job.templates, job.work, nil
+}
+
+func (pool *PoolServer) makeSoloJob(minerAddress string) (*soloJob, error) {
+	pool.RLock()
+	template := pool.templates.GetPrimary().Template
+	pool.RUnlock()
+	if template == nil {
+		return nil, errors.New("primary block template not yet set")
+	}
+
+	minerAddresses := strings.Split(minerAddress, "-")
+	if len(minerAddresses) != len(pool.config.BlockChainOrder) {
+		return nil, errors.New("solo job needs one address per chain: " + minerAddress)
+	}
+
+	primaryNode := pool.GetPrimaryNode()
+	minerScript, err := bitcoin.AddressToScriptPubKey(bitcoin.GetChain(primaryNode.ChainName), primaryNode.Network, minerAddresses[0])
+	if err != nil {
+		return nil, err
+	}
+
+	payees := primaryNode.coinbasePayees()
+	payees.RewardScript = minerScript
+
+	job := &soloJob{}
+	auxillary := pool.config.BlockSignature
+	aux1Name := pool.config.GetAux1()
+	if aux1Name != "" {
+		aux1Block, err := pool.fetchAuxBlock(aux1Name, minerAddresses[1])
+		if err != nil {
+			return nil, err
+		}
+		auxillary = auxillary + hexStringToByteString(aux1Block.GetWork())
+		job.templates.AuxBlocks = []bitcoin.AuxBlock{*aux1Block}
+	}
+
+	block, work, err := bitcoin.GenerateWork(
+		template,
+		pool.config.GetPrimary(),
+		auxillary,
+		payees,
+		extranonceByteReservationLength,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	job.templates.BitcoinBlock = *block
+	job.work = work
+
+	return job, nil
+}
+
+// The finder's coinbase output once the pool fee outputs are taken out
+func (pool *PoolServer) soloPrimaryReward(coinbaseValue uint) float64 {
+	outputs, err := pool.GetPrimaryNode().coinbasePayees().Split(uint64(coinbaseValue))
+	if err != nil || len(outputs) < 1 {
+		logOnError(err)
+		return 0
+	}
+	return float64(outputs[0].Value) / satoshisPerCoin
+}
+
+func (pool *PoolServer) resetSoloJobs() {
+	pool.Lock()
+	pool.soloJobs = make(soloJobMap)
+	pool.Unlock()
+}
@@ -0,0 +1,64 @@
+package pool
+
+import (
+	"sync"
+	"testing"
+
+	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/config"
+)
+
+func soloPool() *PoolServer {
+	pool := NewServer(&config.Config{
+		PoolName:        "solo",
+		BlockChainOrder: []string{"litecoin"},
+		Payouts:         config.PayoutsConfig{Scheme: config.TrueSoloScheme},
+	}, nil)
+	pool.activeNodes = BlockChainNodesMap{"litecoin": {ChainName: "litecoin", Network: "test"}}
+	pool.templates.BitcoinBlock.Template = &bitcoin.Template{
+		Version:       0x20000000,
+		PrevBlockHash: "0c3e6b1e2d5f4a8b9c7e6d5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f2e4c",
+		Height:        101,
+		CoinBaseValue: 50 * satoshisPerCoin,
+		Bits:          "207fffff",
+		Target:        "7fffff0000000000000000000000000000000000000000000000000000000000",
+		CurrentTime:   1700000000,
+	}
+	return pool
+}
+
+// Every share and notify for a miner must see the one job its shares are
+// checked against, however many race to build it
+func TestClientJobIsBuiltOncePerMiner(t *testing.T) {
+	pool := soloPool()
+	client := &stratumClient{login: "myVAEip8wkzC956Goo5bMN5RipDVVyt7sx.rig"}
+
+	const racers = 64
+	jobIDs := make([]any, racers)
+	start := make(chan struct{})
+	var wait sync.WaitGroup
+	for i := 0; i < racers; i++ {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			<-start
+			_, work, err := pool.clientJob(client)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			jobIDs[i] = work[0]
+		}(i)
+	}
+	close(start)
+	wait.Wait()
+
+	for i := 1; i < racers; i++ {
+		if jobIDs[i] != jobIDs[0] {
+			t.Fatalf("miner got jobs %v and %v", jobIDs[0], jobIDs[i])
+		}
+	}
+	if len(pool.soloJobs) != 1 {
+		t.Errorf("%v jobs stored, want the miner's one", len(pool.soloJobs))
+	}
+}
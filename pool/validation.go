@@ -0,0 +1,167 @@
+package pool
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"runtime"
+	"time"
+
+	"designs.capital/dogepool/bitcoin"
+)
+
+const (
+	defaultMaxInflightSubmits = 8
+	validationQueuePerWorker  = 64
+	stratumErrorOther         = 20
+
+	// Node round trips and inconclusive retries take seconds, a second
+	// submitter keeps a dual candidate's aux block from waiting on the primary
+	blockSubmitters    = 2
+	candidateQueueSize = 64
+
+	// How long a share with a block within reach waits for room in its lane
+	priorityQueueWait = 500 * time.Millisecond
+)
+
+var errValidationBusy = errors.New("busy")
+
+// A submit that passed the cheap checks and is waiting for a worker to hash it
+type shareSubmission struct {
+	id          json.RawMessage
+	client      *stratumClient
+	templates   Pair
+	extranonce  string
+	nonce       string
+	nonceTime   string
+	mayBeABlock bool
+}
+
+// A hashed share that met a block target, waiting to be sent to the nodes
+type blockCandidate struct {
+	primary bitcoin.BitcoinBlock
+	aux1    *bitcoin.AuxBlock
+	status  int
+	miner   string
+}
+
+// Hashes shares on a fixed number of goroutines so connections can't pile up
+// unbounded scrypt work.  Shares from jobs with a block within reach have
+// their own lane that workers drain before touching ordinary shares, and
+// shares whose hash is a block go to submitters so the node round trips
+// never hold up validation.
+type validationPool struct {
+	priority    chan *shareSubmission
+	normal      chan *shareSubmission
+	candidates  chan *blockCandidate
+	maxInflight int32
+}
+
+func (pool *PoolServer) startValidationPool() {
+	workers := pool.config.ValidationWorkers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	maxInflight := pool.config.MaxInflightSubmits
+	if maxInflight < 1 {
+		maxInflight = defaultMaxInflightSubmits
+	}
+
+	pool.validation = &validationPool{
+		priority:    make(chan *shareSubmission, workers*validationQueuePerWorker),
+		normal:      make(chan *shareSubmission, workers*validationQueuePerWorker),
+		candidates:  make(chan *blockCandidate, candidateQueueSize),
+		maxInflight: int32(maxInflight),
+	}
+
+	for i := 0; i < workers; i++ {
+		go pool.validateSubmissions()
+	}
+	for i := 0; i < blockSubmitters; i++ {
+		go pool.submitCandidates()
+	}
+	log.Printf("Validating shares on %v worker(s), %v in flight per session", workers, maxInflight)
+}
+
+// Shares are turned away when the session or the pool is saturated.
+// Shares with a block within reach wait a little for room in their lane
+// rather than being turned away at once.
+func (v *validationPool) enqueue(submission *shareSubmission) error {
+	client := submission.client
+	if client.inflightSubmits.Add(1) > v.maxInflight {
+		client.inflightSubmits.Add(-1)
+		return errValidationBusy
+	}
+
+	if submission.mayBeABlock {
+		timeout := time.NewTimer(priorityQueueWait)
+		defer timeout.Stop()
+		select {
+		case v.priority <- submission:
+			return nil
+		case <-timeout.C:
+			client.inflightSubmits.Add(-1)
+			return errValidationBusy
+		}
+	}
+
+	select {
+	case v.normal <- submission:
+		return nil
+	default:
+		client.inflightSubmits.Add(-1)
+		return errValidationBusy
+	}
+}
+
+func (pool *PoolServer) validateSubmissions() {
+	v := pool.validation
+	for {
+		var submission *shareSubmission
+		select {
+		case submission = <-v.priority:
+		default:
+			select {
+			case submission = <-v.priority:
+			case submission = <-v.normal:
+			}
+		}
+
+		pool.answerSubmission(submission)
+	}
+}
+
+func (pool *PoolServer) answerSubmission(submission *shareSubmission) {
+	defer submission.client.inflightSubmits.Add(-1)
+
+	response := stratumResponse{
+		Result: interface{}(false),
+		Id:     submission.id,
+	}
+
+	err := pool.processShare(submission)
+	if err != nil {
+		log.Println(err)
+	} else {
+		response.Result = interface{}(true)
+	}
+
+	logOnError(sendPacket(response, submission.client))
+}
+
+func (pool *PoolServer) submitCandidates() {
+	for candidate := range pool.validation.candidates {
+		pool.submitCandidate(candidate)
+	}
+}
+
+func busyResponse(request *stratumRequest) stratumResponse {
+	return stratumResponse{
+		Result: interface{}(false),
+		Id:     request.Id,
+		Error: &stratumErrorResponse{
+			Code:    stratumErrorOther,
+			Message: errValidationBusy.Error(),
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnqueueAnswersBusy(t *testing.T) {
+	v := &validationPool{
+		priority:    make(chan *shareSubmission, 1),
+		normal:      make(chan *shareSubmission, 1),
+		maxInflight: 3,
+	}
+	client := &stratumClient{}
+	share := func(mayBeABlock bool) *shareSubmission {
+		return &shareSubmission{client: client, mayBeABlock: mayBeABlock}
+	}
+
+	for _, mayBeABlock := range []bool{true, false} {
+		err := v.enqueue(share(mayBeABlock))
+		if err != nil {
+			t.Fatalf("first share in its lane: %v", err)
+		}
+	}
+
+	// Both lanes are full, a block within reach waits a little then gives up
+	for _, mayBeABlock := range []bool{true, false} {
+		err := v.enqueue(share(mayBeABlock))
+		if !errors.Is(err, errValidationBusy) {
+			t.Errorf("full lane took a share, block within reach %v: %v", mayBeABlock, err)
+		}
+	}
+	if client.inflightSubmits.Load() != 2 {
+		t.Errorf("%v in flight, want the 2 queued", client.inflightSubmits.Load())
+	}
+
+	// Room in the lanes but not in the session
+	<-v.priority
+	<-v.normal
+	client.inflightSubmits.Store(3)
+	err := v.enqueue(share(true))
+	if !errors.Is(err, errValidationBusy) || len(v.priority) != 0 {
+		t.Errorf("a saturated session queued a share: %v", err)
+	}
+	if client.inflightSubmits.Load() != 3 {
+		t.Errorf("%v in flight, want the session's 3", client.inflightSubmits.Load())
+	}
+}
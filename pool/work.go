@@ -1,99 +1,93 @@
 package pool
 
 import (
-    "errors"
-    "fmt"
-    "log"
-    "strings"
-    "time"
-
-    "designs.capital/dogepool/bitcoin"
-    "designs.capital/dogepool/persistence"
+	"log"
+
+	"designs.capital/dogepool/bitcoin"
+	"designs.capital/dogepool/chainevents"
 )
 
+// Bytes of the coinbase reserved for extranonce1 + extranonce2
+const extranonceByteReservationLength = 8
+
 // Main INPUT
 func (p *PoolServer) fetchRpcBlockTemplatesAndCacheWork() error {
-    var block *bitcoin.BitcoinBlock
-    var err error
-    template, auxBlocks, err := p.fetchAllBlockTemplatesFromRPC()
-    if err != nil {
-        err = p.CheckAndRecoverRPCs()
-        if err != nil {
-            return err
-        }
-        template, auxBlocks, err = p.fetchAllBlockTemplatesFromRPC()
-        if err != nil {
-            return err
-        }
-    }
-
-    auxillary := p.config.BlockSignature
-    if len(auxBlocks) > 0 {
-        for _, auxBlock := range auxBlocks {
-            mergedPOW := auxBlock.Hash
-            auxillary = auxillary + hexStringToByteString(mergedPOW) // Use from encoding.go
-            p.templates.AuxBlocks = []bitcoin.AuxBlock{*auxBlock}
-            break
-        }
-    }
-
-    primaryName := p.config.GetPrimary()
-    rewardPubScriptKey := p.GetPrimaryNode().RewardPubScriptKey
-    extranonceByteReservationLength := 8
-
-    block, work, err := bitcoin.GenerateWork(
-        template,
-        auxBlocks,
-        primaryName,
-        auxillary,
-        rewardPubScriptKey,
-        extranonceByteReservationLength,
-    )
-    if err != nil {
-        log.Print(err)
-        return err
-    }
-
-    // Convert bitcoin.Work to []string if needed
-    p.workCache = convertWorkToStringSlice(work) // Define this helper
-    p.templates.BitcoinBlock = *block
-    return nil
+	p.followActiveRPCs()
+	template, auxBlocks, err := p.fetchAllBlockTemplatesFromRPC()
+	if err != nil {
+		err = p.CheckAndRecoverRPCs()
+		if err != nil {
+			return err
+		}
+		p.followActiveRPCs()
+		template, auxBlocks, err = p.fetchAllBlockTemplatesFromRPC()
+		if err != nil {
+			return err
+		}
+	}
+
+	auxillary := p.config.BlockSignature
+	aux1Block, hasAux1 := auxBlocks[p.config.GetAux1()]
+	if hasAux1 {
+		mergedPOW := aux1Block.GetWork()
+		auxillary = auxillary + hexStringToByteString(mergedPOW)
+	}
+
+	primaryName := p.config.GetPrimary()
+	payees := p.GetPrimaryNode().coinbasePayees()
+
+	block, work, err := bitcoin.GenerateWork(
+		template,
+		primaryName,
+		auxillary,
+		payees,
+		extranonceByteReservationLength,
+	)
+	if err != nil {
+		log.Print(err)
+		return err
+	}
+
+	p.Lock()
+	p.workCache = work
+	p.templates.BitcoinBlock = *block
+	if hasAux1 {
+		p.templates.AuxBlocks = []bitcoin.AuxBlock{*aux1Block}
+	} else {
+		p.templates.AuxBlocks = nil
+	}
+	p.Unlock()
+
+	for _, blockChainName := range p.config.BlockChainOrder {
+		chainevents.TemplateRefreshed(blockChainName)
+	}
+
+	if p.trueSolo() {
+		p.resetSoloJobs()
+	}
+
+	return nil
 }
 
-// Generate work from cache
-func (p *PoolServer) generateWorkFromCache(force bool) ([]string, error) {
-    if force || len(p.workCache) == 0 {
-        primaryName := p.config.BlockChainOrder[0]
-
-        template, auxBlocks, err := p.fetchAllBlockTemplatesFromRPC()
-        if err != nil {
-            return nil, err
-        }
-
-        _, work, err := bitcoin.GenerateWork(
-            template,
-            auxBlocks,
-            primaryName,
-            "", // arbitrary
-            "", // rewardPubScriptKey
-            0,  // extranonceByteReservationLength
-        )
-        if err != nil {
-            return nil, err
-        }
-
-        p.workCache = convertWorkToStringSlice(work) // Convert to []string
-        return p.workCache, nil
-    }
-    return p.workCache, nil
+// Stratum's last notify parameter tells miners whether to drop their current jobs
+func (p *PoolServer) generateWorkFromCache(refresh bool) (bitcoin.Work, error) {
+	p.RLock()
+	defer p.RUnlock()
+
+	return withCleanJobs(p.workCache, refresh), nil
 }
 
-// Helper to convert bitcoin.Work to []string (adjust based on actual type)
-func convertWorkToStringSlice(work bitcoin.Work) []string {
-    // Assuming bitcoin.Work is []interface{} or similar
-    result := make([]string, len(work))
-    for i, v := range work {
-        result[i] = fmt.Sprintf("%v", v) // Convert each element to string
-    }
-    return result
-}
\ No newline at end of file
+func (p *PoolServer) workForClient(client *stratumClient, refresh bool) (bitcoin.Work, error) {
+	_, work, err := p.clientJob(client)
+	if err != nil {
+		return nil, err
+	}
+
+	return withCleanJobs(work, refresh), nil
+}
+
+func withCleanJobs(cached bitcoin.Work, refresh bool) bitcoin.Work {
+	work := make(bitcoin.Work, len(cached), len(cached)+1)
+	copy(work, cached)
+	return append(work, interface{}(refresh))
+}
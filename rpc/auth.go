@@ -0,0 +1,177 @@
+package rpc
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Where a node's RPC user and password come from.  They're only ever sent
+// in the Authorization header, never the URL.
+type Credentials interface {
+	UserPassword() (string, string, error)
+	// Called after the node answers 401, i.e. it restarted with a new cookie.
+	// Reports whether the credentials changed and are worth another try.
+	Refresh() (bool, error)
+}
+
+type staticCredentials struct {
+	user     string
+	password string
+}
+
+func StaticCredentials(user, password string) Credentials {
+	return staticCredentials{user: user, password: password}
+}
+
+func (c staticCredentials) UserPassword() (string, string, error) {
+	return c.user, c.password, nil
+}
+
+func (c staticCredentials) Refresh() (bool, error) {
+	return false, nil
+}
+
+// A file holding user:password, the format of the node's .cookie
+type fileCredentials struct {
+	sync.Mutex
+	path     string
+	user     string
+	password string
+	loaded   bool
+}
+
+// The node writes a fresh __cookie__:<random> file each time it starts
+func CookieFileCredentials(path string) Credentials {
+	return &fileCredentials{path: path}
+}
+
+// Same format as a cookie, for operators keeping rpcauth users out of the config
+func CredentialsFile(path string) Credentials {
+	return &fileCredentials{path: path}
+}
+
+func (c *fileCredentials) UserPassword() (string, string, error) {
+	c.Lock()
+	defer c.Unlock()
+	if !c.loaded {
+		_, err := c.load()
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return c.user, c.password, nil
+}
+
+func (c *fileCredentials) Refresh() (bool, error) {
+	c.Lock()
+	defer c.Unlock()
+	return c.load()
+}
+
+func (c *fileCredentials) load() (bool, error) {
+	contents, err := os.ReadFile(c.path)
+	if err != nil {
+		return false, errors.New("can't read RPC credentials from " + c.path + ": " + err.Error())
+	}
+	user, password, err := splitCredentials(string(contents))
+	if err != nil {
+		return false, errors.New(c.path + ": " + err.Error())
+	}
+
+	changed := user != c.user || password != c.password
+	c.user, c.password, c.loaded = user, password, true
+	return changed, nil
+}
+
+// An environment variable holding user:password, read once
+func EnvCredentials(variable string) (Credentials, error) {
+	value, exists := os.LookupEnv(variable)
+	if !exists {
+		return nil, errors.New("RPC credentials variable " + variable + " is not set")
+	}
+	user, password, err := splitCredentials(value)
+	if err != nil {
+		return nil, errors.New(variable + ": " + err.Error())
+	}
+	return StaticCredentials(user, password), nil
+}
+
+func splitCredentials(contents string) (string, string, error) {
+	user, password, found := strings.Cut(strings.TrimSpace(contents), ":")
+	if !found || user == "" {
+		return "", "", errors.New("expected user:password")
+	}
+	return user, password, nil
+}
+
+func basicAuthorization(user, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+// Pick the one credential source a node config names
+func (c Config) Credentials() (Credentials, error) {
+	var sources []Credentials
+	if c.Password != "" {
+		sources = append(sources, StaticCredentials(c.Username, c.Password))
+	}
+	if c.CookieFile != "" {
+		sources = append(sources, CookieFileCredentials(c.CookieFile))
+	}
+	if c.CredentialsFile != "" {
+		sources = append(sources, CredentialsFile(c.CredentialsFile))
+	}
+	if c.CredentialsEnv != "" {
+		credentials, err := EnvCredentials(c.CredentialsEnv)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, credentials)
+	}
+
+	switch len(sources) {
+	case 0:
+		return nil, nil // Credentials in the URL or none at all
+	case 1:
+		return sources[0], nil
+	}
+	return nil, errors.New(c.Name + ": configure only one of a password, cookie file, credentials file or credentials variable")
+}
+
+// Replaces anything that would let a reader log in to the node
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+func (r *RPCClient) redact(err error) error {
+	if err == nil || r.credentials == nil {
+		return err
+	}
+	user, password, credentialsErr := r.credentials.UserPassword()
+	if credentialsErr != nil || password == "" {
+		return err
+	}
+
+	message := err.Error()
+	redacted := strings.NewReplacer(
+		basicAuthorization(user, password), "Basic ***",
+		base64.StdEncoding.EncodeToString([]byte(user+":"+password)), "***",
+		user+":"+password, user+":***",
+		password, "***",
+	).Replace(message)
+	if redacted == message {
+		return err
+	}
+	return &redactedError{message: redacted, err: err}
+}
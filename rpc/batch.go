@@ -0,0 +1,207 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// One call in a JSON-RPC batch.  Result is unmarshalled into when the node
+// answers it, Err holds that call's own failure.
+type BatchCall struct {
+	Method string
+	Params []interface{}
+	Result any
+	Err    error
+}
+
+// Sends every call in one HTTP round trip.  The returned error is for the
+// batch as a whole, each call's own failure is in its Err.
+func (r *RPCClient) Batch(ctx context.Context, calls []*BatchCall) error {
+	if len(calls) < 1 {
+		return nil
+	}
+
+	methods := make([]string, len(calls))
+	timeout := time.Duration(0)
+	for i, call := range calls {
+		methods[i] = call.Method
+		if t := r.methodTimeout(call.Method); t > timeout {
+			timeout = t
+		}
+	}
+
+	err := r.withRetry(ctx, methods, func(ctx context.Context) (bool, error) {
+		requests := make([]rpcRequest, len(calls))
+		callsByID := make(map[uint64]*BatchCall, len(calls))
+		for i, call := range calls {
+			requests[i] = r.newRequest(call.Method, call.Params)
+			callsByID[requests[i].ID] = call
+			call.Err = nil
+		}
+		body, err := json.Marshal(requests)
+		if err != nil {
+			return false, err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		var replies []rpcResponse
+		status, err := r.post(ctx, body, &replies)
+		if err != nil {
+			return true, errors.Join(errors.New("batch failed"), err)
+		}
+		if status != http.StatusOK {
+			err = errors.New("batch failed HTTP " + strconv.Itoa(status))
+			return retryableReply(status, rpcError{}), err
+		}
+
+		for _, reply := range replies {
+			call, exists := callsByID[reply.ID]
+			if !exists {
+				return false, fmt.Errorf("batch reply for unknown request %v", reply.ID)
+			}
+			delete(callsByID, reply.ID)
+			call.Err = replyError(call.Method, reply, call.Result)
+		}
+
+		retry := false
+		for id, call := range callsByID {
+			call.Err = fmt.Errorf("%v: no reply in batch for request %v", call.Method, id)
+			retry = true
+		}
+		for _, call := range calls {
+			var callError *rpcCallError
+			if errors.As(call.Err, &callError) && callError.Code == rpcInWarmup {
+				retry = true
+			}
+		}
+
+		return retry, nil
+	})
+	for _, call := range calls {
+		call.Err = r.redact(call.Err)
+	}
+
+	return r.redact(err)
+}
+
+type rpcCallError struct {
+	Method string
+	rpcError
+}
+
+func (e *rpcCallError) Error() string {
+	return e.Method + ": " + e.Message + " (" + strconv.Itoa(e.Code) + ")"
+}
+
+func replyError(method string, reply rpcResponse, result any) error {
+	if reply.Error.Code != 0 || reply.Error.Message != "" {
+		return &rpcCallError{Method: method, rpcError: reply.Error}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(reply.Result, result)
+}
+
+// getblock for every hash in one round trip, errors line up with hashes
+func (r *RPCClient) GetBlocksByHash(ctx context.Context, hashes []string) ([]*GetBlockReply, []error, error) {
+	blocks := make([]*GetBlockReply, len(hashes))
+	calls := make([]*BatchCall, len(hashes))
+	for i, hash := range hashes {
+		blocks[i] = &GetBlockReply{}
+		calls[i] = &BatchCall{Method: "getblock", Params: []interface{}{hash}, Result: blocks[i]}
+	}
+
+	err := r.Batch(ctx, calls)
+	return blocks, batchErrors(calls), err
+}
+
+// gettransaction for every ID in one round trip, errors line up with IDs
+func (r *RPCClient) GetTransactions(ctx context.Context, transactionIDs []string) ([]Transaction, []error, error) {
+	transactions := make([]Transaction, len(transactionIDs))
+	calls := make([]*BatchCall, len(transactionIDs))
+	for i, transactionID := range transactionIDs {
+		calls[i] = &BatchCall{Method: "gettransaction", Params: []interface{}{transactionID}, Result: &transactions[i]}
+	}
+
+	err := r.Batch(ctx, calls)
+	return transactions, batchErrors(calls), err
+}
+
+type BlockHeader struct {
+	Hash          string `json:"hash"`
+	Height        uint64 `json:"height"`
+	Confirmations int64  `json:"confirmations"` // -1 when the block is not on the main chain
+	ParentID      string `json:"previousblockhash"`
+}
+
+// getblockheader for every hash in one round trip, errors line up with hashes
+func (r *RPCClient) GetBlockHeaders(ctx context.Context, hashes []string) ([]BlockHeader, []error, error) {
+	headers := make([]BlockHeader, len(hashes))
+	calls := make([]*BatchCall, len(hashes))
+	for i, hash := range hashes {
+		calls[i] = &BatchCall{Method: "getblockheader", Params: []interface{}{hash}, Result: &headers[i]}
+	}
+
+	err := r.Batch(ctx, calls)
+	return headers, batchErrors(calls), err
+}
+
+type RawTransactionOutput struct {
+	Value        float64 `json:"value"`
+	N            uint32  `json:"n"`
+	ScriptPubKey struct {
+		Hex string `json:"hex"`
+	} `json:"scriptPubKey"`
+}
+
+// Decoded getrawtransaction, no wallet needed
+type RawTransaction struct {
+	TransactionID string                 `json:"txid"`
+	BlockHash     string                 `json:"blockhash"`
+	Outputs       []RawTransactionOutput `json:"vout"`
+}
+
+// Decoded getrawtransaction for every ID, looked up in its block so nodes
+// without -txindex can answer.  Nodes too old for the block hash argument
+// are asked again without it.  Errors line up with IDs.
+func (r *RPCClient) GetRawTransactionsInBlocks(ctx context.Context, transactionIDs, blockHashes []string) ([]RawTransaction, []error, error) {
+	transactions := make([]RawTransaction, len(transactionIDs))
+	calls := make([]*BatchCall, len(transactionIDs))
+	for i, transactionID := range transactionIDs {
+		params := []interface{}{transactionID, true, blockHashes[i]}
+		calls[i] = &BatchCall{Method: "getrawtransaction", Params: params, Result: &transactions[i]}
+	}
+
+	err := r.Batch(ctx, calls)
+	if err != nil {
+		return transactions, batchErrors(calls), err
+	}
+
+	var retries []*BatchCall
+	for _, call := range calls {
+		var callError *rpcCallError
+		if errors.As(call.Err, &callError) && callError.Code == rpcMiscError {
+			call.Params = call.Params[:2]
+			retries = append(retries, call)
+		}
+	}
+	err = r.Batch(ctx, retries)
+
+	return transactions, batchErrors(calls), err
+}
+
+func batchErrors(calls []*BatchCall) []error {
+	errs := make([]error, len(calls))
+	for i, call := range calls {
+		errs[i] = call.Err
+	}
+	return errs
+}
@@ -6,4 +6,13 @@ type Config struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Timeout  string `json:"timeout"`
+
+	// Alternatives to Password, at most one may be set
+	CookieFile      string `json:"cookie_file"`      // The node's .cookie, re-read when it restarts
+	CredentialsFile string `json:"credentials_file"` // user:password
+	CredentialsEnv  string `json:"credentials_env"`  // Variable holding user:password
+	// Method name => duration, for calls that need longer than Timeout
+	MethodTimeouts map[string]string `json:"method_timeouts"`
+	// getblocktemplate "rules" the chain expects
+	TemplateRules []string `json:"template_rules"`
 }
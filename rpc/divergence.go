@@ -0,0 +1,147 @@
+package rpc
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DivergenceBehind   = "behind"
+	DivergenceFork     = "fork"
+	DivergenceResolved = "resolved"
+)
+
+// Raised when a node has been off the majority tip for longer than the
+// threshold, and again when it rejoins
+type DivergenceEvent struct {
+	Chain          string
+	Node           string
+	Active         bool // The node was the one the pool mined on when flagged
+	Kind           string
+	NodeHeight     uint64
+	NodeTip        string
+	MajorityHeight uint64
+	MajorityTip    string
+	Since          time.Time // First probe that disagreed with the majority
+	Detected       time.Time
+}
+
+func (e DivergenceEvent) String() string {
+	switch e.Kind {
+	case DivergenceBehind:
+		m := "%v node %v is %v blocks behind its peers at %v (%v) since %v"
+		return fmt.Sprintf(m, e.Chain, e.Node, e.MajorityHeight-e.NodeHeight, e.MajorityHeight, e.MajorityTip, e.Since.Format(time.RFC3339))
+	case DivergenceFork:
+		m := "%v node %v follows %v at %v, its peers follow %v at %v, since %v"
+		return fmt.Sprintf(m, e.Chain, e.Node, e.NodeTip, e.NodeHeight, e.MajorityTip, e.MajorityHeight, e.Since.Format(time.RFC3339))
+	}
+	m := "%v node %v is back on the majority tip %v at %v"
+	return fmt.Sprintf(m, e.Chain, e.Node, e.MajorityTip, e.MajorityHeight)
+}
+
+type divergence struct {
+	diverged      bool
+	divergedSince time.Time
+	reason        string
+}
+
+type chainTip struct {
+	height uint64
+	hash   string
+}
+
+// The tip most reachable nodes agree on, ties going to the taller tip.
+// Two nodes split between equal height tips have no majority.
+func majorityTip(health []*nodeHealth) (chainTip, bool) {
+	votes := make(map[chainTip]int)
+	for _, h := range health {
+		if h.reachable() && h.tip != "" {
+			votes[chainTip{h.height, h.tip}]++
+		}
+	}
+	if len(votes) < 1 {
+		return chainTip{}, false
+	}
+
+	var best chainTip
+	bestVotes, contested := 0, false
+	for tip, count := range votes {
+		switch {
+		case count > bestVotes || (count == bestVotes && tip.height > best.height):
+			best, bestVotes, contested = tip, count, false
+		case count == bestVotes && tip.height == best.height:
+			contested = true
+		}
+	}
+	return best, !contested
+}
+
+// Compares every node to the majority and returns the events to report.
+// Callers hold the manager's lock.
+func (m *Manager) checkDivergence(now time.Time) []DivergenceEvent {
+	majority, agreed := majorityTip(m.health)
+	reachableNodes := 0
+	for _, h := range m.health {
+		if judgeable(h) {
+			reachableNodes++
+		}
+	}
+
+	// One node, or two split evenly, can't be judged.  Flags already raised
+	// stand until a majority says otherwise.
+	comparable := agreed && reachableNodes > 1
+
+	var events []DivergenceEvent
+	for i, h := range m.health {
+		if !judgeable(h) {
+			continue
+		}
+		if !comparable || h.tip == majority.hash {
+			if comparable && h.diverged {
+				events = append(events, m.divergenceEvent(i, DivergenceResolved, majority, now))
+			}
+			if comparable || !h.diverged {
+				h.divergence = divergence{}
+			}
+			continue
+		}
+
+		if h.divergedSince.IsZero() {
+			h.divergedSince = now
+		}
+		kind := DivergenceFork
+		if h.height < majority.height {
+			kind = DivergenceBehind
+		}
+		h.reason = kind
+		if h.diverged || now.Sub(h.divergedSince) < m.divergenceThreshold {
+			continue
+		}
+
+		h.diverged = true
+		events = append(events, m.divergenceEvent(i, kind, majority, now))
+	}
+
+	return events
+}
+
+// A node that hasn't told us its tip has nothing to compare yet
+func judgeable(h *nodeHealth) bool {
+	return h.reachable() && h.tip != ""
+}
+
+func (m *Manager) divergenceEvent(index int, kind string, majority chainTip, now time.Time) DivergenceEvent {
+	h := m.health[index]
+	return DivergenceEvent{
+		Chain:          m.chainName,
+		Node:           m.clients[index].Name,
+		Active:         index == m.activeIndex,
+		Kind:           kind,
+		NodeHeight:     h.height,
+		NodeTip:        h.tip,
+		MajorityHeight: majority.height,
+		MajorityTip:    majority.hash,
+		Since:          h.divergedSince,
+		Detected:       now,
+	}
+}
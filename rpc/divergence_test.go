@@ -0,0 +1,119 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+)
+
+type tipSpec struct {
+	height uint64
+	tip    string // Empty for a node that hasn't reported one
+	down   bool
+}
+
+func divergenceManager(nodes ...tipSpec) *Manager {
+	m := &Manager{chainName: "test", divergenceThreshold: time.Minute}
+	for i, node := range nodes {
+		m.clients = append(m.clients, &RPCClient{Name: string(rune('a' + i))})
+		health := goodHealth()
+		health.height, health.tip, health.lastOK = node.height, node.tip, !node.down
+		m.health = append(m.health, health)
+	}
+	return m
+}
+
+func TestMajorityTip(t *testing.T) {
+	tests := []struct {
+		name   string
+		nodes  []tipSpec
+		want   chainTip
+		agreed bool
+	}{
+		{"all agree", []tipSpec{{100, "aa", false}, {100, "aa", false}, {100, "aa", false}}, chainTip{100, "aa"}, true},
+		{"two against one", []tipSpec{{100, "aa", false}, {100, "bb", false}, {100, "aa", false}}, chainTip{100, "aa"}, true},
+		{"lone node", []tipSpec{{100, "aa", false}}, chainTip{100, "aa"}, true},
+		{"even split at one height", []tipSpec{{100, "aa", false}, {100, "bb", false}}, chainTip{100, "aa"}, false},
+		{"tie goes to the taller tip", []tipSpec{{100, "aa", false}, {101, "cc", false}}, chainTip{101, "cc"}, true},
+		{"taller tip outvoted", []tipSpec{{100, "aa", false}, {100, "aa", false}, {101, "cc", false}}, chainTip{100, "aa"}, true},
+		{"node with no tip", []tipSpec{{100, "aa", false}, {0, "", false}, {0, "", false}}, chainTip{100, "aa"}, true},
+		{"down nodes don't vote", []tipSpec{{100, "aa", false}, {100, "bb", true}, {100, "bb", true}}, chainTip{100, "aa"}, true},
+		{"nobody reachable", []tipSpec{{100, "aa", true}, {0, "", false}}, chainTip{}, false},
+	}
+	for _, test := range tests {
+		m := divergenceManager(test.nodes...)
+		tip, agreed := majorityTip(m.health)
+		if agreed != test.agreed || (test.agreed && tip != test.want) {
+			t.Errorf("%v: %v agreed %v, want %v agreed %v", test.name, tip, agreed, test.want, test.agreed)
+		}
+	}
+}
+
+func TestCheckDivergence(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		nodes    []tipSpec
+		after    time.Duration // Since the node first disagreed
+		kind     string        // Of the one event for node c, empty for none
+		diverged bool
+	}{
+		{"under the threshold", []tipSpec{{100, "aa", false}, {100, "aa", false}, {99, "99", false}}, 59 * time.Second, "", false},
+		{"behind at the threshold", []tipSpec{{100, "aa", false}, {100, "aa", false}, {99, "99", false}}, time.Minute, DivergenceBehind, true},
+		{"forked", []tipSpec{{100, "aa", false}, {100, "aa", false}, {100, "bb", false}}, 2 * time.Minute, DivergenceFork, true},
+		{"ahead on its own fork", []tipSpec{{100, "aa", false}, {100, "aa", false}, {101, "cc", false}}, 2 * time.Minute, DivergenceFork, true},
+		{"no majority", []tipSpec{{100, "aa", false}, {100, "bb", true}, {100, "bb", false}}, 2 * time.Minute, "", false},
+		{"no tip", []tipSpec{{100, "aa", false}, {100, "aa", false}, {0, "", false}}, 2 * time.Minute, "", false},
+		{"down", []tipSpec{{100, "aa", false}, {100, "aa", false}, {99, "99", true}}, 2 * time.Minute, "", false},
+	}
+	for _, test := range tests {
+		m := divergenceManager(test.nodes...)
+		if events := m.checkDivergence(start); len(events) != 0 {
+			t.Errorf("%v: flagged on the first probe: %v", test.name, events)
+		}
+		events := m.checkDivergence(start.Add(test.after))
+
+		if test.kind == "" {
+			if len(events) != 0 {
+				t.Errorf("%v: %v", test.name, events)
+			}
+		} else if len(events) != 1 || events[0].Node != "c" || events[0].Kind != test.kind || !events[0].Since.Equal(start) {
+			t.Errorf("%v: %+v, want one %v event for c", test.name, events, test.kind)
+		}
+		if m.health[2].diverged != test.diverged {
+			t.Errorf("%v: diverged %v", test.name, m.health[2].diverged)
+		}
+	}
+}
+
+func TestCheckDivergenceResolves(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	m := divergenceManager(tipSpec{100, "aa", false}, tipSpec{100, "aa", false}, tipSpec{100, "bb", false})
+	m.activeIndex = 2
+	m.checkDivergence(start)
+	events := m.checkDivergence(start.Add(time.Minute))
+	if len(events) != 1 || !events[0].Active || m.health[2].healthy() {
+		t.Fatalf("%+v, want the active node flagged", events)
+	}
+
+	// Flagged once, not on every probe
+	if events := m.checkDivergence(start.Add(2 * time.Minute)); len(events) != 0 {
+		t.Errorf("flagged again: %v", events)
+	}
+
+	// Losing the majority leaves the flag standing
+	m.health[1].lastOK = false
+	if events := m.checkDivergence(start.Add(3 * time.Minute)); len(events) != 0 || !m.health[2].diverged {
+		t.Errorf("%v, diverged %v without a majority", events, m.health[2].diverged)
+	}
+
+	m.health[1].lastOK = true
+	m.health[2].height, m.health[2].tip = 101, "dd"
+	m.health[0].height, m.health[0].tip = 101, "dd"
+	events = m.checkDivergence(start.Add(4 * time.Minute))
+	if len(events) != 1 || events[0].Kind != DivergenceResolved || events[0].MajorityTip != "dd" {
+		t.Errorf("%+v, want c resolved on dd", events)
+	}
+	if m.health[2].diverged || !m.health[2].divergedSince.IsZero() || !m.health[2].healthy() {
+		t.Errorf("still flagged after rejoining: %+v", m.health[2].divergence)
+	}
+}
@@ -0,0 +1,379 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/go-zeromq/zmq4"
+)
+
+// https://github.com/bitcoin/bitcoin/blob/master/src/rpc/protocol.h
+const rpcMiscError = -1
+
+// One scripted or recorded reply
+type Fixture struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"` // Only answers calls with these params when set
+	Result json.RawMessage `json:"result"`
+	Error  *FixtureError   `json:"error,omitempty"`
+}
+
+// An RPC error for a fixture or handler to answer with
+type FixtureError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *FixtureError) Error() string {
+	return e.Message
+}
+
+// Answers a call the fixtures don't cover.  Return a *FixtureError to
+// choose the RPC error code.
+type FakeHandler func(params []json.RawMessage) (any, error)
+
+type FakeCall struct {
+	Method string
+	Params json.RawMessage
+}
+
+// A stand-in for dogecoind or litecoind so pool and payout flows run without
+// a live node.  Calls are answered by fixtures for their method in order,
+// the last one repeating, then by handlers.  Anything else is "Method not
+// found", as a node without that RPC would answer.
+type FakeNode struct {
+	sync.Mutex
+	URL       string // Give it to NewRPCClient
+	NotifyURL string // Set by PublishBlocks
+
+	fixtures map[string][]Fixture
+	handlers map[string]FakeHandler
+	calls    []FakeCall
+
+	// Record mode, every call goes to the real node and its reply is kept
+	upstream *RPCClient
+	recorded []Fixture
+
+	server    *http.Server
+	publisher zmq4.Socket
+	sequences map[string]uint32 // topic => next sequence number
+}
+
+func NewFakeNode() (*FakeNode, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	n := &FakeNode{
+		URL:       "http://" + listener.Addr().String(),
+		fixtures:  make(map[string][]Fixture),
+		handlers:  make(map[string]FakeHandler),
+		sequences: make(map[string]uint32),
+	}
+	n.server = &http.Server{Handler: n}
+	go n.server.Serve(listener)
+
+	return n, nil
+}
+
+// Passes every call through to upstream and keeps the replies for
+// SaveFixtures.  Point the pool at URL while it runs against a real node.
+func NewRecordingNode(upstream *RPCClient) (*FakeNode, error) {
+	n, err := NewFakeNode()
+	if err != nil {
+		return nil, err
+	}
+	n.upstream = upstream
+	return n, nil
+}
+
+// Queues one reply per result for method
+func (n *FakeNode) Script(method string, results ...any) error {
+	for _, result := range results {
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		n.AddFixtures(Fixture{Method: method, Result: encoded})
+	}
+	return nil
+}
+
+func (n *FakeNode) AddFixtures(fixtures ...Fixture) {
+	n.Lock()
+	defer n.Unlock()
+	for _, fixture := range fixtures {
+		n.fixtures[fixture.Method] = append(n.fixtures[fixture.Method], fixture)
+	}
+}
+
+func (n *FakeNode) Handle(method string, handler FakeHandler) {
+	n.Lock()
+	defer n.Unlock()
+	n.handlers[method] = handler
+}
+
+// Every call made so far for method, or every call at all when it's empty
+func (n *FakeNode) Calls(method string) []FakeCall {
+	n.Lock()
+	defer n.Unlock()
+	var calls []FakeCall
+	for _, call := range n.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func LoadFixtures(path string) ([]Fixture, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []Fixture
+	err = json.Unmarshal(contents, &fixtures)
+	if err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	return fixtures, nil
+}
+
+// Writes what a recording node captured, in the order it was called
+func (n *FakeNode) SaveFixtures(path string) error {
+	n.Lock()
+	contents, err := json.MarshalIndent(n.recorded, "", "    ")
+	n.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, contents, 0600)
+}
+
+// Binds a ZMQ publisher the pool can subscribe to as the node's notify URL
+func (n *FakeNode) PublishBlocks() error {
+	publisher := zmq4.NewPub(context.Background())
+	err := publisher.Listen("tcp://127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+
+	n.Lock()
+	defer n.Unlock()
+	n.publisher = publisher
+	n.NotifyURL = "tcp://" + publisher.Addr().String()
+	return nil
+}
+
+// Sends hashblock as the node does, the hash in display order
+func (n *FakeNode) PublishHashBlock(blockHash string) error {
+	hash, err := hex.DecodeString(blockHash)
+	if err != nil {
+		return err
+	}
+	return n.Publish("hashblock", hash)
+}
+
+// Sends any topic followed by its own little endian sequence number, as
+// rawblock, hashtx and rawtx are
+func (n *FakeNode) Publish(topic string, body []byte) error {
+	n.Lock()
+	publisher := n.publisher
+	sequence := make([]byte, 4)
+	binary.LittleEndian.PutUint32(sequence, n.sequences[topic])
+	n.sequences[topic]++
+	n.Unlock()
+
+	if publisher == nil {
+		return errors.New("PublishBlocks hasn't been called")
+	}
+	return publisher.Send(zmq4.NewMsgFrom([]byte(topic), body, sequence))
+}
+
+func (n *FakeNode) Close() error {
+	n.Lock()
+	publisher := n.publisher
+	n.Unlock()
+
+	err := n.server.Close()
+	if publisher != nil {
+		err = errors.Join(err, publisher.Close())
+	}
+	return err
+}
+
+type fakeRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type fakeReply struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *FixtureError   `json:"error"`
+}
+
+func (n *FakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body json.RawMessage
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	// Batches are always answered 200, errors travel per call
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var requests []fakeRequest
+		err = json.Unmarshal(body, &requests)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		replies := make([]fakeReply, len(requests))
+		for i, request := range requests {
+			replies[i], _ = n.answer(r.Context(), request)
+		}
+		json.NewEncoder(w).Encode(replies)
+		return
+	}
+
+	var request fakeRequest
+	err = json.Unmarshal(body, &request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reply, status := n.answer(r.Context(), request)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(reply)
+}
+
+func (n *FakeNode) answer(ctx context.Context, request fakeRequest) (fakeReply, int) {
+	params, _ := json.Marshal(request.Params)
+	if request.Params == nil {
+		params = nil
+	}
+	n.Lock()
+	n.calls = append(n.calls, FakeCall{Method: request.Method, Params: params})
+	n.Unlock()
+
+	if n.upstream != nil {
+		return n.forward(ctx, request, params)
+	}
+
+	reply := fakeReply{ID: request.ID}
+	fixture, found := n.nextFixture(request.Method, params)
+	if found {
+		reply.Result, reply.Error = fixture.Result, fixture.Error
+		return reply, replyStatus(reply.Error)
+	}
+
+	n.Lock()
+	handler, found := n.handlers[request.Method]
+	n.Unlock()
+	if !found {
+		reply.Error = &FixtureError{Code: rpcMethodNotFound, Message: "Method not found"}
+		return reply, http.StatusNotFound
+	}
+
+	result, err := handler(request.Params)
+	var fixtureErr *FixtureError
+	switch {
+	case errors.As(err, &fixtureErr):
+		reply.Error = fixtureErr
+	case err != nil:
+		reply.Error = &FixtureError{Code: rpcMiscError, Message: err.Error()}
+	default:
+		reply.Result, err = json.Marshal(result)
+		if err != nil {
+			reply.Error = &FixtureError{Code: rpcMiscError, Message: err.Error()}
+		}
+	}
+	return reply, replyStatus(reply.Error)
+}
+
+// Fixtures are used up in order, the last one for a method keeps answering
+func (n *FakeNode) nextFixture(method string, params json.RawMessage) (Fixture, bool) {
+	n.Lock()
+	defer n.Unlock()
+
+	fixtures := n.fixtures[method]
+	matches := 0
+	first := -1
+	for i, fixture := range fixtures {
+		if fixture.Params != nil && !sameJSON(fixture.Params, params) {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		matches++
+	}
+	if first < 0 {
+		return Fixture{}, false
+	}
+
+	fixture := fixtures[first]
+	if matches > 1 {
+		n.fixtures[method] = append(fixtures[:first:first], fixtures[first+1:]...)
+	}
+	return fixture, true
+}
+
+func (n *FakeNode) forward(ctx context.Context, request fakeRequest, params json.RawMessage) (fakeReply, int) {
+	forwarded := make([]interface{}, len(request.Params))
+	for i, param := range request.Params {
+		forwarded[i] = param
+	}
+
+	reply := fakeReply{ID: request.ID}
+	resp, status, err := n.upstream.doRequest(ctx, request.Method, forwarded)
+	if err != nil {
+		reply.Error = &FixtureError{Code: rpcMiscError, Message: err.Error()}
+		return reply, http.StatusBadGateway
+	}
+	reply.Result = resp.Result
+	if resp.Error.Code != 0 || resp.Error.Message != "" {
+		reply.Error = &FixtureError{Code: resp.Error.Code, Message: resp.Error.Message}
+	}
+
+	// Never write a wallet passphrase to disk
+	if request.Method == "walletpassphrase" {
+		params = nil
+	}
+	n.Lock()
+	n.recorded = append(n.recorded, Fixture{Method: request.Method, Params: params, Result: reply.Result, Error: reply.Error})
+	n.Unlock()
+
+	return reply, status
+}
+
+func replyStatus(err *FixtureError) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case err.Code == rpcMethodNotFound:
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func sameJSON(a, b json.RawMessage) bool {
+	var compactA, compactB bytes.Buffer
+	if json.Compact(&compactA, a) != nil || json.Compact(&compactB, b) != nil {
+		return false
+	}
+	return bytes.Equal(compactA.Bytes(), compactB.Bytes())
+}
@@ -0,0 +1,274 @@
+package rpc
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-zeromq/zmq4"
+)
+
+func fakeNode(t *testing.T) (*FakeNode, *RPCClient) {
+	node, err := NewFakeNode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { node.Close() })
+	return node, NewRPCClient("litecoin", node.URL, "", "", "5s")
+}
+
+func TestFixturesReplayInOrder(t *testing.T) {
+	node, client := fakeNode(t)
+	err := node.Script("getconnectioncount", 3, 5, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []int64
+	for i := 0; i < 5; i++ {
+		peers, err := client.GetPeerCount(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, peers)
+	}
+	want := []int64{3, 5, 8, 8, 8}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v with the last fixture repeating", got, want)
+		}
+	}
+	if calls := node.Calls("getconnectioncount"); len(calls) != 5 {
+		t.Errorf("%v calls recorded, want 5", len(calls))
+	}
+}
+
+func TestFixturesMatchParams(t *testing.T) {
+	node, client := fakeNode(t)
+	node.AddFixtures(
+		Fixture{Method: "getblock", Params: json.RawMessage(`["bb"]`), Result: json.RawMessage(`{"height": 2}`)},
+		Fixture{Method: "getblock", Params: json.RawMessage(`[ "aa" ]`), Result: json.RawMessage(`{"height": 1}`)},
+	)
+
+	for _, test := range []struct {
+		hash   string
+		height uint64
+	}{{"aa", 1}, {"bb", 2}, {"aa", 1}} {
+		block, err := client.GetBlockByHash(context.Background(), test.hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block.Height != test.height {
+			t.Errorf("getblock %v: height %v, want %v", test.hash, block.Height, test.height)
+		}
+	}
+
+	_, err := client.GetBlockByHash(context.Background(), "cc")
+	if err == nil || !strings.Contains(err.Error(), "Method not found") {
+		t.Errorf("params no fixture matches: got %v, want Method not found", err)
+	}
+}
+
+func TestHandlersAndErrors(t *testing.T) {
+	node, client := fakeNode(t)
+	node.Handle("sendmany", func(params []json.RawMessage) (any, error) {
+		var outputs map[string]float64
+		err := json.Unmarshal(params[1], &outputs)
+		if err != nil {
+			return nil, err
+		}
+		if outputs["poor"] > 0 {
+			return nil, &FixtureError{Code: -6, Message: "Insufficient funds"}
+		}
+		return "txid", nil
+	})
+
+	txid, err := client.SendMany(context.Background(), map[string]float64{"rich": 1})
+	if err != nil || txid != "txid" {
+		t.Fatalf("got %q, %v from the handler", txid, err)
+	}
+	_, err = client.SendMany(context.Background(), map[string]float64{"poor": 1})
+	if err == nil || !strings.Contains(err.Error(), "Insufficient funds") {
+		t.Errorf("got %v, want the handler's error", err)
+	}
+
+	_, err = client.EstimateSmartFee(context.Background(), 6)
+	if !errors.Is(err, ErrMethodNotFound) {
+		t.Errorf("unscripted method: got %v, want ErrMethodNotFound", err)
+	}
+}
+
+func TestBatchesAnswerEachCall(t *testing.T) {
+	node, client := fakeNode(t)
+	node.AddFixtures(
+		Fixture{Method: "getblock", Params: json.RawMessage(`["aa"]`), Result: json.RawMessage(`{"height": 1, "confirmations": 3}`)},
+		Fixture{Method: "getblock", Params: json.RawMessage(`["bb"]`), Error: &FixtureError{Code: -5, Message: "Block not found"}},
+	)
+
+	blocks, errs, err := client.GetBlocksByHash(context.Background(), []string{"aa", "bb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs[0] != nil || blocks[0].Confirmations != 3 {
+		t.Errorf("first block: got %+v, %v", blocks[0], errs[0])
+	}
+	if errs[1] == nil || !strings.Contains(errs[1].Error(), "Block not found") {
+		t.Errorf("second block: got %v, want its own error", errs[1])
+	}
+	if calls := node.Calls("getblock"); len(calls) != 2 {
+		t.Errorf("%v getblock calls recorded, want 2", len(calls))
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	upstream, upstreamClient := fakeNode(t)
+	upstream.AddFixtures(
+		Fixture{Method: "getblock", Params: json.RawMessage(`["aa"]`), Result: json.RawMessage(`{"height": 7}`)},
+		Fixture{Method: "walletpassphrase", Result: json.RawMessage(`null`)},
+		Fixture{Method: "sendmany", Error: &FixtureError{Code: -6, Message: "Insufficient funds"}},
+	)
+	err := upstream.Script("getconnectioncount", 4, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder, err := NewRecordingNode(upstreamClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recorder.Close()
+	recording := NewRPCClient("litecoin", recorder.URL, "", "", "5s")
+
+	session := func(client *RPCClient) []string {
+		ctx := context.Background()
+		var seen []string
+		for i := 0; i < 2; i++ {
+			peers, err := client.GetPeerCount(ctx)
+			seen = append(seen, fmtResult(peers, err))
+		}
+		block, err := client.GetBlockByHash(ctx, "aa")
+		seen = append(seen, fmtResult(block.Height, err))
+		seen = append(seen, fmtResult(nil, client.unlockWallet(ctx, "hunter2", time.Minute)))
+		_, err = client.SendMany(ctx, map[string]float64{"address": 1})
+		seen = append(seen, fmtResult(nil, err))
+		return seen
+	}
+	recorded := session(recording)
+
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	err = recorder.SaveFixtures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures, err := LoadFixtures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fixture := range fixtures {
+		if fixture.Method == "walletpassphrase" && fixture.Params != nil {
+			t.Errorf("the wallet passphrase was recorded: %s", fixture.Params)
+		}
+	}
+
+	replay, replayClient := fakeNode(t)
+	replay.AddFixtures(fixtures...)
+	replayed := session(replayClient)
+
+	if strings.Join(replayed, ", ") != strings.Join(recorded, ", ") {
+		t.Errorf("replay answered %v, the node answered %v", replayed, recorded)
+	}
+}
+
+func fmtResult(result any, err error) string {
+	if err != nil {
+		return "error " + err.Error()
+	}
+	encoded, _ := json.Marshal(result)
+	return string(encoded)
+}
+
+func TestPublishNumbersEachTopic(t *testing.T) {
+	node, _ := fakeNode(t)
+	err := node.PublishBlocks()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscriber := zmq4.NewSub(context.Background())
+	defer subscriber.Close()
+	err = subscriber.Dial(node.NotifyURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = subscriber.SetOption(zmq4.OptionSubscribe, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The subscription isn't in place until a message gets through
+	received := make(chan zmq4.Msg, 128)
+	go func() {
+		for {
+			msg, err := subscriber.Recv()
+			if err != nil {
+				close(received)
+				return
+			}
+			received <- msg
+		}
+	}()
+	deadline := time.After(5 * time.Second)
+	var first zmq4.Msg
+	for first.Frames == nil {
+		err = node.Publish("hashtx", []byte{1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case first = <-received:
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("nothing published arrived")
+		}
+	}
+	warmup := binary.LittleEndian.Uint32(first.Frames[2])
+
+	err = node.PublishHashBlock("00ff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = node.Publish("hashtx", []byte{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hashBlock, hashTx zmq4.Msg
+	for hashBlock.Frames == nil || hashTx.Frames == nil {
+		select {
+		case msg := <-received:
+			switch {
+			case string(msg.Frames[0]) == "hashblock":
+				hashBlock = msg
+			case msg.Frames[1][0] == 2:
+				hashTx = msg
+			}
+		case <-deadline:
+			t.Fatal("published messages didn't arrive")
+		}
+	}
+
+	if sequence := binary.LittleEndian.Uint32(hashBlock.Frames[2]); sequence != 0 {
+		t.Errorf("hashblock's first sequence is %v, want 0", sequence)
+	}
+	if hashBlock.Frames[1][0] != 0x00 || hashBlock.Frames[1][1] != 0xff {
+		t.Errorf("hashblock body %x, want the hash as given", hashBlock.Frames[1])
+	}
+	if sequence := binary.LittleEndian.Uint32(hashTx.Frames[2]); sequence <= warmup {
+		t.Errorf("hashtx sequence %v didn't follow %v", sequence, warmup)
+	}
+}
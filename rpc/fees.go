@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// https://github.com/bitcoin/bitcoin/blob/master/src/rpc/protocol.h
+const rpcMethodNotFound = -32601
+
+// The node doesn't know the method, i.e. an older fork without fee estimation
+var ErrMethodNotFound = errors.New("is not supported by this node")
+
+func (r *RPCClient) feeRequest(ctx context.Context, method string, params []interface{}, result any) error {
+	resp, status, err := r.doRequest(ctx, method, params)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code == rpcMethodNotFound || (status == http.StatusNotFound && resp.Error.Code == 0) {
+		return fmt.Errorf("%v: %v %w", r.Name, method, ErrMethodNotFound)
+	}
+	if status != 200 {
+		return handleHttpError(resp, status)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+type smartFeeEstimate struct {
+	FeeRate *float64 `json:"feerate"` // Coins per kB, absent without enough data
+	Errors  []string `json:"errors"`
+	Blocks  int      `json:"blocks"`
+}
+
+// The fee rate in coins per kB for confirmation within confTarget blocks
+func (r *RPCClient) EstimateSmartFee(ctx context.Context, confTarget int) (float64, error) {
+	var estimate smartFeeEstimate
+	err := r.feeRequest(ctx, "estimatesmartfee", []interface{}{confTarget}, &estimate)
+	if err != nil {
+		return 0, err
+	}
+	if estimate.FeeRate == nil || *estimate.FeeRate <= 0 {
+		m := r.Name + ": no fee estimate"
+		if len(estimate.Errors) > 0 {
+			m = m + ", " + estimate.Errors[0]
+		}
+		return 0, errors.New(m)
+	}
+	return *estimate.FeeRate, nil
+}
+
+// Sets the wallet's fee rate in coins per kB for the transactions it sends
+func (r *RPCClient) SetTxFee(ctx context.Context, feePerKB float64) error {
+	return r.feeRequest(ctx, "settxfee", []interface{}{feePerKB}, nil)
+}
+
+type fundedTransaction struct {
+	Hex       string  `json:"hex"`
+	Fee       float64 `json:"fee"`
+	ChangePos int     `json:"changepos"`
+}
+
+// The fee the wallet would pay to send these outputs, without signing or
+// broadcasting anything
+func (r *RPCClient) QuoteFee(ctx context.Context, outputs map[string]float64) (float64, error) {
+	var unfunded string
+	err := r.feeRequest(ctx, "createrawtransaction", []interface{}{[]any{}, outputs}, &unfunded)
+	if err != nil {
+		return 0, err
+	}
+
+	var funded fundedTransaction
+	err = r.feeRequest(ctx, "fundrawtransaction", []interface{}{unfunded}, &funded)
+	if err != nil {
+		return 0, err
+	}
+	return funded.Fee, nil
+}
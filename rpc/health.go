@@ -1,9 +1,159 @@
 package rpc
 
-func (r *RPCClient) Check() bool {
-	_, err := r.GetBlockTemplate()
-	if err != nil {
-		return false
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// Weight of the newest probe in the latency and error rate averages
+const healthSmoothing = 0.3
+
+type NodeStatus struct {
+	Name             string    `json:"name"`
+	Active           bool      `json:"active"`
+	Healthy          bool      `json:"healthy"`
+	Score            float64   `json:"score"`
+	LatencyMs        float64   `json:"latencyMs"`
+	ErrorRate        float64   `json:"errorRate"`
+	Height           uint64    `json:"height"`
+	Tip              string    `json:"tip"`
+	HeightLag        uint64    `json:"heightLag"`
+	Peers            int64     `json:"peers"`
+	InitialDownload  bool      `json:"initialDownload"`
+	LastProbe        time.Time `json:"lastProbe"`
+	LastError        string    `json:"lastError,omitempty"`
+	ConsecutiveFails int       `json:"consecutiveFails"`
+	Diverged         bool      `json:"diverged"`
+	Divergence       string    `json:"divergence,omitempty"`
+}
+
+type probeResult struct {
+	latency         time.Duration
+	err             error
+	height          uint64
+	tip             string
+	peers           int64
+	initialDownload bool
+}
+
+// One round trip, getblockchaininfo for height and sync state plus
+// getconnectioncount, far lighter than building a block template
+func probeNode(ctx context.Context, client *RPCClient) probeResult {
+	var chainInfo blockChainInfoResponse
+	var peers int64
+	calls := []*BatchCall{
+		{Method: "getblockchaininfo", Result: &chainInfo},
+		{Method: "getconnectioncount", Result: &peers},
+	}
+
+	started := time.Now()
+	err := client.Batch(ctx, calls)
+	result := probeResult{latency: time.Since(started)}
+	result.err = errors.Join(err, calls[0].Err, calls[1].Err)
+	result.height = chainInfo.Blocks
+	result.tip = chainInfo.BestBlockHash
+	result.peers = peers
+	result.initialDownload = chainInfo.InitialBlockDownload
+
+	return result
+}
+
+type nodeHealth struct {
+	probes           int
+	latencyMs        float64
+	errorRate        float64
+	height           uint64
+	tip              string
+	peers            int64
+	initialDownload  bool
+	lastOK           bool
+	lastProbe        time.Time
+	lastError        string
+	consecutiveFails int
+	divergence
+}
+
+func (h *nodeHealth) record(result probeResult) {
+	failed := 0.0
+	if result.err != nil {
+		failed = 1
+	}
+	latencyMs := float64(result.latency) / float64(time.Millisecond)
+
+	if h.probes == 0 {
+		h.latencyMs, h.errorRate = latencyMs, failed
+	} else {
+		h.latencyMs += healthSmoothing * (latencyMs - h.latencyMs)
+		h.errorRate += healthSmoothing * (failed - h.errorRate)
 	}
-	return true
+	h.probes++
+	h.lastProbe = time.Now()
+	h.lastOK = result.err == nil
+
+	if result.err != nil {
+		h.lastError = result.err.Error()
+		h.consecutiveFails++
+		return
+	}
+	h.lastError = ""
+	h.consecutiveFails = 0
+	h.height = result.height
+	h.tip = result.tip
+	h.peers = result.peers
+	h.initialDownload = result.initialDownload
+}
+
+// Answering and synced, its tip counts towards the majority
+func (h *nodeHealth) reachable() bool {
+	return h.probes > 0 && h.lastOK && !h.initialDownload
+}
+
+// Reachable and following the majority, safe to mine on
+func (h *nodeHealth) healthy() bool {
+	return h.reachable() && !h.diverged
+}
+
+// 100 for a fast, error free node at the tallest height with peers.
+// Lower config positions lose a fraction of a point so ties keep the
+// configured order.
+func (h *nodeHealth) status(name string, tallest uint64, position int) NodeStatus {
+	status := NodeStatus{
+		Name:             name,
+		Healthy:          h.healthy(),
+		LatencyMs:        math.Round(h.latencyMs*10) / 10,
+		ErrorRate:        math.Round(h.errorRate*1000) / 1000,
+		Height:           h.height,
+		Tip:              h.tip,
+		Peers:            h.peers,
+		InitialDownload:  h.initialDownload,
+		LastProbe:        h.lastProbe,
+		LastError:        h.lastError,
+		ConsecutiveFails: h.consecutiveFails,
+		Diverged:         h.diverged,
+		Divergence:       h.reason,
+	}
+	if tallest > h.height {
+		status.HeightLag = tallest - h.height
+	}
+	if !status.Healthy {
+		return status
+	}
+
+	score := 100.0
+	score -= math.Min(30, h.latencyMs/20)
+	score -= 40 * h.errorRate
+	score -= math.Min(50, 10*float64(status.HeightLag))
+	switch {
+	case h.peers == 0:
+		score -= 30
+	case h.peers < 3:
+		score -= 10
+	}
+	score -= 0.5 * float64(position)
+
+	// Any healthy node beats an unhealthy one
+	status.Score = math.Round(math.Max(score, 1)*10) / 10
+	return status
 }
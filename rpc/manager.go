@@ -1,95 +1,227 @@
 package rpc
 
 import (
+	"context"
 	"errors"
 	"log"
+	"sync"
 	"time"
 )
 
+// A challenger has to beat the active node by this much before we move,
+// so two similar nodes don't trade places every probe
+const switchScoreMargin = 10
+
 type Manager struct {
-	chainName            string
-	activeIndex          int
-	clients              []*RPCClient
-	primaryCheckInterval time.Duration
+	sync.RWMutex
+	chainName           string
+	activeIndex         int
+	clients             []*RPCClient
+	health              []*nodeHealth
+	probeInterval       time.Duration
+	divergenceThreshold time.Duration
+	probing             sync.Once
+	subscribers         []chan int
+
+	// Set before StartProbing, called outside the manager's lock
+	OnDivergence func(DivergenceEvent)
 }
 
-func MakeRPCManager(chainName string, nodes []Config, returnToPrimaryAfter string) Manager {
-	m := Manager{}
+func MakeRPCManager(chainName string, nodes []Config, probeInterval, divergenceThreshold string) *Manager {
+	m := &Manager{}
 	m.chainName = chainName
 	m.clients = make([]*RPCClient, len(nodes))
+	m.health = make([]*nodeHealth, len(nodes))
 	for i, node := range nodes {
-		m.clients[i] = NewRPCClient(node.Name, node.URL, node.Username, node.Password, node.Timeout)
+		credentials, err := node.Credentials()
+		if err != nil {
+			panic(err)
+		}
+		m.clients[i] = NewRPCClientWithCredentials(node.Name, node.URL, credentials, node.Timeout)
+		m.clients[i].TemplateRules = node.TemplateRules
+		m.clients[i].MethodTimeouts = make(map[string]time.Duration)
+		for method, timeout := range node.MethodTimeouts {
+			duration, err := time.ParseDuration(timeout)
+			if err != nil {
+				panic(err)
+			}
+			m.clients[i].MethodTimeouts[method] = duration
+		}
+		m.health[i] = &nodeHealth{}
 	}
 	var err error
-	m.primaryCheckInterval, err = time.ParseDuration(returnToPrimaryAfter)
+	m.probeInterval, err = time.ParseDuration(probeInterval)
+	if err != nil {
+		panic(err)
+	}
+	m.divergenceThreshold, err = time.ParseDuration(divergenceThreshold)
 	if err != nil {
 		panic(err)
 	}
 	return m
 }
 
-func (manager *Manager) GetActiveClient() *RPCClient {
-	return manager.clients[manager.activeIndex]
+// Probes every node once so the first active node is the best one, then
+// keeps one probe loop running per node until ctx is done
+func (m *Manager) StartProbing(ctx context.Context) {
+	m.probing.Do(func() {
+		var wg sync.WaitGroup
+		for i := range m.clients {
+			wg.Add(1)
+			go func(index int) {
+				defer wg.Done()
+				m.probe(ctx, index)
+			}(i)
+		}
+		wg.Wait()
+		m.selectBestNode()
+
+		for i := range m.clients {
+			go m.probeLoop(ctx, i)
+		}
+	})
 }
 
-func (manager *Manager) CheckAndRecoverRPCs() error {
-	if manager.GetActiveClient().Check() {
-		return nil
+func (m *Manager) probeLoop(ctx context.Context, index int) {
+	ticker := time.NewTicker(m.probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.probe(ctx, index)
+			m.selectBestNode()
+		}
 	}
-	err := manager.FindHealthyNode()
-	if err != nil {
-		return err
+}
+
+func (m *Manager) probe(ctx context.Context, index int) {
+	client := m.clients[index]
+	ctx, cancel := context.WithTimeout(WithoutRetries(ctx), client.Timeout)
+	defer cancel()
+
+	result := probeNode(ctx, client)
+
+	m.Lock()
+	m.health[index].record(result)
+	m.Unlock()
+}
+
+// Flags nodes that left the majority, then moves to the highest scoring
+// node if the active one is down, diverged or clearly worse
+func (m *Manager) selectBestNode() {
+	m.Lock()
+	events := m.checkDivergence(time.Now())
+	switched := m.pickNode()
+	subscribers := m.subscribers
+	index := m.activeIndex
+	m.Unlock()
+
+	for _, event := range events {
+		if m.OnDivergence != nil {
+			m.OnDivergence(event)
+		}
 	}
-	// Launch loop to eventually get us back to the primary node
-	go func() {
-		for {
-			time.Sleep(manager.primaryCheckInterval)
-			if manager.CheckPrimary() {
-				manager.RestorePrimary()
-				return
-			}
+	if !switched {
+		return
+	}
+	for _, subscriber := range subscribers {
+		select {
+		case subscriber <- index:
+		default: // Still holding an unread switch, it'll see the latest node anyway
 		}
-	}()
-
-	return nil
+	}
 }
 
-func (m *Manager) RestorePrimary() {
-	m.activeIndex = 0
+// Receives the new node index each time the manager moves
+func (m *Manager) Subscribe() <-chan int {
+	m.Lock()
+	defer m.Unlock()
+	subscriber := make(chan int, 1)
+	m.subscribers = append(m.subscribers, subscriber)
+	return subscriber
 }
 
-func (m *Manager) FindHealthyNode() error {
-	nodesLength := len(m.clients)
-	nodesChecked := 0
-	nowHealthy := false
-	for !nowHealthy {
-		nodesChecked++
-		if nodesChecked > nodesLength {
-			return errors.New("no healthy " + m.chainName + " nodes!")
+func (m *Manager) pickNode() bool {
+	statuses := m.statuses()
+	best := m.activeIndex
+	for i, status := range statuses {
+		if status.Healthy && status.Score > statuses[best].Score {
+			best = i
 		}
-		m.nextNode()
-		nowHealthy = m.checkActiveNodeHealth()
 	}
-	log.Printf("Now on node: %v\n", m.activeIndex)
-	return nil
+
+	active := statuses[m.activeIndex]
+	if best == m.activeIndex {
+		return false
+	}
+	if active.Healthy && statuses[best].Score < active.Score+switchScoreMargin {
+		return false
+	}
+
+	log.Printf("🔀 %v RPC moving from %v (score %.1f) to %v (score %.1f)",
+		m.chainName, active.Name, active.Score, statuses[best].Name, statuses[best].Score)
+	m.activeIndex = best
+	return true
+}
+
+func (m *Manager) GetActiveClient() *RPCClient {
+	m.RLock()
+	defer m.RUnlock()
+	return m.clients[m.activeIndex]
 }
 
-func (m *Manager) CheckPrimary() bool {
-	return m.clients[0].Check()
+// The client and its position in the node config, read together
+func (m *Manager) Active() (*RPCClient, int) {
+	m.RLock()
+	defer m.RUnlock()
+	return m.clients[m.activeIndex], m.activeIndex
 }
 
 func (m *Manager) GetIndex() int {
+	m.RLock()
+	defer m.RUnlock()
 	return m.activeIndex
 }
 
-func (m *Manager) checkActiveNodeHealth() bool {
-	return m.GetActiveClient().Check()
+// Called after a failed call, probes the active node now rather than
+// waiting for its loop and moves off it if it's down
+func (m *Manager) CheckAndRecoverRPCs() error {
+	m.probe(context.Background(), m.GetIndex())
+	m.selectBestNode()
+
+	m.RLock()
+	defer m.RUnlock()
+	if !m.health[m.activeIndex].healthy() {
+		return errors.New("no healthy " + m.chainName + " nodes!")
+	}
+	return nil
 }
 
-func (m *Manager) nextNode() {
-	if m.activeIndex < len(m.clients)-1 {
-		m.activeIndex++
-	} else {
-		m.activeIndex = 0
+// Scores are relative, height lag is measured against the other nodes
+func (m *Manager) statuses() []NodeStatus {
+	var tallest uint64
+	for _, health := range m.health {
+		if health.healthy() && health.height > tallest {
+			tallest = health.height
+		}
+	}
+
+	statuses := make([]NodeStatus, len(m.clients))
+	for i, client := range m.clients {
+		statuses[i] = m.health[i].status(client.Name, tallest, i)
+		statuses[i].Active = i == m.activeIndex
 	}
+	return statuses
+}
+
+func (m *Manager) Statuses() []NodeStatus {
+	m.RLock()
+	defer m.RUnlock()
+	return m.statuses()
+}
+
+func (m *Manager) ChainName() string {
+	return m.chainName
 }
@@ -0,0 +1,161 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A synced node answering in 10ms with 8 peers
+func goodHealth() *nodeHealth {
+	return &nodeHealth{probes: 1, latencyMs: 10, height: 100, peers: 8, lastOK: true}
+}
+
+func TestHealthScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(*nodeHealth)
+		position int
+		healthy  bool
+		score    float64
+	}{
+		{"fast and synced", func(h *nodeHealth) {}, 0, true, 99.5},
+		{"second in the config", func(h *nodeHealth) {}, 1, true, 99},
+		{"slow", func(h *nodeHealth) { h.latencyMs = 200 }, 0, true, 90},
+		{"latency capped", func(h *nodeHealth) { h.latencyMs = 5000 }, 0, true, 70},
+		{"erroring", func(h *nodeHealth) { h.errorRate = 0.5 }, 0, true, 79.5},
+		{"two blocks behind", func(h *nodeHealth) { h.height = 98 }, 0, true, 79.5},
+		{"lag capped", func(h *nodeHealth) { h.height = 10 }, 0, true, 49.5},
+		{"no peers", func(h *nodeHealth) { h.peers = 0 }, 0, true, 69.5},
+		{"few peers", func(h *nodeHealth) { h.peers = 2 }, 0, true, 89.5},
+		{"floored", func(h *nodeHealth) { h.latencyMs, h.errorRate, h.height, h.peers = 5000, 1, 10, 0 }, 0, true, 1},
+		{"last probe failed", func(h *nodeHealth) { h.lastOK = false }, 0, false, 0},
+		{"never probed", func(h *nodeHealth) { h.probes = 0 }, 0, false, 0},
+		{"syncing", func(h *nodeHealth) { h.initialDownload = true }, 0, false, 0},
+	}
+	for _, test := range tests {
+		health := goodHealth()
+		test.change(health)
+		status := health.status(test.name, 100, test.position)
+		if status.Healthy != test.healthy || status.Score != test.score {
+			t.Errorf("%v: healthy %v scoring %v, want %v scoring %v", test.name, status.Healthy, status.Score, test.healthy, test.score)
+		}
+	}
+}
+
+func TestHealthRecordSmoothsProbes(t *testing.T) {
+	health := &nodeHealth{}
+	health.record(probeResult{latency: 100 * time.Millisecond, height: 10})
+	health.record(probeResult{latency: 200 * time.Millisecond, err: errors.New("timeout")})
+	if health.latencyMs != 130 || health.errorRate != 0.3 {
+		t.Errorf("latency %v error rate %v, want 130 and 0.3", health.latencyMs, health.errorRate)
+	}
+	if health.height != 10 || health.consecutiveFails != 1 || health.healthy() {
+		t.Errorf("height %v after %v fails, a failed probe keeps the last height and isn't healthy", health.height, health.consecutiveFails)
+	}
+}
+
+func testManager(latencies ...float64) *Manager {
+	m := &Manager{chainName: "test"}
+	for i, latency := range latencies {
+		m.clients = append(m.clients, &RPCClient{Name: string(rune('a' + i))})
+		health := goodHealth()
+		health.latencyMs = latency
+		m.health = append(m.health, health)
+	}
+	return m
+}
+
+func TestSelectBestNodeHysteresis(t *testing.T) {
+	tests := []struct {
+		name      string
+		latencies []float64 // Of the active first node, then the challenger
+		down      bool      // The active node's last probe failed
+		switched  bool
+	}{
+		{"challenger a little better", []float64{180, 10}, false, false}, // 91 against 99
+		{"challenger just short", []float64{210, 10}, false, false},      // 89.5 against 99
+		{"challenger by the margin", []float64{220, 10}, false, true},    // 89 against 99
+		{"challenger clearly better", []float64{400, 10}, false, true},   // 80 against 99
+		{"active down", []float64{10, 400}, true, true},                  // Anything healthy wins
+		{"active already best", []float64{10, 10}, false, false},         // 99.5 against 99
+	}
+	for _, test := range tests {
+		m := testManager(test.latencies...)
+		if test.down {
+			m.health[0].lastOK = false
+		}
+		switches := m.Subscribe()
+
+		m.selectBestNode()
+		if (m.GetIndex() == 1) != test.switched {
+			t.Errorf("%v: active node %v", test.name, m.GetIndex())
+		}
+		select {
+		case index := <-switches:
+			if !test.switched || index != 1 {
+				t.Errorf("%v: told subscribers about node %v", test.name, index)
+			}
+		default:
+			if test.switched {
+				t.Errorf("%v: subscribers weren't told", test.name)
+			}
+		}
+	}
+
+	// An unhealthy node is never picked however the active node scores
+	m := testManager(400, 10)
+	m.health[1].lastOK = false
+	m.selectBestNode()
+	if m.GetIndex() != 0 {
+		t.Errorf("moved to a node that's down")
+	}
+}
+
+// Answers the probe's batch like a synced node
+func probedNode(probes *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+		var requests []rpcRequest
+		json.NewDecoder(r.Body).Decode(&requests)
+		replies := make([]map[string]any, len(requests))
+		for i, request := range requests {
+			var result any = 8
+			if request.Method == "getblockchaininfo" {
+				result = map[string]any{"blocks": 100, "bestblockhash": "00ff"}
+			}
+			replies[i] = map[string]any{"result": result, "error": nil, "id": request.ID}
+		}
+		json.NewEncoder(w).Encode(replies)
+	}))
+}
+
+func TestProbingStopsWithItsContext(t *testing.T) {
+	var probes atomic.Int32
+	node := probedNode(&probes)
+	defer node.Close()
+
+	m := MakeRPCManager("test", []Config{{Name: "a", URL: node.URL, Timeout: "1s"}}, "5ms", "1m")
+	ctx, cancel := context.WithCancel(context.Background())
+	m.StartProbing(ctx)
+	if !m.Statuses()[0].Healthy {
+		t.Fatalf("first probe left the node %+v", m.Statuses()[0])
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	time.Sleep(20 * time.Millisecond) // A probe already under way finishes
+	stopped := probes.Load()
+	if stopped < 2 {
+		t.Errorf("%v probes in 50ms at a 5ms interval", stopped)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if probes.Load() != stopped {
+		t.Errorf("%v more probes after the context was done", probes.Load()-stopped)
+	}
+}
@@ -0,0 +1,107 @@
+package rpc
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+type RetryPolicy struct {
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:  3,
+	BaseDelay: 250 * time.Millisecond,
+	MaxDelay:  4 * time.Second,
+}
+
+// Reads that are safe to send again when the node didn't answer.  Anything
+// that submits, spends or changes wallet state is sent once.
+var idempotentMethods = map[string]bool{
+	"getbestblockhash":   true,
+	"getblock":           true,
+	"getblockchaininfo":  true,
+	"getblockhash":       true,
+	"getblockheader":     true,
+	"getblocktemplate":   true,
+	"getconnectioncount": true,
+	"getrawtransaction":  true,
+	"gettransaction":     true,
+	"validateaddress":    true,
+}
+
+// https://github.com/bitcoin/bitcoin/blob/master/src/rpc/protocol.h
+const rpcInWarmup = -28
+
+type noRetryKey struct{}
+
+// Health checks want to know about the first failure, not hide it
+func WithoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// Runs attempt until it succeeds, says not to retry, or the policy runs out.
+// Only retried when every method in the call is idempotent.
+func (r *RPCClient) withRetry(ctx context.Context, methods []string, attempt func(context.Context) (bool, error)) error {
+	attempts := r.Retry.Attempts
+	if attempts < 1 || ctx.Value(noRetryKey{}) != nil {
+		attempts = 1
+	}
+	for _, method := range methods {
+		if !idempotentMethods[method] {
+			attempts = 1
+		}
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			timer := time.NewTimer(r.Retry.delay(i))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		var retry bool
+		retry, err = attempt(ctx)
+		if !retry || ctx.Err() != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+// Full jitter, a random wait up to the exponential step
+func (p RetryPolicy) delay(retry int) time.Duration {
+	step := p.BaseDelay << (retry - 1)
+	if step > p.MaxDelay || step <= 0 {
+		step = p.MaxDelay
+	}
+	if step <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(step) + 1))
+}
+
+// Gateways and a node still loading its block index will answer later,
+// a JSON-RPC error from a running node won't change
+func retryableReply(status int, replyError rpcError) bool {
+	if replyError.Code == rpcInWarmup {
+		return true
+	}
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusInternalServerError:
+		return replyError.Message == ""
+	}
+	return false
+}
@@ -2,35 +2,67 @@ package rpc
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
-	"strings"
+	"sync/atomic"
 	"time"
 )
 
 type RPCClient struct {
-	NodeUrl string
-	Name    string
-	client  *http.Client
+	NodeUrl       string
+	Name          string
+	TemplateRules []string
+	// Overrides Timeout for slow calls, i.e. submitblock on a busy node
+	MethodTimeouts map[string]time.Duration
+	Retry          RetryPolicy
+	Timeout        time.Duration
+	client         *http.Client
+	credentials    Credentials
+	nextID         atomic.Uint64
 }
 
 func NewRPCClient(name, rpcURL, rpcUser, rpcPassword, timeout string) *RPCClient {
-	urlParts := strings.Split(rpcURL, "://")
+	var credentials Credentials
+	if rpcPassword != "" {
+		credentials = StaticCredentials(rpcUser, rpcPassword)
+	}
+	return NewRPCClientWithCredentials(name, rpcURL, credentials, timeout)
+}
+
+// Credentials may be nil to use any user:password in the URL
+func NewRPCClientWithCredentials(name, rpcURL string, credentials Credentials, timeout string) *RPCClient {
+	nodeURL, err := url.Parse(rpcURL)
+	if err != nil {
+		panic("rpc: can't parse " + name + " node URL: " + err.Error())
+	}
+	if nodeURL.User != nil {
+		if credentials == nil {
+			password, _ := nodeURL.User.Password()
+			credentials = StaticCredentials(nodeURL.User.Username(), password)
+		}
+		nodeURL.User = nil
+	}
+
 	rpcClient := &RPCClient{
-		Name:    name,
-		NodeUrl: urlParts[0] + "://" + rpcUser + ":" + rpcPassword + "@" + urlParts[1]}
+		Name:        name,
+		NodeUrl:     nodeURL.String(),
+		Retry:       DefaultRetryPolicy,
+		credentials: credentials,
+	}
 
 	timeOutIntv, err := time.ParseDuration(timeout)
 	if err != nil {
-		panic("util: Can'blockTemplate parse duration `" + timeout + "`: " + err.Error())
+		panic("util: Can't parse duration `" + timeout + "`: " + err.Error())
 	}
+	rpcClient.Timeout = timeOutIntv
 
-	rpcClient.client = &http.Client{
-		Timeout: timeOutIntv,
-	}
+	// Deadlines come from each call's context
+	rpcClient.client = &http.Client{}
 
 	return rpcClient
 }
@@ -38,7 +70,7 @@ func NewRPCClient(name, rpcURL, rpcUser, rpcPassword, timeout string) *RPCClient
 type rpcResponse struct {
 	Result json.RawMessage `json:"result"`
 	Error  rpcError        `json:"error"`
-	ID     int             `json:"id"`
+	ID     uint64          `json:"id"`
 }
 
 type rpcError struct {
@@ -46,51 +78,117 @@ type rpcError struct {
 	Message string `json:"message"`
 }
 
-func (r *RPCClient) doRequest(method string, params []interface{}) (rpcResponse, int, error) {
-	type rpcRequest struct {
-		ID             int           `json:"id"`
-		JsonRPCVersion string        `json:"jsonrpc"`
-		Method         string        `json:"method"`
-		Parameters     []interface{} `json:"params"`
+type rpcRequest struct {
+	ID             uint64        `json:"id"`
+	JsonRPCVersion string        `json:"jsonrpc"`
+	Method         string        `json:"method"`
+	Parameters     []interface{} `json:"params"`
+}
+
+func (r *RPCClient) newRequest(method string, params []interface{}) rpcRequest {
+	if params == nil {
+		params = []interface{}{}
 	}
+	return rpcRequest{
+		ID:             r.nextID.Add(1),
+		JsonRPCVersion: "2.0",
+		Method:         method,
+		Parameters:     params,
+	}
+}
 
-	var jsonStr rpcRequest
-	jsonStr.ID = 1219
-	jsonStr.JsonRPCVersion = "2.0"
-	jsonStr.Method = method
-	jsonStr.Parameters = params
+func (r *RPCClient) methodTimeout(method string) time.Duration {
+	timeout, exists := r.MethodTimeouts[method]
+	if exists {
+		return timeout
+	}
+	return r.Timeout
+}
 
+// Sends one call, retrying idempotent methods the node failed to answer
+func (r *RPCClient) doRequest(ctx context.Context, method string, params []interface{}) (rpcResponse, int, error) {
 	var rpcResp rpcResponse
+	var status int
+
+	err := r.withRetry(ctx, []string{method}, func(ctx context.Context) (bool, error) {
+		request := r.newRequest(method, params)
+		body, err := json.Marshal(request)
+		if err != nil {
+			return false, err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, r.methodTimeout(method))
+		defer cancel()
+
+		rpcResp = rpcResponse{}
+		status, err = r.post(ctx, body, &rpcResp)
+		if err != nil {
+			return true, errors.Join(errors.New(method+" failed"), err)
+		}
+		if status == http.StatusOK && rpcResp.ID != request.ID {
+			m := "%v: reply for request %v, expected %v"
+			m = fmt.Sprintf(m, method, rpcResp.ID, request.ID)
+			return false, errors.New(m)
+		}
+
+		return retryableReply(status, rpcResp.Error), nil
+	})
+
+	return rpcResp, status, r.redact(err)
+}
 
-	s, err := json.Marshal(jsonStr)
-	if err != nil {
-		return rpcResp, 0, err
+// A body that doesn't decode is only an error on a 200, nodes answer 401
+// and some 5xx without JSON
+func (r *RPCClient) post(ctx context.Context, body []byte, reply any) (int, error) {
+	status, err := r.send(ctx, body, reply)
+	if status != http.StatusUnauthorized || r.credentials == nil {
+		return status, err
 	}
 
-	req, err := http.NewRequest("POST", r.NodeUrl, bytes.NewBuffer(s))
+	// The node rejected the request before running it, so sending it again
+	// with a re-read cookie is safe for any method
+	changed, refreshErr := r.credentials.Refresh()
+	if refreshErr != nil {
+		return status, refreshErr
+	}
+	if !changed {
+		return status, err
+	}
+	return r.send(ctx, body, reply)
+}
+
+func (r *RPCClient) send(ctx context.Context, body []byte, reply any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", r.NodeUrl, bytes.NewReader(body))
 	if err != nil {
-		return rpcResp, 0, err
+		return 0, err
 	}
 	req.Header.Add("accept", "application/json")
-
-	if params != nil {
-		req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Type", "application/json")
+	if r.credentials != nil {
+		user, password, err := r.credentials.UserPassword()
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Authorization", basicAuthorization(user, password))
 	}
 
 	resp, err := r.client.Do(req)
 	if err != nil {
-		return rpcResp, 0, err
+		return 0, err
 	}
 	defer resp.Body.Close()
 
-	json.NewDecoder(resp.Body).Decode(&rpcResp)
+	err = json.NewDecoder(resp.Body).Decode(reply)
+	if err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, errors.Join(errors.New("undecodable reply"), err)
+	}
 
-	return rpcResp, resp.StatusCode, nil
+	return resp.StatusCode, nil
 }
 
-func (r *RPCClient) GetPeerCount() (int64, error) { // getconnectioncount
+func (r *RPCClient) GetPeerCount(ctx context.Context) (int64, error) { // getconnectioncount
 	var n int64
-	resp, status, err := r.doRequest("getconnectioncount", nil)
+	resp, status, err := r.doRequest(ctx, "getconnectioncount", nil)
 	if err != nil {
 		return 0, err
 	}
@@ -99,19 +197,20 @@ func (r *RPCClient) GetPeerCount() (int64, error) { // getconnectioncount
 		return 0, handleHttpError(resp, status)
 	}
 
-	json.Unmarshal(resp.Result, &n)
+	err = json.Unmarshal(resp.Result, &n)
 
-	return n, nil
+	return n, err
 }
 
-func (r *RPCClient) GetBlockTemplate() (json.RawMessage, error) {
+func (r *RPCClient) GetBlockTemplate(ctx context.Context) (json.RawMessage, error) {
 	params := make([]interface{}, 1)
 	rules := make(map[string][]string)
-	rules["rules"] = make([]string, 2)
-	rules["rules"][0] = "mweb"
-	rules["rules"][1] = "segwit"
+	rules["rules"] = r.TemplateRules
+	if rules["rules"] == nil {
+		rules["rules"] = []string{"mweb", "segwit"}
+	}
 	params[0] = rules
-	resp, status, err := r.doRequest("getblocktemplate", params)
+	resp, status, err := r.doRequest(ctx, "getblocktemplate", params)
 	if err != nil {
 		return json.RawMessage{}, err
 	}
@@ -123,10 +222,23 @@ func (r *RPCClient) GetBlockTemplate() (json.RawMessage, error) {
 	return resp.Result, nil
 }
 
-func (r *RPCClient) CreateAuxBlock(rewardAddress string) (json.RawMessage, error) {
+func (r *RPCClient) CreateAuxBlock(ctx context.Context, rewardAddress string) (json.RawMessage, error) {
 	params := make([]any, 1)
 	params[0] = rewardAddress
-	resp, status, err := r.doRequest("createauxblock", params)
+	resp, status, err := r.doRequest(ctx, "createauxblock", params)
+	if err != nil {
+		return json.RawMessage{}, err
+	}
+	if status != 200 {
+		return json.RawMessage{}, handleHttpError(resp, status)
+	}
+
+	return resp.Result, nil
+}
+
+// Wallet based variant of createauxblock, the node pays its own wallet
+func (r *RPCClient) GetAuxBlock(ctx context.Context) (json.RawMessage, error) {
+	resp, status, err := r.doRequest(ctx, "getauxblock", []any{})
 	if err != nil {
 		return json.RawMessage{}, err
 	}
@@ -143,21 +255,22 @@ type GetBlockReplyPart struct {
 }
 
 type GetBlockReply struct {
-	Hash         string   `json:"id"`
-	Difficulty   float64  `json:"difficulty"`
-	Timestamp    int      `json:"time"`
-	Size         int      `json:"size"`
-	Height       uint64   `json:"height"`
-	ParentID     string   `json:"previousblockhash"`
-	Nonce        string   `json:"nonce64"` // From Block Reply
-	Miner        string   `json:"miner"`   // From Explorer API
-	Transactions []string `json:"tx"`      // From Block Reply
+	Hash          string   `json:"id"`
+	Difficulty    float64  `json:"difficulty"`
+	Timestamp     int      `json:"time"`
+	Size          int      `json:"size"`
+	Height        uint64   `json:"height"`
+	Confirmations int64    `json:"confirmations"` // -1 when the block is not on the main chain
+	ParentID      string   `json:"previousblockhash"`
+	Nonce         string   `json:"nonce64"` // From Block Reply
+	Miner         string   `json:"miner"`   // From Explorer API
+	Transactions  []string `json:"tx"`      // From Block Reply
 }
 
-func (r *RPCClient) GetLatestBlock() (GetBlockReplyPart, error) {
+func (r *RPCClient) GetLatestBlock(ctx context.Context) (GetBlockReplyPart, error) {
 	var reply GetBlockReplyPart
 
-	resp, status, err := r.doRequest("getbestblockhash", nil)
+	resp, status, err := r.doRequest(ctx, "getbestblockhash", nil)
 	if err != nil {
 		return reply, err
 	}
@@ -167,9 +280,12 @@ func (r *RPCClient) GetLatestBlock() (GetBlockReplyPart, error) {
 	}
 
 	var blockHash string
-	json.Unmarshal(resp.Result, &blockHash)
+	err = json.Unmarshal(resp.Result, &blockHash)
+	if err != nil {
+		return reply, err
+	}
 
-	block, err := r.GetBlockByHash(blockHash)
+	block, err := r.GetBlockByHash(ctx, blockHash)
 	if err != nil {
 		return reply, err
 	}
@@ -180,11 +296,11 @@ func (r *RPCClient) GetLatestBlock() (GetBlockReplyPart, error) {
 	return reply, nil
 }
 
-func (r *RPCClient) GetBlockByHash(hash string) (*GetBlockReply, error) {
+func (r *RPCClient) GetBlockByHash(ctx context.Context, hash string) (*GetBlockReply, error) {
 	var reply GetBlockReply
 	params := make([]interface{}, 1)
 	params[0] = hash
-	resp, status, err := r.doRequest("getblock", params)
+	resp, status, err := r.doRequest(ctx, "getblock", params)
 
 	if err != nil {
 		return &reply, err
@@ -194,16 +310,16 @@ func (r *RPCClient) GetBlockByHash(hash string) (*GetBlockReply, error) {
 		return &reply, handleHttpError(resp, status)
 	}
 
-	json.Unmarshal(resp.Result, &reply)
+	err = json.Unmarshal(resp.Result, &reply)
 
-	return &reply, nil
+	return &reply, err
 }
 
-func (r *RPCClient) GetBlockByHeight(height int64) (*GetBlockReply, error) {
+func (r *RPCClient) GetBlockByHeight(ctx context.Context, height int64) (*GetBlockReply, error) {
 	var reply GetBlockReply
 	rpcParams := make([]interface{}, 1)
 	rpcParams[0] = height
-	resp, status, err := r.doRequest("getblockhash", rpcParams)
+	resp, status, err := r.doRequest(ctx, "getblockhash", rpcParams)
 	if err != nil {
 		return &reply, err
 	}
@@ -213,9 +329,12 @@ func (r *RPCClient) GetBlockByHeight(height int64) (*GetBlockReply, error) {
 	}
 
 	var blockHash string
-	json.Unmarshal(resp.Result, &blockHash)
+	err = json.Unmarshal(resp.Result, &blockHash)
+	if err != nil {
+		return &reply, err
+	}
 
-	block, err := r.GetBlockByHash(blockHash)
+	block, err := r.GetBlockByHash(ctx, blockHash)
 	if err != nil {
 		return &reply, err
 	}
@@ -223,59 +342,93 @@ func (r *RPCClient) GetBlockByHeight(height int64) (*GetBlockReply, error) {
 	return block, nil
 }
 
-func (r *RPCClient) SubmitBlock(submission []interface{}) (bool, error) {
-	rpcParams := make([]interface{}, 1)
+// Everything the block's coinbase pays.  Verbosity 2 decodes the
+// transactions so nodes without -txindex can answer.
+func (r *RPCClient) GetCoinbaseValue(ctx context.Context, hash string) (float64, error) {
+	params := []interface{}{hash, 2}
+	resp, status, err := r.doRequest(ctx, "getblock", params)
+	if err != nil {
+		return 0, err
+	}
 
-	// This ultimately will be the point of inversion for each chain block...
-	// Each chain block will have it's own rpc.SubmitBlock.. well, all RPC methods really
-	rpcParams[0] = submission[0].(string)
+	if status != 200 {
+		return 0, handleHttpError(resp, status)
+	}
 
-	resp, status, err := r.doRequest("submitblock", rpcParams)
+	var block struct {
+		Transactions []struct {
+			Outputs []struct {
+				Value float64 `json:"value"`
+			} `json:"vout"`
+		} `json:"tx"`
+	}
+	err = json.Unmarshal(resp.Result, &block)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
+	if len(block.Transactions) < 1 {
+		return 0, errors.New("getblock " + hash + " has no coinbase")
+	}
+
+	value := 0.0
+	for _, output := range block.Transactions[0].Outputs {
+		value += output.Value
+	}
+	return value, nil
+}
 
-	result := string(resp.Result)
-	if status != 200 || result != "null" {
-		m := "HTTP (%v) %v error-msg: %v"
-		m = fmt.Sprintf(m, status, result, resp.Error.Message)
-		return false, errors.New(m)
+func (r *RPCClient) SubmitBlock(ctx context.Context, submission string) error {
+	rpcParams := make([]interface{}, 1)
+	rpcParams[0] = submission
+
+	resp, status, err := r.doRequest(ctx, "submitblock", rpcParams)
+	if err != nil {
+		return err
 	}
 
-	return true, nil
+	return parseSubmitResult("submitblock", resp, status)
 }
 
-func (r *RPCClient) SubmitAuxBlock(auxBlockHash string, primaryAuxPow string) (bool, error) {
+func (r *RPCClient) SubmitAuxBlock(ctx context.Context, auxBlockHash string, primaryAuxPow string) error {
 	rpcParams := make([]any, 2)
 
 	rpcParams[0] = auxBlockHash
 	rpcParams[1] = primaryAuxPow
 
-	resp, status, err := r.doRequest("submitauxblock", rpcParams)
+	resp, status, err := r.doRequest(ctx, "submitauxblock", rpcParams)
 	if err != nil {
-		return false, err
+		return err
 	}
-	result := string(resp.Result)
-	if status != 200 || result != "true" {
-		m := "HTTP (%v) %v error-msg: %v"
-		m = fmt.Sprintf(m, status, result, resp.Error.Message)
-		return false, errors.New(m)
+
+	return parseSubmitResult("submitauxblock", resp, status)
+}
+
+// getauxblock <hash> <auxpow> submits and replies with a bool like submitauxblock
+func (r *RPCClient) SubmitGetAuxBlock(ctx context.Context, auxBlockHash string, primaryAuxPow string) error {
+	rpcParams := make([]any, 2)
+
+	rpcParams[0] = auxBlockHash
+	rpcParams[1] = primaryAuxPow
+
+	resp, status, err := r.doRequest(ctx, "getauxblock", rpcParams)
+	if err != nil {
+		return err
 	}
 
-	return true, nil
+	return parseSubmitResult("getauxblock", resp, status)
 }
 
 type validateAddressResponse struct {
 	ScriptPubKey string `json:"scriptPubKey"`
 }
 
-func (r *RPCClient) ValidateAddress(address string) (validateAddressResponse, error) {
+func (r *RPCClient) ValidateAddress(ctx context.Context, address string) (validateAddressResponse, error) {
 	var response validateAddressResponse
 
 	rpcParams := make([]interface{}, 1)
 	rpcParams[0] = address
 
-	resp, status, err := r.doRequest("validateaddress", rpcParams)
+	resp, status, err := r.doRequest(ctx, "validateaddress", rpcParams)
 	if err != nil {
 		return response, err
 	}
@@ -292,14 +445,17 @@ func (r *RPCClient) ValidateAddress(address string) (validateAddressResponse, er
 }
 
 type blockChainInfoResponse struct {
-	Chain             string  `json:"chain"`
-	NetworkDifficulty float64 `json:"difficulty"`
+	Chain                string  `json:"chain"`
+	NetworkDifficulty    float64 `json:"difficulty"`
+	Blocks               uint64  `json:"blocks"`
+	BestBlockHash        string  `json:"bestblockhash"`
+	InitialBlockDownload bool    `json:"initialblockdownload"`
 }
 
-func (r *RPCClient) GetBlockChainInfo() (blockChainInfoResponse, error) {
+func (r *RPCClient) GetBlockChainInfo(ctx context.Context) (blockChainInfoResponse, error) {
 	var response blockChainInfoResponse
 
-	resp, status, err := r.doRequest("getblockchaininfo", nil)
+	resp, status, err := r.doRequest(ctx, "getblockchaininfo", nil)
 	if err != nil {
 		return response, err
 	}
@@ -0,0 +1,122 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// https://en.bitcoin.it/wiki/BIP_0022#Appendix:_Example_Rejection_Reasons
+
+var (
+	ErrBlockDuplicate    = errors.New("block already known to the node")
+	ErrBlockInconclusive = errors.New("block accepted but not yet connected to the best chain")
+	ErrBlockStale        = errors.New("block builds on a stale or unknown tip")
+	ErrBlockHighHash     = errors.New("block hash does not meet the network target")
+	ErrBlockTime         = errors.New("block time rejected by the node")
+	ErrBlockStructural   = errors.New("block construction is invalid")
+	ErrBlockRejected     = errors.New("block rejected")
+)
+
+var submitRejectReasons = map[string]error{
+	"duplicate":              ErrBlockDuplicate,
+	"duplicate-inconclusive": ErrBlockInconclusive,
+	"inconclusive":           ErrBlockInconclusive,
+	"duplicate-invalid":      ErrBlockStructural,
+	"bad-prevblk":            ErrBlockStale,
+	"prev-blk-not-found":     ErrBlockStale,
+	"stale-prevblk":          ErrBlockStale,
+	"stale-work":             ErrBlockStale,
+	"block hash unknown":     ErrBlockStale, // submitauxblock for an expired createauxblock
+	"high-hash":              ErrBlockHighHash,
+	"time-too-old":           ErrBlockTime,
+	"time-too-new":           ErrBlockTime,
+	"time-invalid":           ErrBlockTime,
+	"rejected":               ErrBlockRejected,
+}
+
+// Anything with these prefixes means our header, coinbase or
+// transaction serialization is wrong rather than the miner's luck.
+var structuralRejectPrefixes = []string{
+	"bad-",
+	"unexpected-witness",
+	"invalid-",
+}
+
+type SubmitBlockError struct {
+	Method string
+	Reason string
+	Kind   error
+}
+
+func (e *SubmitBlockError) Error() string {
+	return e.Method + ": " + e.Reason + " (" + e.Kind.Error() + ")"
+}
+
+func (e *SubmitBlockError) Unwrap() error {
+	return e.Kind
+}
+
+// Structural errors mean our block construction is broken and every
+// future candidate will be rejected the same way.
+func (e *SubmitBlockError) Structural() bool {
+	return e.Kind == ErrBlockStructural || e.Kind == ErrBlockHighHash
+}
+
+func classifySubmitReason(method, reason string) *SubmitBlockError {
+	reason = strings.TrimSpace(reason)
+	kind, known := submitRejectReasons[reason]
+	if !known {
+		kind = ErrBlockRejected
+		for _, prefix := range structuralRejectPrefixes {
+			if strings.HasPrefix(reason, prefix) {
+				kind = ErrBlockStructural
+				break
+			}
+		}
+	}
+
+	return &SubmitBlockError{
+		Method: method,
+		Reason: reason,
+		Kind:   kind,
+	}
+}
+
+// For submissions that didn't go through a node, an empty reason is success
+func SubmitReasonError(method, reason string) error {
+	if reason == "" {
+		return nil
+	}
+	return classifySubmitReason(method, reason)
+}
+
+// BIP22: null is success, a string is the rejection reason.
+// submitauxblock replies with a bool instead.
+func parseSubmitResult(method string, resp rpcResponse, status int) error {
+	if resp.Error.Message != "" {
+		return classifySubmitReason(method, resp.Error.Message)
+	}
+
+	result := strings.TrimSpace(string(resp.Result))
+	switch result {
+	case "null", "true":
+		if status != 200 {
+			return handleHttpError(resp, status)
+		}
+		return nil
+	case "", "false":
+		if status != 200 {
+			return handleHttpError(resp, status)
+		}
+		return classifySubmitReason(method, "rejected")
+	}
+
+	var reason string
+	err := json.Unmarshal(resp.Result, &reason)
+	if err != nil {
+		return errors.Join(errors.New(method+": unexpected result "+result), err)
+	}
+
+	return classifySubmitReason(method, reason)
+}
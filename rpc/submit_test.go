@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseSubmitResult(t *testing.T) {
+	tests := []struct {
+		result     string
+		want       error
+		structural bool
+	}{
+		{`null`, nil, false},
+		{`true`, nil, false},
+		{`false`, ErrBlockRejected, false},
+		{`"duplicate"`, ErrBlockDuplicate, false},
+		{`"inconclusive"`, ErrBlockInconclusive, false},
+		{`"high-hash"`, ErrBlockHighHash, true},
+		{`"bad-txnmrklroot"`, ErrBlockStructural, true},
+		{`"bad-cb-length"`, ErrBlockStructural, true},
+		{`"time-too-new"`, ErrBlockTime, false},
+		{`"something-new"`, ErrBlockRejected, false},
+	}
+	for _, test := range tests {
+		err := parseSubmitResult("submitblock", rpcResponse{Result: json.RawMessage(test.result)}, 200)
+		if test.want == nil {
+			if err != nil {
+				t.Errorf("%v: got %v, want success", test.result, err)
+			}
+			continue
+		}
+
+		var submitErr *SubmitBlockError
+		if !errors.As(err, &submitErr) {
+			t.Errorf("%v: got %v, want a *SubmitBlockError", test.result, err)
+			continue
+		}
+		if !errors.Is(err, test.want) {
+			t.Errorf("%v: got %v, want %v", test.result, submitErr.Kind, test.want)
+		}
+		if submitErr.Structural() != test.structural {
+			t.Errorf("%v: structural is %v, want %v", test.result, submitErr.Structural(), test.structural)
+		}
+	}
+}
+
+func TestParseSubmitResultFromError(t *testing.T) {
+	resp := rpcResponse{Result: json.RawMessage(`null`), Error: rpcError{Code: -1, Message: "bad-cb-length"}}
+	err := parseSubmitResult("submitauxblock", resp, 500)
+	if !errors.Is(err, ErrBlockStructural) {
+		t.Errorf("got %v, want the error's reason classified", err)
+	}
+}
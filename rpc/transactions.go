@@ -1,8 +1,8 @@
 package rpc
 
 import (
+	"context"
 	"encoding/json"
-	"time"
 )
 
 type TransactionDetails struct {
@@ -20,16 +20,17 @@ type Transaction struct {
 	BlockTime       int64                `json:"blocktime"`
 	TransactionTime int64                `json:"time"`
 	RecievedTime    int64                `json:"recievedtime"`
+	Fee             float64              `json:"fee"` // Negative, only for transactions this wallet sent
 	Details         []TransactionDetails `json:"details"`
 }
 
-func (r *RPCClient) GetTransaction(transactionID string) (Transaction, error) {
+func (r *RPCClient) GetTransaction(ctx context.Context, transactionID string) (Transaction, error) {
 	params := make([]any, 1)
 	params[0] = transactionID
 
 	transaction := Transaction{}
 
-	resp, status, err := r.doRequest("gettransaction", params)
+	resp, status, err := r.doRequest(ctx, "gettransaction", params)
 	if err != nil {
 		return transaction, err
 	}
@@ -42,7 +43,7 @@ func (r *RPCClient) GetTransaction(transactionID string) (Transaction, error) {
 	return transaction, err
 }
 
-func (r *RPCClient) SendMany(transactions map[string]float64) (string, error) {
+func (r *RPCClient) SendMany(ctx context.Context, transactions map[string]float64) (string, error) {
 	params := make([]any, 2)
 	from := ""
 	params[0] = from
@@ -50,7 +51,7 @@ func (r *RPCClient) SendMany(transactions map[string]float64) (string, error) {
 
 	transactionID := ""
 
-	response, status, err := r.doRequest("sendmany", params)
+	response, status, err := r.doRequest(ctx, "sendmany", params)
 	if err != nil {
 		return transactionID, err
 	}
@@ -63,8 +64,8 @@ func (r *RPCClient) SendMany(transactions map[string]float64) (string, error) {
 	return transactionID, err
 }
 
-func (r *RPCClient) GetWalletBalance() (float64, error) {
-	resp, status, err := r.doRequest("getbalance", nil)
+func (r *RPCClient) GetWalletBalance(ctx context.Context) (float64, error) {
+	resp, status, err := r.doRequest(ctx, "getbalance", nil)
 	if err != nil {
 		return 0, err
 	}
@@ -74,24 +75,16 @@ func (r *RPCClient) GetWalletBalance() (float64, error) {
 	}
 
 	var balance float64
-	json.Unmarshal(resp.Result, &balance)
+	err = json.Unmarshal(resp.Result, &balance)
 
-	return balance, nil
+	return balance, err
 }
 
-func (r *RPCClient) isWalletUnlocked() (bool, error) {
-	return false, nil
-}
-
-func (r *RPCClient) lockWallet() error {
-	return nil
-}
-
-func (r *RPCClient) SendTransaction(to string, value float64) (string, error) {
+func (r *RPCClient) SendTransaction(ctx context.Context, to string, value float64) (string, error) {
 	rpcParams := make([]interface{}, 2)
 	rpcParams[0] = to
 	rpcParams[1] = value
-	resp, status, err := r.doRequest("sendtoaddress", rpcParams)
+	resp, status, err := r.doRequest(ctx, "sendtoaddress", rpcParams)
 	if err != nil {
 		return "", err
 	}
@@ -101,9 +94,9 @@ func (r *RPCClient) SendTransaction(to string, value float64) (string, error) {
 	}
 
 	var receiptHash string
-	json.Unmarshal(resp.Result, &receiptHash)
+	err = json.Unmarshal(resp.Result, &receiptHash)
 
-	return receiptHash, nil
+	return receiptHash, err
 }
 
 type Tx struct {
@@ -112,12 +105,12 @@ type Tx struct {
 }
 
 type TxReceipt struct {
-	BlockHeight    uint64    `json:""`
-	BlockHash      string    `json:"blockhash"`
-	BlockTime      time.Time `json:"blocktime"`
-	Fee            float32   `json:"fee"`
-	ConfirmedCount int64     `json:"confirmations"`
-	TxId           string    `json:"txid"`
+	BlockHeight    uint64  `json:""`
+	BlockHash      string  `json:"blockhash"`
+	BlockTime      int64   `json:"blocktime"`
+	Fee            float32 `json:"fee"`
+	ConfirmedCount int64   `json:"confirmations"`
+	TxId           string  `json:"txid"`
 }
 
 func (r *TxReceipt) Confirmed() bool {
@@ -128,11 +121,11 @@ func (r *TxReceipt) Successful() bool {
 	return r.Confirmed()
 }
 
-func (r *RPCClient) GetTxReceipt(txId string) (*TxReceipt, error) {
+func (r *RPCClient) GetTxReceipt(ctx context.Context, txId string) (*TxReceipt, error) {
 	var rcpt TxReceipt
 	rpcParams := make([]interface{}, 1)
 	rpcParams[0] = txId
-	resp, status, err := r.doRequest("gettransaction", rpcParams)
+	resp, status, err := r.doRequest(ctx, "gettransaction", rpcParams)
 	if err != nil {
 		return &rcpt, err
 	}
@@ -141,14 +134,12 @@ func (r *RPCClient) GetTxReceipt(txId string) (*TxReceipt, error) {
 		return &rcpt, handleHttpError(resp, status)
 	}
 
-	respStr := ""
-	for _, s := range resp.Result {
-		respStr = respStr + string(s)
+	err = json.Unmarshal(resp.Result, &rcpt)
+	if err != nil {
+		return &rcpt, err
 	}
 
-	json.Unmarshal(resp.Result, &rcpt)
-
-	block, err := r.GetBlockByHash(rcpt.BlockHash)
+	block, err := r.GetBlockByHash(ctx, rcpt.BlockHash)
 	if err != nil {
 		return &rcpt, err
 	}
@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+)
+
+// https://github.com/bitcoin/bitcoin/blob/master/src/rpc/protocol.h
+const rpcWalletPassphraseIncorrect = -14
+
+type walletInfo struct {
+	// Absent for an unencrypted wallet, 0 while locked
+	UnlockedUntil *int64 `json:"unlocked_until"`
+}
+
+func (w walletInfo) encrypted() bool {
+	return w.UnlockedUntil != nil
+}
+
+func (w walletInfo) unlocked() bool {
+	return !w.encrypted() || *w.UnlockedUntil > time.Now().Unix()
+}
+
+func (r *RPCClient) getWalletInfo(ctx context.Context) (walletInfo, error) {
+	var info walletInfo
+	resp, status, err := r.doRequest(ctx, "getwalletinfo", nil)
+	if err != nil {
+		return info, err
+	}
+	if status != 200 {
+		return info, handleHttpError(resp, status)
+	}
+
+	err = json.Unmarshal(resp.Result, &info)
+	return info, err
+}
+
+func (r *RPCClient) unlockWallet(ctx context.Context, passphrase string, timeout time.Duration) error {
+	seconds := int64(timeout.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	params := []interface{}{passphrase, seconds}
+	resp, status, err := r.doRequest(ctx, "walletpassphrase", params)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code == rpcWalletPassphraseIncorrect {
+		return errors.New(r.Name + ": the wallet passphrase is incorrect")
+	}
+	if status != 200 {
+		return handleHttpError(resp, status)
+	}
+	return nil
+}
+
+func (r *RPCClient) lockWallet(ctx context.Context) error {
+	resp, status, err := r.doRequest(ctx, "walletlock", nil)
+	if err != nil {
+		return err
+	}
+	if status != 200 {
+		return handleHttpError(resp, status)
+	}
+	return nil
+}
+
+// Runs send with the wallet unlocked for at most timeout, locking it again
+// afterwards whether or not send succeeded.  passphrase is only called for
+// an encrypted wallet, nil means none is configured.
+func (r *RPCClient) WithUnlockedWallet(ctx context.Context, passphrase func() (string, error), timeout time.Duration, send func(context.Context) error) (err error) {
+	info, err := r.getWalletInfo(ctx)
+	if err != nil {
+		return errors.Join(errors.New(r.Name+": can't read the wallet's lock state"), err)
+	}
+	if !info.encrypted() {
+		return send(ctx)
+	}
+
+	if passphrase == nil {
+		if info.unlocked() {
+			// Somebody unlocked it by hand, leave the lock to them
+			return send(ctx)
+		}
+		return errors.New(r.Name + ": the wallet is encrypted and locked, configure a wallet passphrase file or variable")
+	}
+
+	secret, err := passphrase()
+	if err != nil {
+		return err
+	}
+	err = r.unlockWallet(ctx, secret, timeout)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// Lock even when the payout's context has been cancelled
+		lockCtx, cancel := context.WithTimeout(context.Background(), r.Timeout)
+		defer cancel()
+		lockErr := r.lockWallet(lockCtx)
+		if lockErr != nil {
+			log.Printf("⚠️  %v: failed to lock the wallet, it unlocks itself after %v: %v", r.Name, timeout, lockErr)
+			err = errors.Join(err, lockErr)
+		}
+	}()
+
+	return send(ctx)
+}